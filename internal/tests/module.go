@@ -0,0 +1,30 @@
+// Package tests provides helpers shared by the tests of syntaxgo
+// The helpers write fixture sources to disk, so packages load the same way as real ones
+//
+// tests 包提供 syntaxgo 各测试共用的辅助函数
+// 这些辅助函数将测试用的源代码写入磁盘，使包的加载方式与真实场景一致
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// NewTempModule writes a go.mod with the module path and the files into a temp directory, returns the module root
+// The file names are slash-separated paths relative to the root, directories are created as needed
+//
+// NewTempModule 将带有模块路径的 go.mod 和这些文件写入临时目录，返回模块根目录
+// 文件名是相对于根目录、以斜杠分隔的路径，按需创建目录
+func NewTempModule(t *testing.T, modulePath string, files map[string]string) string {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module "+modulePath+"\n\ngo 1.22\n"), 0644))
+	for name, code := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
+	}
+	return root
+}
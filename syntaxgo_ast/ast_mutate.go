@@ -0,0 +1,545 @@
+package syntaxgo_ast
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_astnode"
+)
+
+// NewCommentGroup creates a comment group of "//" comments, one comment for each line of the text.
+// NewCommentGroup 创建由 "//" 注释组成的注释组，文本的每一行对应一条注释。
+func NewCommentGroup(text string) *ast.CommentGroup {
+	var commentGroup = &ast.CommentGroup{}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line == "" {
+			commentGroup.List = append(commentGroup.List, &ast.Comment{Text: "//"})
+		} else {
+			commentGroup.List = append(commentGroup.List, &ast.Comment{Text: "// " + line})
+		}
+	}
+	return commentGroup
+}
+
+// SetNodeDoc attaches the doc comment to a declaration, spec or field, returns false when the node has no doc.
+// SetNodeDoc 为声明、规格或字段附加文档注释，节点没有文档注释时返回 false。
+func SetNodeDoc(node ast.Node, doc *ast.CommentGroup) bool {
+	switch x := node.(type) {
+	case *ast.FuncDecl:
+		x.Doc = doc
+	case *ast.GenDecl:
+		x.Doc = doc
+	case *ast.TypeSpec:
+		x.Doc = doc
+	case *ast.ValueSpec:
+		x.Doc = doc
+	case *ast.ImportSpec:
+		x.Doc = doc
+	case *ast.Field:
+		x.Doc = doc
+	default:
+		return false
+	}
+	return true
+}
+
+// SetNodeComment attaches the line comment to a spec or field, returns false when the node has no line comment.
+// SetNodeComment 为规格或字段附加行尾注释，节点没有行尾注释时返回 false。
+func SetNodeComment(node ast.Node, comment *ast.CommentGroup) bool {
+	switch x := node.(type) {
+	case *ast.TypeSpec:
+		x.Comment = comment
+	case *ast.ValueSpec:
+		x.Comment = comment
+	case *ast.ImportSpec:
+		x.Comment = comment
+	case *ast.Field:
+		x.Comment = comment
+	default:
+		return false
+	}
+	return true
+}
+
+// RemoveDecl removes the declaration together with its doc and line comments.
+// Like every mutation in this file, the AST is re-parsed afterwards, so nodes got before the call are stale.
+//
+// RemoveDecl 删除声明及其文档注释和行尾注释。
+// 与本文件中的所有修改操作一样，之后会重新解析 AST，因此调用前获取的节点会失效。
+func (ab *AstBundle) RemoveDecl(decl ast.Decl) error {
+	return ab.mutate([]ast.Node{decl}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, edx := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(sdx, edx, nil)
+		return nil
+	})
+}
+
+// InsertDeclBefore inserts the new declaration before the anchor declaration, the doc comment of the new declaration is written too.
+// InsertDeclBefore 在锚点声明之前插入新的声明，同时写入新声明的文档注释。
+func (ab *AstBundle) InsertDeclBefore(anchor ast.Decl, decl ast.Decl) error {
+	code, err := ab.renderDecl(decl)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{anchor}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, _ := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(sdx, sdx, []byte(code+"\n\n"))
+		return nil
+	})
+}
+
+// InsertDeclAfter inserts the new declaration after the anchor declaration, the doc comment of the new declaration is written too.
+// InsertDeclAfter 在锚点声明之后插入新的声明，同时写入新声明的文档注释。
+func (ab *AstBundle) InsertDeclAfter(anchor ast.Decl, decl ast.Decl) error {
+	code, err := ab.renderDecl(decl)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{anchor}, func(ctx *mutateContext, nodes []ast.Node) error {
+		_, edx := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(edx, edx, []byte("\n"+code+"\n"))
+		return nil
+	})
+}
+
+// AppendDecl appends the new declaration to the end of the file.
+// AppendDecl 将新的声明追加到文件末尾。
+func (ab *AstBundle) AppendDecl(decl ast.Decl) error {
+	code, err := ab.renderDecl(decl)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate(nil, func(ctx *mutateContext, nodes []ast.Node) error {
+		ctx.editSet.ReplaceRange(len(ctx.source), len(ctx.source), []byte("\n"+code+"\n"))
+		return nil
+	})
+}
+
+// ReplaceDecl replaces the declaration with the new one.
+// The old doc and line comments are kept when the new declaration has no doc comment.
+//
+// ReplaceDecl 用新的声明替换旧的声明。
+// 新声明没有文档注释时，保留旧声明的文档注释和行尾注释。
+func (ab *AstBundle) ReplaceDecl(oldDecl ast.Decl, newDecl ast.Decl) error {
+	code, err := ab.renderDecl(newDecl)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{oldDecl}, func(ctx *mutateContext, nodes []ast.Node) error {
+		if getDeclDoc(newDecl) != nil {
+			sdx, edx := ctx.getRange(nodes[0])
+			ctx.editSet.ReplaceRange(sdx, edx, []byte(code+"\n"))
+		} else {
+			ctx.editSet.Replace(nodes[0], []byte(code))
+		}
+		return nil
+	})
+}
+
+// MoveDeclBefore moves the declaration before the anchor declaration, comments move along with it.
+// MoveDeclBefore 将声明移动到锚点声明之前，注释随之移动。
+func (ab *AstBundle) MoveDeclBefore(decl ast.Decl, anchor ast.Decl) error {
+	return ab.moveNode(decl, anchor, true, "\n")
+}
+
+// MoveDeclAfter moves the declaration after the anchor declaration, comments move along with it.
+// MoveDeclAfter 将声明移动到锚点声明之后，注释随之移动。
+func (ab *AstBundle) MoveDeclAfter(decl ast.Decl, anchor ast.Decl) error {
+	return ab.moveNode(decl, anchor, false, "\n")
+}
+
+// RemoveField removes the struct field together with its doc and line comments.
+// RemoveField 删除结构体字段及其文档注释和行尾注释。
+func (ab *AstBundle) RemoveField(field *ast.Field) error {
+	return ab.mutate([]ast.Node{field}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, edx := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(sdx, edx, nil)
+		return nil
+	})
+}
+
+// InsertFieldBefore inserts the new field before the anchor field, the doc and line comments of the new field are written too.
+// InsertFieldBefore 在锚点字段之前插入新的字段，同时写入新字段的文档注释和行尾注释。
+func (ab *AstBundle) InsertFieldBefore(anchor *ast.Field, field *ast.Field) error {
+	code, err := ab.renderField(field)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{anchor}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, _ := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(sdx, sdx, []byte(ctx.getLineBreak(sdx)+code+"\n"))
+		return nil
+	})
+}
+
+// InsertFieldAfter inserts the new field after the anchor field, the doc and line comments of the new field are written too.
+// InsertFieldAfter 在锚点字段之后插入新的字段，同时写入新字段的文档注释和行尾注释。
+func (ab *AstBundle) InsertFieldAfter(anchor *ast.Field, field *ast.Field) error {
+	code, err := ab.renderField(field)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{anchor}, func(ctx *mutateContext, nodes []ast.Node) error {
+		_, edx := ctx.getRange(nodes[0])
+		ctx.editSet.ReplaceRange(edx, edx, []byte(ctx.getLineBreak(edx)+code+"\n"))
+		return nil
+	})
+}
+
+// AppendField appends the new field to the end of the struct.
+// AppendField 将新的字段追加到结构体末尾。
+func (ab *AstBundle) AppendField(structType *ast.StructType, field *ast.Field) error {
+	code, err := ab.renderField(field)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{structType}, func(ctx *mutateContext, nodes []ast.Node) error {
+		closing := syntaxgo_astnode.GetOffset(ctx.fset, nodes[0].(*ast.StructType).Fields.Closing)
+		lineSdx := closing
+		for lineSdx > 0 && (ctx.source[lineSdx-1] == ' ' || ctx.source[lineSdx-1] == '\t') {
+			lineSdx--
+		}
+		if lineSdx > 0 && ctx.source[lineSdx-1] == '\n' {
+			ctx.editSet.ReplaceRange(lineSdx, lineSdx, []byte(code+"\n"))
+		} else {
+			ctx.editSet.ReplaceRange(closing, closing, []byte("\n"+code+"\n"))
+		}
+		return nil
+	})
+}
+
+// ReplaceField replaces the struct field with the new one.
+// The old doc and line comments are kept when the new field has no comments of its own.
+//
+// ReplaceField 用新的字段替换旧的结构体字段。
+// 新字段自身没有注释时，保留旧字段的文档注释和行尾注释。
+func (ab *AstBundle) ReplaceField(oldField *ast.Field, newField *ast.Field) error {
+	code, err := ab.renderField(newField)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.mutate([]ast.Node{oldField}, func(ctx *mutateContext, nodes []ast.Node) error {
+		if newField.Doc != nil || newField.Comment != nil {
+			sdx, edx := ctx.getRange(nodes[0])
+			ctx.editSet.ReplaceRange(sdx, edx, []byte(code+"\n"))
+		} else {
+			ctx.editSet.Replace(nodes[0], []byte(code))
+		}
+		return nil
+	})
+}
+
+// MoveFieldBefore moves the struct field before the anchor field, comments move along with it.
+// MoveFieldBefore 将结构体字段移动到锚点字段之前，注释随之移动。
+func (ab *AstBundle) MoveFieldBefore(field *ast.Field, anchor *ast.Field) error {
+	return ab.moveNode(field, anchor, true, "")
+}
+
+// MoveFieldAfter moves the struct field after the anchor field, comments move along with it.
+// MoveFieldAfter 将结构体字段移动到锚点字段之后，注释随之移动。
+func (ab *AstBundle) MoveFieldAfter(field *ast.Field, anchor *ast.Field) error {
+	return ab.moveNode(field, anchor, false, "")
+}
+
+// moveNode cuts the lines of the node with their comments and pastes them before or after the anchor.
+// moveNode 剪切节点及其注释所在的行，并粘贴到锚点之前或之后。
+func (ab *AstBundle) moveNode(node ast.Node, anchor ast.Node, before bool, separator string) error {
+	if node == anchor {
+		return nil
+	}
+	return ab.mutate([]ast.Node{node, anchor}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, edx := ctx.getRange(nodes[0])
+		code := string(ctx.source[sdx:edx])
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+		ctx.editSet.ReplaceRange(sdx, edx, nil)
+		anchorSdx, anchorEdx := ctx.getRange(nodes[1])
+		if before {
+			ctx.editSet.ReplaceRange(anchorSdx, anchorSdx, []byte(ctx.getLineBreak(anchorSdx)+code+separator))
+		} else {
+			ctx.editSet.ReplaceRange(anchorEdx, anchorEdx, []byte(ctx.getLineBreak(anchorEdx)+separator+code))
+		}
+		return nil
+	})
+}
+
+// mutateContext is the freshly printed source with its AST, in which the ranges of the target nodes are resolved.
+// mutateContext 是新打印的源代码及其 AST，目标节点的区间在其中计算。
+type mutateContext struct {
+	fset       *token.FileSet            // File set of the printed file / 打印后文件的文件集
+	file       *ast.File                 // Re-parsed printed file / 重新解析的打印后文件
+	source     []byte                    // Printed source / 打印后的源代码
+	commentMap ast.CommentMap            // Comment groups linked to the nodes / 关联到节点的注释组
+	editSet    *syntaxgo_astnode.EditSet // Edits on the printed source / 对打印后源代码的修改
+}
+
+// mutate prints the AST, finds the target nodes in the re-parsed file, applies the edits and re-parses the result into the bundle.
+// Working on the printed source keeps every comment next to the code it belongs to.
+//
+// mutate 打印 AST，在重新解析的文件中找到目标节点，应用修改并将结果重新解析到 bundle 中。
+// 基于打印后的源代码操作，可以让每条注释都保持在其所属代码的旁边。
+func (ab *AstBundle) mutate(targets []ast.Node, run func(ctx *mutateContext, nodes []ast.Node) error) error {
+	indexes, err := getNodeIndexes(ab.file, targets)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	source, err := ab.FormatSource()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	astFile, err := parser.ParseFile(ab.fset, ab.path, source, parser.ParseComments)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	nodes, err := getNodesByIndexes(astFile, indexes, targets)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	ctx := &mutateContext{
+		fset:       ab.fset,
+		file:       astFile,
+		source:     source,
+		commentMap: ast.NewCommentMap(ab.fset, astFile, astFile.Comments),
+		editSet:    syntaxgo_astnode.NewEditSet(ab.fset),
+	}
+	if err := run(ctx, nodes); err != nil {
+		return erero.Wro(err)
+	}
+	newSource, _, err := ctx.editSet.Apply(source)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	newSource, err = format.Source(newSource)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	newFile, err := parser.ParseFile(ab.fset, ab.path, newSource, parser.ParseComments)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	ab.file = newFile
+	ab.typesBundle = nil // The types info refers to the old nodes. // 类型信息指向旧的节点。
+	return nil
+}
+
+// getRange returns the range of the node together with the comment groups linked to it by ast.CommentMap,
+// that is its doc and line comments, the free comments before it and a comment on the line following it.
+// Comment groups separated from the node by a blank line, like section headers, stay out of the range.
+//
+// getRange 返回节点及 ast.CommentMap 关联到该节点的注释组所覆盖的区间，
+// 即文档注释、行尾注释、节点之前的游离注释以及节点下一行的注释。
+// 与节点之间隔着空行的注释组（例如分节标题）不会被纳入区间。
+func (ctx *mutateContext) getRange(node ast.Node) (int, int) {
+	var before, after []*ast.CommentGroup
+	for _, commentGroup := range ctx.commentMap.Filter(node).Comments() {
+		if commentGroup.End() <= node.Pos() {
+			before = append(before, commentGroup)
+		} else if commentGroup.Pos() >= node.End() {
+			after = append(after, commentGroup)
+		}
+	}
+	pos, end := node.Pos(), node.End()
+	for idx := len(before) - 1; idx >= 0; idx-- {
+		if ctx.fset.Position(pos).Line-ctx.fset.Position(before[idx].End()).Line > 1 {
+			break
+		}
+		pos = before[idx].Pos()
+	}
+	for _, commentGroup := range after {
+		if ctx.fset.Position(commentGroup.Pos()).Line-ctx.fset.Position(end).Line > 1 {
+			break
+		}
+		end = commentGroup.End()
+	}
+	return ctx.widenRange(syntaxgo_astnode.GetOffset(ctx.fset, pos), syntaxgo_astnode.GetOffset(ctx.fset, end))
+}
+
+// widenRange widens the range to whole lines when nothing else shares them, and swallows a trailing ";" otherwise.
+// widenRange 在没有其他代码共享这些行时将区间扩展为整行，否则吞掉其后的 ";"。
+func (ctx *mutateContext) widenRange(sdx, edx int) (int, int) {
+	source := ctx.source
+	lineSdx := sdx
+	for lineSdx > 0 && (source[lineSdx-1] == ' ' || source[lineSdx-1] == '\t') {
+		lineSdx--
+	}
+	lineEdx := edx
+	for lineEdx < len(source) && (source[lineEdx] == ' ' || source[lineEdx] == '\t') {
+		lineEdx++
+	}
+	if (lineSdx == 0 || source[lineSdx-1] == '\n') && (lineEdx == len(source) || source[lineEdx] == '\n') {
+		return lineSdx, min(lineEdx+1, len(source))
+	}
+	if lineEdx < len(source) && source[lineEdx] == ';' {
+		return sdx, lineEdx + 1
+	}
+	return sdx, edx
+}
+
+// getLineBreak returns "\n" when the offset is inside a line, so the code pasted there starts a line of its own.
+// Anchors sharing a line with other code, like the fields of "struct{ A int }", are split onto separate lines this way.
+//
+// getLineBreak 在偏移位于行中间时返回 "\n"，使粘贴在此处的代码另起一行。
+// 与其他代码共享一行的锚点（例如 "struct{ A int }" 中的字段）由此被拆分到不同的行上。
+func (ctx *mutateContext) getLineBreak(idx int) string {
+	if idx > 0 && ctx.source[idx-1] != '\n' {
+		return "\n"
+	}
+	return ""
+}
+
+// renderDecl prints the declaration with its doc comment, the printer misplaces comments of nodes without positions.
+// renderDecl 打印声明及其文档注释，打印器会错放没有位置信息的节点的注释。
+func (ab *AstBundle) renderDecl(decl ast.Decl) (string, error) {
+	var doc *ast.CommentGroup
+	var comment *ast.CommentGroup
+	switch x := decl.(type) {
+	case *ast.FuncDecl:
+		doc = x.Doc
+		clone := *x
+		clone.Doc = nil
+		decl = &clone
+	case *ast.GenDecl:
+		doc = x.Doc
+		clone := *x
+		clone.Doc = nil
+		if !clone.Lparen.IsValid() && len(clone.Specs) == 1 {
+			clone.Specs = []ast.Spec{cloneSpecWithoutComment(clone.Specs[0], &comment)}
+		}
+		decl = &clone
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, ab.fset, decl); err != nil {
+		return "", erero.Wro(err)
+	}
+	return joinComments(doc, buf.String(), comment), nil
+}
+
+// renderField prints the field with its doc and line comments.
+// renderField 打印字段及其文档注释和行尾注释。
+func (ab *AstBundle) renderField(field *ast.Field) (string, error) {
+	var names = make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, ab.fset, field.Type); err != nil {
+		return "", erero.Wro(err)
+	}
+	code := buf.String()
+	if len(names) > 0 {
+		code = strings.Join(names, ", ") + " " + code
+	}
+	if field.Tag != nil {
+		code += " " + field.Tag.Value
+	}
+	return joinComments(field.Doc, code, field.Comment), nil
+}
+
+func cloneSpecWithoutComment(spec ast.Spec, comment **ast.CommentGroup) ast.Spec {
+	switch x := spec.(type) {
+	case *ast.TypeSpec:
+		clone := *x
+		*comment, clone.Comment = x.Comment, nil
+		return &clone
+	case *ast.ValueSpec:
+		clone := *x
+		*comment, clone.Comment = x.Comment, nil
+		return &clone
+	case *ast.ImportSpec:
+		clone := *x
+		*comment, clone.Comment = x.Comment, nil
+		return &clone
+	}
+	return spec
+}
+
+func joinComments(doc *ast.CommentGroup, code string, comment *ast.CommentGroup) string {
+	var lines []string
+	if doc != nil {
+		for _, c := range doc.List {
+			lines = append(lines, c.Text)
+		}
+	}
+	if comment != nil {
+		var texts = make([]string, 0, len(comment.List))
+		for _, c := range comment.List {
+			texts = append(texts, c.Text)
+		}
+		code += " " + strings.Join(texts, " ")
+	}
+	return strings.Join(append(lines, code), "\n")
+}
+
+func getDeclDoc(decl ast.Decl) *ast.CommentGroup {
+	switch x := decl.(type) {
+	case *ast.FuncDecl:
+		return x.Doc
+	case *ast.GenDecl:
+		return x.Doc
+	}
+	return nil
+}
+
+// getNodeIndexes returns the pre-order indexes of the target nodes in the file.
+// getNodeIndexes 返回目标节点在文件中的前序遍历序号。
+func getNodeIndexes(astFile *ast.File, targets []ast.Node) ([]int, error) {
+	var indexes = make([]int, len(targets))
+	for idx := range indexes {
+		indexes[idx] = -1
+	}
+	var count = 0
+	ast.Inspect(astFile, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		for idx, target := range targets {
+			if node == target {
+				indexes[idx] = count
+			}
+		}
+		count++
+		return true
+	})
+	for idx, index := range indexes {
+		if index < 0 {
+			return nil, erero.Errorf("node %T at index %d is not in the file", targets[idx], idx)
+		}
+	}
+	return indexes, nil
+}
+
+// getNodesByIndexes returns the nodes at the pre-order indexes, checking they have the same types as the targets.
+// getNodesByIndexes 返回前序遍历序号对应的节点，并检查其类型与目标节点一致。
+func getNodesByIndexes(astFile *ast.File, indexes []int, targets []ast.Node) ([]ast.Node, error) {
+	var nodes = make([]ast.Node, len(indexes))
+	var count = 0
+	ast.Inspect(astFile, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		for idx, index := range indexes {
+			if index == count {
+				nodes[idx] = node
+			}
+		}
+		count++
+		return true
+	})
+	for idx, node := range nodes {
+		if node == nil || !sameNodeType(node, targets[idx]) {
+			return nil, erero.Errorf("node %T at index %d is not found after printing", targets[idx], idx)
+		}
+	}
+	return nodes, nil
+}
+
+func sameNodeType(a ast.Node, b ast.Node) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b)
+}
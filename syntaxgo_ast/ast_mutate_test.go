@@ -0,0 +1,309 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const mutateExampleCode = `package example
+
+// A is the first constant.
+const A = 1 // one
+
+// Hello says hello.
+func Hello() string {
+	// inner comment stays inside
+	return "hello"
+}
+
+// User is a user.
+type User struct {
+	// ID is the primary key.
+	ID int64 // primary key
+	// Name is the user name.
+	Name string ` + "`json:\"name\"`" + `
+	Age  int // age in years
+}
+`
+
+// getUserFields returns the fields of the User struct in the example code
+// getUserFields 返回示例代码中 User 结构体的字段
+func getUserFields(astBundle *AstBundle) (*ast.StructType, []*ast.Field) {
+	genDecl := astBundle.file.Decls[2].(*ast.GenDecl)
+	structType := genDecl.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+	return structType, structType.Fields.List
+}
+
+// TestAstBundle_MoveDecl tests moving a declaration together with its doc, line and inner comments
+// Verifies every comment stays next to the code it belongs to
+//
+// TestAstBundle_MoveDecl 测试移动声明及其文档注释、行尾注释和内部注释
+// 验证每条注释都保持在其所属代码的旁边
+func TestAstBundle_MoveDecl(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(mutateExampleCode))
+	require.NoError(t, err)
+
+	require.NoError(t, astBundle.MoveDeclAfter(astBundle.file.Decls[0], astBundle.file.Decls[1]))
+	require.NoError(t, astBundle.MoveDeclBefore(astBundle.file.Decls[2], astBundle.file.Decls[0]))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `package example
+
+// User is a user.
+type User struct {
+	// ID is the primary key.
+	ID int64 // primary key
+	// Name is the user name.
+	Name string ` + "`json:\"name\"`" + `
+	Age  int    // age in years
+}
+
+// Hello says hello.
+func Hello() string {
+	// inner comment stays inside
+	return "hello"
+}
+
+// A is the first constant.
+const A = 1 // one
+`
+	require.Equal(t, expected, string(newSrc))
+}
+
+// TestAstBundle_RemoveAndInsertDecl tests removing a declaration and inserting generated declarations with comments
+// Verifies the removed comments are gone and the attached comments are written
+//
+// TestAstBundle_RemoveAndInsertDecl 测试删除声明以及插入带注释的生成声明
+// 验证删除的注释已消失，附加的注释被写入
+func TestAstBundle_RemoveAndInsertDecl(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(mutateExampleCode))
+	require.NoError(t, err)
+
+	require.NoError(t, astBundle.RemoveDecl(astBundle.file.Decls[1]))
+
+	valueSpec := &ast.ValueSpec{
+		Names:  []*ast.Ident{ast.NewIdent("B")},
+		Values: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "2"}},
+	}
+	require.True(t, SetNodeComment(valueSpec, NewCommentGroup("two")))
+	genDecl := &ast.GenDecl{Tok: token.CONST, Specs: []ast.Spec{valueSpec}}
+	require.True(t, SetNodeDoc(genDecl, NewCommentGroup("B is the second constant.")))
+	require.NoError(t, astBundle.InsertDeclAfter(astBundle.file.Decls[0], genDecl))
+
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent("Bye"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{},
+	}
+	require.True(t, SetNodeDoc(funcDecl, NewCommentGroup("Bye says bye.\n\nIt does nothing.")))
+	require.NoError(t, astBundle.AppendDecl(funcDecl))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `package example
+
+// A is the first constant.
+const A = 1 // one
+
+// B is the second constant.
+const B = 2 // two
+
+// User is a user.
+type User struct {
+	// ID is the primary key.
+	ID int64 // primary key
+	// Name is the user name.
+	Name string ` + "`json:\"name\"`" + `
+	Age  int    // age in years
+}
+
+// Bye says bye.
+//
+// It does nothing.
+func Bye() {
+}
+`
+	require.Equal(t, expected, string(newSrc))
+}
+
+// TestAstBundle_RemoveDecl_CommentInEarlierBody tests removing a declaration after a function whose body holds only a comment
+// Verifies the comment inside the earlier body and the free comment after a blank line are kept while the doc and line comments go
+//
+// TestAstBundle_RemoveDecl_CommentInEarlierBody 测试删除位于仅含注释的函数体之后的声明
+// 验证前面函数体内的注释和隔着空行的游离注释被保留，而声明的文档注释和行尾注释被删除
+func TestAstBundle_RemoveDecl_CommentInEarlierBody(t *testing.T) {
+	const code = `package example
+
+func B() {
+	// inner
+} // trailing
+
+// free comment
+
+// A doc
+type A struct {
+	Name string
+} // A line
+
+var C = 1
+`
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+
+	require.NoError(t, astBundle.RemoveDecl(astBundle.file.Decls[1]))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package example\n\nfunc B() {\n\t// inner\n} // trailing\n\n// free comment\n\nvar C = 1\n", string(newSrc))
+}
+
+// TestAstBundle_MoveDecl_SectionHeader tests moving a declaration below a section header comment
+// Verifies the header separated by a blank line stays in place while the doc comment moves
+//
+// TestAstBundle_MoveDecl_SectionHeader 测试移动位于分节标题注释之下的声明
+// 验证隔着空行的标题保持原位，而文档注释随声明移动
+func TestAstBundle_MoveDecl_SectionHeader(t *testing.T) {
+	const code = `package example
+
+// Constants section
+
+// A doc
+const A = 1
+
+func B() {}
+`
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+
+	require.NoError(t, astBundle.MoveDeclAfter(astBundle.file.Decls[0], astBundle.file.Decls[1]))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package example\n\n// Constants section\n\nfunc B() {}\n\n// A doc\nconst A = 1\n", string(newSrc))
+}
+
+// TestAstBundle_ReplaceDecl tests replacing a function without a doc comment
+// Verifies the old doc comment is kept
+//
+// TestAstBundle_ReplaceDecl 测试用没有文档注释的函数替换旧函数
+// 验证保留旧的文档注释
+func TestAstBundle_ReplaceDecl(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(mutateExampleCode))
+	require.NoError(t, err)
+
+	funcDecl := &ast.FuncDecl{
+		Name: ast.NewIdent("Hello"),
+		Type: &ast.FuncType{Params: &ast.FieldList{}},
+		Body: &ast.BlockStmt{},
+	}
+	require.NoError(t, astBundle.ReplaceDecl(astBundle.file.Decls[1], funcDecl))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Contains(t, string(newSrc), "// Hello says hello.\nfunc Hello() {\n}\n")
+	require.NotContains(t, string(newSrc), "inner comment")
+}
+
+// TestAstBundle_MutateFields tests removing, moving, inserting and replacing struct fields
+// Verifies the doc and line comments follow their fields
+//
+// TestAstBundle_MutateFields 测试删除、移动、插入和替换结构体字段
+// 验证文档注释和行尾注释跟随其字段
+func TestAstBundle_MutateFields(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(mutateExampleCode))
+	require.NoError(t, err)
+
+	_, fields := getUserFields(astBundle)
+	require.NoError(t, astBundle.MoveFieldBefore(fields[2], fields[0]))
+
+	_, fields = getUserFields(astBundle)
+	require.NoError(t, astBundle.RemoveField(fields[2]))
+
+	_, fields = getUserFields(astBundle)
+	require.NoError(t, astBundle.ReplaceField(fields[1], &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent("ID")},
+		Type:  ast.NewIdent("string"),
+	}))
+
+	structType, _ := getUserFields(astBundle)
+	emailField := &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent("Email")},
+		Type:  ast.NewIdent("string"),
+		Tag:   &ast.BasicLit{Kind: token.STRING, Value: "`json:\"email\"`"},
+	}
+	require.True(t, SetNodeDoc(emailField, NewCommentGroup("Email is the contact address.")))
+	require.NoError(t, astBundle.AppendField(structType, emailField))
+
+	_, fields = getUserFields(astBundle)
+	require.NoError(t, astBundle.InsertFieldAfter(fields[0], &ast.Field{
+		Names:   []*ast.Ident{ast.NewIdent("Score")},
+		Type:    &ast.StarExpr{X: ast.NewIdent("float64")},
+		Comment: NewCommentGroup("nil when unknown"),
+	}))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `// User is a user.
+type User struct {
+	Age   int      // age in years
+	Score *float64 // nil when unknown
+	// ID is the primary key.
+	ID string // primary key
+	// Email is the contact address.
+	Email string ` + "`json:\"email\"`" + `
+}
+`
+	require.Contains(t, string(newSrc), expected)
+}
+
+// TestAstBundle_InsertField_OneLineStruct tests inserting fields around the only field of a one-line struct
+// Verifies the field list is split onto separate lines so the new fields parse
+//
+// TestAstBundle_InsertField_OneLineStruct 测试在单行结构体唯一字段的前后插入字段
+// 验证字段列表被拆分到不同的行上，使新字段能够被解析
+func TestAstBundle_InsertField_OneLineStruct(t *testing.T) {
+	const code = "package example\n\ntype P struct{ A int }\n"
+	newField := &ast.Field{
+		Names:   []*ast.Ident{ast.NewIdent("B")},
+		Type:    ast.NewIdent("string"),
+		Comment: NewCommentGroup("new"),
+	}
+
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+	_, fields := getOneLineFields(astBundle)
+	require.NoError(t, astBundle.InsertFieldBefore(fields[0], newField))
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package example\n\ntype P struct {\n\tB string // new\n\tA int\n}\n", string(newSrc))
+
+	astBundle, err = NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+	_, fields = getOneLineFields(astBundle)
+	require.NoError(t, astBundle.InsertFieldAfter(fields[0], newField))
+	newSrc, err = astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package example\n\ntype P struct {\n\tA int\n\tB string // new\n}\n", string(newSrc))
+}
+
+// getOneLineFields returns the fields of the struct declared first in the file
+// getOneLineFields 返回文件中第一个声明的结构体的字段
+func getOneLineFields(astBundle *AstBundle) (*ast.StructType, []*ast.Field) {
+	structType := astBundle.file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+	return structType, structType.Fields.List
+}
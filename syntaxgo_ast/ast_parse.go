@@ -7,6 +7,7 @@ import (
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"os"
 
 	"github.com/yyle88/erero"
 	"golang.org/x/tools/go/ast/astutil"
@@ -60,6 +61,18 @@ type AstBundle struct {
 	// file is the parsed AST file representation.
 	// file 是已解析的 AST 文件表示。
 	file *ast.File
+
+	// typesBundle is the go/types result, set when the bundle is type-checked.
+	// typesBundle 是 go/types 的结果，在类型检查后设置。
+	typesBundle *TypesBundle
+
+	// path is the file path the bundle was loaded from, empty when parsed from bytes.
+	// path 是 bundle 加载时的文件路径，从字节解析时为空。
+	path string
+
+	// source is the source code the bundle was parsed from, kept to diff against rewrites.
+	// source 是 bundle 解析时的源代码，保留用于与改写结果做差异比较。
+	source []byte
 }
 
 // NewAstBundle creates a new AstBundle.
@@ -88,7 +101,9 @@ func NewAstBundleV2(fset *token.FileSet, data []byte) (*AstBundle, error) {
 	if err != nil {
 		return nil, erero.Wro(err)
 	}
-	return NewAstBundle(fset, astFile), nil
+	astBundle := NewAstBundle(fset, astFile)
+	astBundle.source = data
+	return astBundle, nil
 }
 
 // NewAstBundleV3 creates an AstBundle by parsing a file from the given path.
@@ -96,11 +111,18 @@ func NewAstBundleV2(fset *token.FileSet, data []byte) (*AstBundle, error) {
 func NewAstBundleV3(fset *token.FileSet, path string) (*AstBundle, error) {
 	// Parse the Go source file at the specified path and attach comments to the AST.
 	// 解析指定路径的 Go 源文件，并将注释附加到 AST。
-	astFile, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	source, err := os.ReadFile(path)
 	if err != nil {
 		return nil, erero.Wro(err)
 	}
-	return NewAstBundle(fset, astFile), nil
+	astFile, err := parser.ParseFile(fset, path, source, parser.ParseComments)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	astBundle := NewAstBundle(fset, astFile)
+	astBundle.path = path
+	astBundle.source = source
+	return astBundle, nil
 }
 
 // NewAstBundleV4 creates an AstBundle from a file path using a new FileSet.
@@ -116,11 +138,18 @@ func NewAstBundleV4(path string) (*AstBundle, error) {
 func NewAstBundleV5(fset *token.FileSet, path string, mode parser.Mode) (*AstBundle, error) {
 	// Parse the file at the given path using the specified parser mode.
 	// 使用指定的解析模式解析给定路径的文件。
-	astFile, err := parser.ParseFile(fset, path, nil, mode)
+	source, err := os.ReadFile(path)
 	if err != nil {
 		return nil, erero.Wro(err)
 	}
-	return NewAstBundle(fset, astFile), nil
+	astFile, err := parser.ParseFile(fset, path, source, mode)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	astBundle := NewAstBundle(fset, astFile)
+	astBundle.path = path
+	astBundle.source = source
+	return astBundle, nil
 }
 
 // NewAstBundleV6 creates an AstBundle from a file path using a new FileSet and specific parser mode.
@@ -141,6 +170,18 @@ func (ab *AstBundle) GetBundle() (*ast.File, *token.FileSet) {
 	return astFile, fileSet
 }
 
+// GetPath returns the file path the bundle was loaded from, empty when parsed from bytes.
+// GetPath 返回 bundle 加载时的文件路径，从字节解析时为空。
+func (ab *AstBundle) GetPath() string {
+	return ab.path
+}
+
+// GetSource returns the source code the bundle was parsed from, nil when created from an AST file.
+// GetSource 返回 bundle 解析时的源代码，从 AST 文件创建时为 nil。
+func (ab *AstBundle) GetSource() []byte {
+	return ab.source
+}
+
 // FormatSource formats the AST back into Go source code.
 // FormatSource 将 AST 格式化为 Go 源代码。
 func (ab *AstBundle) FormatSource() ([]byte, error) {
@@ -0,0 +1,149 @@
+package syntaxgo_ast
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/yyle88/erero"
+)
+
+// SaveOptions controls how an AstBundle is written back to disk.
+// SaveOptions 控制 AstBundle 如何写回磁盘。
+type SaveOptions struct {
+	dryRun   bool        // Only report whether the content would change. // 只报告内容是否会变化
+	fileMode fs.FileMode // Mode of a new file, an existing file keeps its mode. // 新文件的权限，已有文件保留其权限
+}
+
+// NewSaveOptions creates options that write the file, new files get mode 0644.
+// NewSaveOptions 创建默认选项：写入文件，新文件的权限为 0644。
+func NewSaveOptions() *SaveOptions {
+	return &SaveOptions{
+		dryRun:   false,
+		fileMode: 0644,
+	}
+}
+
+// SetDryRun sets whether to only report the change without writing the file.
+// SetDryRun 设置是否只报告变化而不写入文件。
+func (opts *SaveOptions) SetDryRun(dryRun bool) *SaveOptions {
+	opts.dryRun = dryRun
+	return opts
+}
+
+// SetFileMode sets the mode of a new file, an existing file keeps its own mode.
+// SetFileMode 设置新文件的权限，已有文件保留其自身的权限。
+func (opts *SaveOptions) SetFileMode(fileMode fs.FileMode) *SaveOptions {
+	opts.fileMode = fileMode
+	return opts
+}
+
+// Save formats the AST and writes it back to the path the bundle was loaded from.
+// Returns whether the content changed, an unchanged file is not rewritten.
+//
+// Save 格式化 AST 并写回 bundle 加载时的路径。
+// 返回内容是否发生变化，内容未变化时不会重写文件。
+func (ab *AstBundle) Save() (bool, error) {
+	return ab.SaveV2(NewSaveOptions())
+}
+
+// SaveV2 formats the AST and writes it back to the path the bundle was loaded from, with options.
+// SaveV2 使用选项格式化 AST 并写回 bundle 加载时的路径。
+func (ab *AstBundle) SaveV2(options *SaveOptions) (bool, error) {
+	if ab.path == "" {
+		return false, erero.New("bundle is parsed from bytes, use SaveAs with a path")
+	}
+	return ab.SaveAsV2(ab.path, options)
+}
+
+// SaveAs formats the AST and writes it to the path.
+// Returns whether the content changed, an unchanged file is not rewritten.
+//
+// SaveAs 格式化 AST 并写入指定路径。
+// 返回内容是否发生变化，内容未变化时不会重写文件。
+func (ab *AstBundle) SaveAs(path string) (bool, error) {
+	return ab.SaveAsV2(path, NewSaveOptions())
+}
+
+// SaveAsV2 formats the AST, checks the output re-parses, and writes it to the path with options.
+// The file is written to a temp file in the same directory and renamed, so readers never see a partial file.
+// In dry-run mode nothing is written and the result only tells whether the content would change.
+//
+// SaveAsV2 使用选项格式化 AST，检查输出能重新解析，并写入指定路径。
+// 文件先写入同目录下的临时文件再重命名，读取方不会看到写了一半的文件。
+// 在 dry-run 模式下不写入任何内容，结果只表示内容是否会变化。
+func (ab *AstBundle) SaveAsV2(path string, options *SaveOptions) (bool, error) {
+	newSource, err := ab.FormatSource()
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), path, newSource, parser.ParseComments); err != nil {
+		return false, erero.Wro(err)
+	}
+
+	var fileMode = options.fileMode
+	oldSource, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, erero.Wro(err)
+		}
+	} else {
+		if bytes.Equal(oldSource, newSource) {
+			return false, nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		fileMode = info.Mode().Perm()
+	}
+	if options.dryRun {
+		return true, nil
+	}
+	if err := writeFileAtomic(path, newSource, fileMode); err != nil {
+		return false, erero.Wro(err)
+	}
+	return true, nil
+}
+
+// writeFileAtomic writes the data to a temp file in the same directory, then renames it to the path.
+// writeFileAtomic 将数据写入同目录下的临时文件，然后重命名为目标路径。
+func writeFileAtomic(path string, data []byte, fileMode fs.FileMode) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return erero.Wro(err)
+	}
+	tempPath := tempFile.Name()
+	if err := writeAndClose(tempFile, data, fileMode); err != nil {
+		_ = os.Remove(tempPath)
+		return erero.Wro(err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+func writeAndClose(file *os.File, data []byte, fileMode fs.FileMode) error {
+	if _, err := file.Write(data); err != nil {
+		_ = file.Close()
+		return erero.Wro(err)
+	}
+	if err := file.Chmod(fileMode); err != nil {
+		_ = file.Close()
+		return erero.Wro(err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return erero.Wro(err)
+	}
+	if err := file.Close(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package syntaxgo_ast
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAstBundle_Save tests writing the changed AST back to the file it was loaded from
+// Verifies dry-run leaves the file untouched, saving keeps the file mode, and an unchanged save reports false
+//
+// TestAstBundle_Save 测试将修改后的 AST 写回加载时的文件
+// 验证 dry-run 不修改文件，保存时保留文件权限，内容未变化时返回 false
+func TestAstBundle_Save(t *testing.T) {
+	const code = `package example
+
+func Hello() string { return "hello" }
+`
+	path := filepath.Join(t.TempDir(), "example.go")
+	require.NoError(t, os.WriteFile(path, []byte(code), 0600))
+
+	astBundle, err := NewAstBundleV4(path)
+	require.NoError(t, err)
+	require.Equal(t, path, astBundle.GetPath())
+	require.True(t, astBundle.AddImport("fmt"))
+
+	changed, err := astBundle.SaveV2(NewSaveOptions().SetDryRun(true))
+	require.NoError(t, err)
+	require.True(t, changed)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, code, string(data))
+
+	changed, err = astBundle.Save()
+	require.NoError(t, err)
+	require.True(t, changed)
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	t.Log(string(data))
+	require.Contains(t, string(data), `import "fmt"`)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	changed, err = astBundle.Save()
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1) // No temp file is left behind. // 没有遗留临时文件。
+}
+
+// TestAstBundle_SaveAs tests writing an AST parsed from bytes to a new file
+// Verifies Save without a path fails and SaveAs creates the file with the configured mode
+//
+// TestAstBundle_SaveAs 测试将从字节解析的 AST 写入新文件
+// 验证没有路径时 Save 失败，SaveAs 以配置的权限创建文件
+func TestAstBundle_SaveAs(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte("package example\nvar A  =  1\n"))
+	require.NoError(t, err)
+	require.Empty(t, astBundle.GetPath())
+
+	_, err = astBundle.Save()
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "example.go")
+	changed, err := astBundle.SaveAsV2(path, NewSaveOptions().SetFileMode(0640))
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "package example\n\nvar A = 1\n", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0640), info.Mode().Perm())
+}
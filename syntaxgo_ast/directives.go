@@ -0,0 +1,318 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/build"
+	"go/build/constraint"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_astnode"
+)
+
+// Directive is a "//name:..." comment such as //go:generate, //go:embed, //go:linkname or //nolint.
+// Directive 是 "//name:..." 形式的注释，比如 //go:generate、//go:embed、//go:linkname 或 //nolint。
+type Directive struct {
+	Name    string       // Directive name, such as "go:generate" or "nolint" / 指令名称，比如 "go:generate" 或 "nolint"
+	Args    string       // Text after the name, such as the command or the linters / 名称之后的文本，比如命令或 linter 列表
+	Comment *ast.Comment // Comment holding the directive / 包含该指令的注释
+	Node    ast.Node     // Declaration, spec or field the directive is attached to, nil when free-floating / 指令附加的声明、规格或字段，游离时为 nil
+}
+
+// ParseDirective parses a comment text as a directive, returns false when the comment is not a directive.
+// A directive has no space after "//" and starts with "prefix:name" or "nolint".
+//
+// ParseDirective 将注释文本解析为指令，注释不是指令时返回 false。
+// 指令的 "//" 之后没有空格，并且以 "prefix:name" 或 "nolint" 开头。
+func ParseDirective(text string) (name string, args string, ok bool) {
+	text, ok = strings.CutPrefix(text, "//")
+	if !ok || text == "" || text[0] == ' ' || text[0] == '\t' {
+		return "", "", false
+	}
+	if rest, ok := strings.CutPrefix(text, "nolint"); ok && (rest == "" || rest[0] == ':' || rest[0] == ' ') {
+		return "nolint", strings.TrimSpace(strings.TrimPrefix(rest, ":")), true
+	}
+	name, args, _ = strings.Cut(text, " ")
+	prefix, suffix, found := strings.Cut(name, ":")
+	if !found || !isDirectiveWord(prefix) || !isDirectiveWord(suffix) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(args), true
+}
+
+func isDirectiveWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !('a' <= c && c <= 'z') && !('0' <= c && c <= '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ListDirectives lists every directive in the file, except the //go:build constraint which has its own API.
+// ListDirectives 列出文件中的所有指令，但不包括 //go:build 约束，它有单独的 API。
+func (ab *AstBundle) ListDirectives() []*Directive {
+	owners := getCommentOwners(ab.file)
+	var directives []*Directive
+	for _, commentGroup := range ab.file.Comments {
+		for _, comment := range commentGroup.List {
+			name, args, ok := ParseDirective(comment.Text)
+			if !ok || name == "go:build" {
+				continue
+			}
+			directives = append(directives, &Directive{
+				Name:    name,
+				Args:    args,
+				Comment: comment,
+				Node:    owners[commentGroup],
+			})
+		}
+	}
+	return directives
+}
+
+// FindDirectives lists the directives with the name, such as "go:generate".
+// FindDirectives 列出具有该名称的指令，比如 "go:generate"。
+func (ab *AstBundle) FindDirectives(name string) []*Directive {
+	var results []*Directive
+	for _, directive := range ab.ListDirectives() {
+		if directive.Name == name {
+			results = append(results, directive)
+		}
+	}
+	return results
+}
+
+// AddDirective adds the directive as the last doc line of the declaration, spec or field, such as "go:generate stringer -type=Kind".
+// Pass a nil node to add a free-floating directive after the package clause.
+//
+// AddDirective 将指令添加为声明、规格或字段的最后一行文档注释，比如 "go:generate stringer -type=Kind"。
+// 传入 nil 节点时，在 package 子句之后添加游离的指令。
+func (ab *AstBundle) AddDirective(node ast.Node, text string) error {
+	if _, _, ok := ParseDirective("//" + text); !ok {
+		return erero.Errorf("%q is not a directive", text)
+	}
+	if node == nil {
+		return ab.mutate([]ast.Node{ab.file.Name}, func(ctx *mutateContext, nodes []ast.Node) error {
+			_, edx := ctx.widenRange(syntaxgo_astnode.SdxEdxV2(ctx.fset, nodes[0]))
+			ctx.editSet.ReplaceRange(edx, edx, []byte("\n//"+text+"\n"))
+			return nil
+		})
+	}
+	return ab.mutate([]ast.Node{node}, func(ctx *mutateContext, nodes []ast.Node) error {
+		sdx, _ := ctx.widenRange(syntaxgo_astnode.SdxEdxV2(ctx.fset, nodes[0]))
+		ctx.editSet.ReplaceRange(sdx, sdx, []byte("//"+text+"\n"))
+		return nil
+	})
+}
+
+// RemoveDirective removes the line of the directive.
+// RemoveDirective 删除指令所在的行。
+func (ab *AstBundle) RemoveDirective(directive *Directive) error {
+	return ab.editComment(directive.Comment, func(ctx *mutateContext, comment *ast.Comment) {
+		sdx, edx := ctx.widenRange(syntaxgo_astnode.SdxEdxV2(ctx.fset, comment))
+		ctx.editSet.ReplaceRange(sdx, edx, nil)
+	})
+}
+
+// SetDirectiveArgs replaces the text after the name of the directive.
+// SetDirectiveArgs 替换指令名称之后的文本。
+func (ab *AstBundle) SetDirectiveArgs(directive *Directive, args string) error {
+	text := "//" + directive.Name
+	if args != "" {
+		if directive.Name == "nolint" {
+			text += ":" + args
+		} else {
+			text += " " + args
+		}
+	}
+	return ab.editComment(directive.Comment, func(ctx *mutateContext, comment *ast.Comment) {
+		ctx.editSet.Replace(comment, []byte(text))
+	})
+}
+
+// editComment finds the comment in the printed file by its index among all comments, then edits it.
+// editComment 根据注释在所有注释中的序号在打印后的文件中找到它，然后对其进行修改。
+func (ab *AstBundle) editComment(target *ast.Comment, run func(ctx *mutateContext, comment *ast.Comment)) error {
+	index := slices.Index(getAllComments(ab.file), target)
+	if index < 0 {
+		return erero.New("comment is not in the file")
+	}
+	return ab.mutate(nil, func(ctx *mutateContext, nodes []ast.Node) error {
+		comments := getAllComments(ctx.file)
+		if index >= len(comments) {
+			return erero.New("comment is not found after printing")
+		}
+		run(ctx, comments[index])
+		return nil
+	})
+}
+
+func getAllComments(astFile *ast.File) []*ast.Comment {
+	var comments []*ast.Comment
+	for _, commentGroup := range astFile.Comments {
+		comments = append(comments, commentGroup.List...)
+	}
+	return comments
+}
+
+// getCommentOwners maps doc and line comment groups to the declarations, specs and fields they belong to.
+// getCommentOwners 将文档注释组和行尾注释组映射到其所属的声明、规格和字段。
+func getCommentOwners(astFile *ast.File) map[*ast.CommentGroup]ast.Node {
+	owners := map[*ast.CommentGroup]ast.Node{}
+	ast.Inspect(astFile, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.FuncDecl:
+			setCommentOwner(owners, x, x.Doc)
+		case *ast.GenDecl:
+			setCommentOwner(owners, x, x.Doc)
+		case *ast.TypeSpec:
+			setCommentOwner(owners, x, x.Doc, x.Comment)
+		case *ast.ValueSpec:
+			setCommentOwner(owners, x, x.Doc, x.Comment)
+		case *ast.ImportSpec:
+			setCommentOwner(owners, x, x.Doc, x.Comment)
+		case *ast.Field:
+			setCommentOwner(owners, x, x.Doc, x.Comment)
+		}
+		return true
+	})
+	return owners
+}
+
+func setCommentOwner(owners map[*ast.CommentGroup]ast.Node, node ast.Node, commentGroups ...*ast.CommentGroup) {
+	for _, commentGroup := range commentGroups {
+		if commentGroup != nil {
+			owners[commentGroup] = node
+		}
+	}
+}
+
+// GetBuildConstraint returns the //go:build expression of the file, falling back to the old "// +build" lines.
+// Returns false when the file has no build constraint.
+//
+// GetBuildConstraint 返回文件的 //go:build 表达式，没有时回退到旧的 "// +build" 行。
+// 文件没有构建约束时返回 false。
+func (ab *AstBundle) GetBuildConstraint() (constraint.Expr, bool, error) {
+	var plusBuildExpr constraint.Expr
+	for _, comment := range ab.getHeaderComments() {
+		if constraint.IsGoBuild(comment.Text) {
+			expr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				return nil, false, erero.Wro(err)
+			}
+			return expr, true, nil
+		}
+		if constraint.IsPlusBuild(comment.Text) {
+			expr, err := constraint.Parse(comment.Text)
+			if err != nil {
+				return nil, false, erero.Wro(err)
+			}
+			if plusBuildExpr == nil {
+				plusBuildExpr = expr
+			} else {
+				plusBuildExpr = &constraint.AndExpr{X: plusBuildExpr, Y: expr}
+			}
+		}
+	}
+	return plusBuildExpr, plusBuildExpr != nil, nil
+}
+
+// SetBuildConstraint writes the expression as the //go:build line of the file, replacing any existing constraint lines.
+// A nil expression removes the constraint.
+//
+// SetBuildConstraint 将表达式写为文件的 //go:build 行，替换已有的约束行。
+// 表达式为 nil 时删除约束。
+func (ab *AstBundle) SetBuildConstraint(expr constraint.Expr) error {
+	return ab.mutate(nil, func(ctx *mutateContext, nodes []ast.Node) error {
+		var inserted = expr == nil
+		for _, comment := range getHeaderComments(ctx.file) {
+			if !constraint.IsGoBuild(comment.Text) && !constraint.IsPlusBuild(comment.Text) {
+				continue
+			}
+			if !inserted && constraint.IsGoBuild(comment.Text) {
+				ctx.editSet.Replace(comment, []byte("//go:build "+expr.String()))
+				inserted = true
+				continue
+			}
+			sdx, edx := ctx.widenRange(syntaxgo_astnode.SdxEdxV2(ctx.fset, comment))
+			ctx.editSet.ReplaceRange(sdx, edx, nil)
+		}
+		if !inserted {
+			ctx.editSet.ReplaceRange(0, 0, []byte("//go:build "+expr.String()+"\n\n"))
+		}
+		return nil
+	})
+}
+
+// SetBuildConstraintText parses the text, such as "linux && !cgo", and writes it as the //go:build line of the file.
+// SetBuildConstraintText 解析文本（比如 "linux && !cgo"），并将其写为文件的 //go:build 行。
+func (ab *AstBundle) SetBuildConstraintText(text string) error {
+	expr, err := constraint.Parse("//go:build " + text)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return ab.SetBuildConstraint(expr)
+}
+
+// MatchBuildConstraint reports whether the file is built for the GOOS, GOARCH and tags, a file without constraint always matches.
+// MatchBuildConstraint 判断文件是否会在该 GOOS、GOARCH 和标签下构建，没有约束的文件总是匹配。
+func (ab *AstBundle) MatchBuildConstraint(goos string, goarch string, tags []string) (bool, error) {
+	expr, ok, err := ab.GetBuildConstraint()
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	if !ok {
+		return true, nil
+	}
+	return EvalBuildConstraint(expr, goos, goarch, tags), nil
+}
+
+// EvalBuildConstraint evaluates the expression for the GOOS, GOARCH and tags, the same way the go command does.
+// The "unix" tag, the implied OS tags like "linux" for "android", and the go1.N release tags are satisfied too.
+//
+// EvalBuildConstraint 以与 go 命令相同的方式，针对 GOOS、GOARCH 和标签求值表达式。
+// "unix" 标签、隐含的系统标签（比如 "android" 隐含 "linux"）以及 go1.N 版本标签同样会被满足。
+func EvalBuildConstraint(expr constraint.Expr, goos string, goarch string, tags []string) bool {
+	return expr.Eval(func(tag string) bool {
+		switch {
+		case tag == goos || tag == goarch || slices.Contains(tags, tag):
+			return true
+		case tag == "unix":
+			return slices.Contains(unixOSList, goos)
+		case tag == "linux":
+			return goos == "android"
+		case tag == "solaris":
+			return goos == "illumos"
+		case tag == "darwin":
+			return goos == "ios"
+		default:
+			return slices.Contains(build.Default.ReleaseTags, tag)
+		}
+	})
+}
+
+// unixOSList is the GOOS values satisfying the "unix" build tag.
+// unixOSList 是满足 "unix" 构建标签的 GOOS 取值。
+var unixOSList = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd", "openbsd", "solaris"}
+
+func (ab *AstBundle) getHeaderComments() []*ast.Comment {
+	return getHeaderComments(ab.file)
+}
+
+// getHeaderComments returns the comments before the package clause, where build constraints live.
+// getHeaderComments 返回 package 子句之前的注释，构建约束位于其中。
+func getHeaderComments(astFile *ast.File) []*ast.Comment {
+	var comments []*ast.Comment
+	for _, commentGroup := range astFile.Comments {
+		if commentGroup.Pos() >= astFile.Package {
+			break
+		}
+		comments = append(comments, commentGroup.List...)
+	}
+	return comments
+}
@@ -0,0 +1,171 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const directivesExampleCode = `//go:build linux && !cgo
+
+// Package example shows directives.
+package example
+
+import _ "embed"
+
+//go:generate stringer -type=Kind
+type Kind int
+
+//go:embed hello.txt
+var hello string
+
+//nolint:errcheck,unused
+func run() {}
+`
+
+// TestParseDirective tests parsing directive comments
+// Verifies normal comments with a space after "//" are not directives
+//
+// TestParseDirective 测试解析指令注释
+// 验证 "//" 之后带空格的普通注释不是指令
+func TestParseDirective(t *testing.T) {
+	name, args, ok := ParseDirective("//go:linkname localName runtime.nanotime")
+	require.True(t, ok)
+	require.Equal(t, "go:linkname", name)
+	require.Equal(t, "localName runtime.nanotime", args)
+
+	name, args, ok = ParseDirective("//nolint:errcheck")
+	require.True(t, ok)
+	require.Equal(t, "nolint", name)
+	require.Equal(t, "errcheck", args)
+
+	_, _, ok = ParseDirective("// go:generate is mentioned in a sentence")
+	require.False(t, ok)
+	_, _, ok = ParseDirective("//TODO: fix it")
+	require.False(t, ok)
+}
+
+// TestAstBundle_ListDirectives tests listing directives with the declarations they are attached to
+// Verifies the build constraint is not listed as a directive
+//
+// TestAstBundle_ListDirectives 测试列出指令及其附加的声明
+// 验证构建约束不会作为指令列出
+func TestAstBundle_ListDirectives(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(directivesExampleCode))
+	require.NoError(t, err)
+
+	directives := astBundle.ListDirectives()
+	require.Len(t, directives, 3)
+	require.Equal(t, "go:generate", directives[0].Name)
+	require.Equal(t, "stringer -type=Kind", directives[0].Args)
+	require.Equal(t, astBundle.file.Decls[1], directives[0].Node)
+	require.Equal(t, "go:embed", directives[1].Name)
+	require.Equal(t, "nolint", directives[2].Name)
+	require.Equal(t, "errcheck,unused", directives[2].Args)
+	require.IsType(t, &ast.FuncDecl{}, directives[2].Node)
+}
+
+// TestAstBundle_EditDirectives tests adding, changing and removing directives
+// Verifies the directives are written directly above their declarations
+//
+// TestAstBundle_EditDirectives 测试添加、修改和删除指令
+// 验证指令写在其声明的正上方
+func TestAstBundle_EditDirectives(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(directivesExampleCode))
+	require.NoError(t, err)
+
+	require.NoError(t, astBundle.SetDirectiveArgs(astBundle.FindDirectives("go:generate")[0], "stringer -type=Kind -trimprefix=Kind"))
+	require.NoError(t, astBundle.RemoveDirective(astBundle.FindDirectives("nolint")[0]))
+	require.NoError(t, astBundle.AddDirective(astBundle.file.Decls[3], "go:noinline"))
+	require.NoError(t, astBundle.AddDirective(nil, "go:generate go run gen.go"))
+	require.Error(t, astBundle.AddDirective(nil, "not a directive"))
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `//go:build linux && !cgo
+
+// Package example shows directives.
+package example
+
+//go:generate go run gen.go
+
+import _ "embed"
+
+//go:generate stringer -type=Kind -trimprefix=Kind
+type Kind int
+
+//go:embed hello.txt
+var hello string
+
+//go:noinline
+func run() {}
+`
+	require.Equal(t, expected, string(newSrc))
+}
+
+// TestAstBundle_BuildConstraint tests reading, evaluating and replacing the build constraint
+// Verifies old "// +build" lines are replaced by one //go:build line
+//
+// TestAstBundle_BuildConstraint 测试读取、求值和替换构建约束
+// 验证旧的 "// +build" 行会被替换为一行 //go:build
+func TestAstBundle_BuildConstraint(t *testing.T) {
+	astBundle, err := NewAstBundleV1([]byte(directivesExampleCode))
+	require.NoError(t, err)
+
+	expr, ok, err := astBundle.GetBuildConstraint()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "linux && !cgo", expr.String())
+
+	match, err := astBundle.MatchBuildConstraint("linux", "amd64", nil)
+	require.NoError(t, err)
+	require.True(t, match)
+	match, err = astBundle.MatchBuildConstraint("android", "arm64", []string{"cgo"})
+	require.NoError(t, err)
+	require.False(t, match)
+	match, err = astBundle.MatchBuildConstraint("windows", "amd64", nil)
+	require.NoError(t, err)
+	require.False(t, match)
+
+	require.NoError(t, astBundle.SetBuildConstraintText("unix || windows"))
+	expr, ok, err = astBundle.GetBuildConstraint()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "unix || windows", expr.String())
+	require.True(t, EvalBuildConstraint(expr, "darwin", "arm64", nil))
+	require.False(t, EvalBuildConstraint(expr, "js", "wasm", nil))
+
+	require.NoError(t, astBundle.SetBuildConstraint(nil))
+	_, ok, err = astBundle.GetBuildConstraint()
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+// TestAstBundle_SetBuildConstraint_PlusBuild tests replacing old "// +build" lines
+// Verifies the file gets a single //go:build line
+//
+// TestAstBundle_SetBuildConstraint_PlusBuild 测试替换旧的 "// +build" 行
+// 验证文件只留下一行 //go:build
+func TestAstBundle_SetBuildConstraint_PlusBuild(t *testing.T) {
+	const code = `// +build linux darwin
+// +build amd64
+
+package example
+`
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+
+	expr, ok, err := astBundle.GetBuildConstraint()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "(linux || darwin) && amd64", expr.String())
+
+	require.NoError(t, astBundle.SetBuildConstraint(&constraint.TagExpr{Tag: "linux"}))
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	require.Equal(t, "//go:build linux\n\npackage example\n", string(newSrc))
+}
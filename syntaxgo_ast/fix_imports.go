@@ -0,0 +1,302 @@
+package syntaxgo_ast
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_reflect"
+	"golang.org/x/exp/maps"
+)
+
+// ImportRegistry maps package names to import paths, it is used to resolve missing package qualifiers offline.
+// ImportRegistry 将包名映射到导入路径，用于离线解析缺失的包限定符。
+type ImportRegistry struct {
+	nameToPath map[string]string // Package name to import path / 包名到导入路径
+	pathToName map[string]string // Import path to package name / 导入路径到包名
+}
+
+// NewImportRegistry creates an empty ImportRegistry.
+// NewImportRegistry 创建一个空的 ImportRegistry。
+func NewImportRegistry() *ImportRegistry {
+	return &ImportRegistry{
+		nameToPath: map[string]string{},
+		pathToName: map[string]string{},
+	}
+}
+
+// AddNamedPkgPath registers the import path under the package name, a later registration of the same name wins.
+// AddNamedPkgPath 以包名登记导入路径，同名的后登记者生效。
+func (registry *ImportRegistry) AddNamedPkgPath(name string, pkgPath string) *ImportRegistry {
+	registry.nameToPath[name] = pkgPath
+	registry.pathToName[pkgPath] = name
+	return registry
+}
+
+// AddPkgPath registers the import path under the package name guessed from the path.
+// AddPkgPath 以根据路径推测的包名登记导入路径。
+func (registry *ImportRegistry) AddPkgPath(pkgPath string) *ImportRegistry {
+	return registry.AddNamedPkgPath(GuessPackageName(pkgPath), pkgPath)
+}
+
+// AddPkgPaths registers the import paths under the package names guessed from the paths.
+// AddPkgPaths 以根据路径推测的包名登记多个导入路径。
+func (registry *ImportRegistry) AddPkgPaths(pkgPaths []string) *ImportRegistry {
+	for _, pkgPath := range pkgPaths {
+		registry.AddPkgPath(pkgPath)
+	}
+	return registry
+}
+
+// AddReferencedTypes registers the package paths of the types, found with syntaxgo_reflect.GetPkgPaths.
+// AddReferencedTypes 登记这些类型所在的包路径，包路径通过 syntaxgo_reflect.GetPkgPaths 获取。
+func (registry *ImportRegistry) AddReferencedTypes(reflectTypes []reflect.Type) *ImportRegistry {
+	return registry.AddPkgPaths(syntaxgo_reflect.GetPkgPaths(reflectTypes))
+}
+
+// AddModulePackages registers every package of the module enclosing the directory, using the package names declared in the files.
+// AddModulePackages 登记包含该目录的模块中的所有包，使用文件中声明的包名。
+func (registry *ImportRegistry) AddModulePackages(root string) error {
+	importer := NewOfflineImporter(token.NewFileSet(), root)
+	if importer.modulePath == "" {
+		return erero.Errorf("no go.mod found above %s", root)
+	}
+	return filepath.WalkDir(importer.moduleRoot, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return erero.Wro(err)
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != importer.moduleRoot && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		buildPkg, err := importer.buildCtx.ImportDir(path, 0)
+		if err != nil || buildPkg.Name == "" || buildPkg.Name == "main" {
+			return nil // Skip directories without an importable package. // 跳过没有可导入包的目录。
+		}
+		if pkgPath, ok := importer.ImportPathOf(path); ok {
+			registry.AddNamedPkgPath(buildPkg.Name, pkgPath)
+		}
+		return nil
+	})
+}
+
+// LookupPkgPath returns the import path registered under the package name.
+// LookupPkgPath 返回以该包名登记的导入路径。
+func (registry *ImportRegistry) LookupPkgPath(name string) (string, bool) {
+	pkgPath, ok := registry.nameToPath[name]
+	return pkgPath, ok
+}
+
+// LookupName returns the package name registered for the import path.
+// LookupName 返回为该导入路径登记的包名。
+func (registry *ImportRegistry) LookupName(pkgPath string) (string, bool) {
+	name, ok := registry.pathToName[pkgPath]
+	return name, ok
+}
+
+// GuessPackageName guesses the package name from the import path, like goimports does when the package cannot be loaded.
+// It takes the last element, skips a major version suffix like "/v2", and trims "go-" prefixes and ".vN" or ".go" suffixes.
+//
+// GuessPackageName 根据导入路径推测包名，与 goimports 无法加载包时的做法一致。
+// 取最后一段，跳过 "/v2" 这样的主版本后缀，并去掉 "go-" 前缀以及 ".vN" 或 ".go" 后缀。
+func GuessPackageName(pkgPath string) string {
+	elems := strings.Split(pkgPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if idx := strings.LastIndex(name, "."); idx > 0 && (isMajorVersion(name[idx+1:]) || name[idx+1:] == "go") {
+		name = name[:idx]
+	}
+	var sb strings.Builder
+	for _, c := range name {
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9' && sb.Len() > 0) {
+			sb.WriteRune(c)
+		}
+	}
+	return strings.ToLower(sb.String())
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// FindUsedQualifiers returns the names used as X in selector expressions X.Sel, where X is not a local declaration.
+// Such names are package qualifiers or names declared in sibling files.
+// The types info is used when the file is type-checked, and files parsed without object resolution are resolved again.
+//
+// FindUsedQualifiers 返回在选择器表达式 X.Sel 中作为 X 使用、且不是本地声明的名称。
+// 这些名称是包限定符或在同包其他文件中声明的名称。
+// 文件已类型检查时使用类型信息，未进行对象解析的文件会被重新解析。
+func (ab *AstBundle) FindUsedQualifiers() []string {
+	var isQualifier = func(ident *ast.Ident) bool {
+		return ident.Obj == nil
+	}
+	var astFile = ab.file
+	if ab.typesBundle != nil {
+		info := ab.typesBundle.GetInfo()
+		isQualifier = func(ident *ast.Ident) bool {
+			object, ok := info.Uses[ident]
+			if !ok {
+				return true // Unresolved, such as a missing import. // 未解析的名称，比如缺失的导入。
+			}
+			_, ok = object.(*types.PkgName)
+			return ok
+		}
+	} else if astFile.Scope == nil {
+		// Parsed with parser.SkipObjectResolution, resolve on a new parse of the code.
+		// 使用 parser.SkipObjectResolution 解析，通过重新解析代码来解析对象。
+		if resolvedFile, err := ab.parseWithObjectResolution(); err == nil {
+			astFile = resolvedFile
+		}
+	}
+
+	var names = map[string]bool{}
+	ast.Inspect(astFile, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok && isQualifier(ident) {
+				names[ident.Name] = true
+			}
+		}
+		return true
+	})
+	results := maps.Keys(names)
+	slices.Sort(results)
+	return results
+}
+
+// parseWithObjectResolution prints the AST and parses it again with object resolution.
+// parseWithObjectResolution 打印 AST 并在进行对象解析的情况下重新解析。
+func (ab *AstBundle) parseWithObjectResolution() (*ast.File, error) {
+	var buffer bytes.Buffer
+	if err := printer.Fprint(&buffer, ab.fset, ab.file); err != nil {
+		return nil, erero.Wro(err)
+	}
+	astFile, err := parser.ParseFile(token.NewFileSet(), "", buffer.Bytes(), 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return astFile, nil
+}
+
+// GetImportName returns the name the import is used by in the file: the alias when set, otherwise the package name.
+// The package name comes from the registry, then from the source files found offline, and is guessed from the path at last.
+//
+// GetImportName 返回导入在文件中的使用名称：有别名时为别名，否则为包名。
+// 包名依次来自注册表、离线找到的源文件，最后根据路径推测。
+func (ab *AstBundle) GetImportName(importSpec *ast.ImportSpec, registry *ImportRegistry, importer *OfflineImporter) string {
+	if importSpec.Name != nil {
+		return importSpec.Name.Name
+	}
+	pkgPath, _ := strconv.Unquote(importSpec.Path.Value)
+	if registry != nil {
+		if name, ok := registry.LookupName(pkgPath); ok {
+			return name
+		}
+	}
+	if importer != nil {
+		if name, ok := importer.GetPackageName(pkgPath); ok {
+			return name
+		}
+	}
+	return GuessPackageName(pkgPath)
+}
+
+// RemoveUnusedImports deletes the imports whose names are not used as selector qualifiers, through DeleteImport and DeleteNamedImport.
+// Blank, dot and "C" imports are kept. Returns the removed import paths.
+//
+// RemoveUnusedImports 删除名称未作为选择器限定符使用的导入，通过 DeleteImport 和 DeleteNamedImport 删除。
+// 空白导入、点导入和 "C" 导入会被保留。返回被删除的导入路径。
+func (ab *AstBundle) RemoveUnusedImports(registry *ImportRegistry) (removed []string) {
+	used := map[string]bool{}
+	for _, name := range ab.FindUsedQualifiers() {
+		used[name] = true
+	}
+	importer := ab.newOfflineImporter()
+	for _, importSpec := range slices.Clone(ab.file.Imports) {
+		pkgPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil || pkgPath == "C" {
+			continue
+		}
+		name := ab.GetImportName(importSpec, registry, importer)
+		if name == "_" || name == "." || used[name] {
+			continue
+		}
+		if importSpec.Name != nil {
+			ab.DeleteNamedImport(importSpec.Name.Name, pkgPath)
+		} else {
+			ab.DeleteImport(pkgPath)
+		}
+		removed = append(removed, pkgPath)
+	}
+	return removed
+}
+
+// AddMissingImports adds imports of the selector qualifiers that are not imported yet and are found in the registry.
+// Returns the added import paths.
+//
+// AddMissingImports 为尚未导入且能在注册表中找到的选择器限定符添加导入。
+// 返回被添加的导入路径。
+func (ab *AstBundle) AddMissingImports(registry *ImportRegistry) (added []string) {
+	importer := ab.newOfflineImporter()
+	imported := map[string]bool{}
+	for _, importSpec := range ab.file.Imports {
+		imported[ab.GetImportName(importSpec, registry, importer)] = true
+	}
+	for _, name := range ab.FindUsedQualifiers() {
+		if imported[name] {
+			continue
+		}
+		pkgPath, ok := registry.LookupPkgPath(name)
+		if !ok {
+			continue
+		}
+		if name == GuessPackageName(pkgPath) {
+			ab.AddImport(pkgPath)
+		} else {
+			ab.AddNamedImport(name, pkgPath)
+		}
+		imported[name] = true
+		added = append(added, pkgPath)
+	}
+	return added
+}
+
+// FixImports removes unused imports and adds missing imports found in the registry, an offline goimports for generated code.
+// Call FormatSource afterwards to get the new source.
+//
+// FixImports 删除未使用的导入并添加能在注册表中找到的缺失导入，相当于面向生成代码的离线 goimports。
+// 之后调用 FormatSource 获取新的源代码。
+func (ab *AstBundle) FixImports(registry *ImportRegistry) (removed []string, added []string) {
+	removed = ab.RemoveUnusedImports(registry)
+	added = ab.AddMissingImports(registry)
+	return removed, added
+}
+
+// newOfflineImporter creates an OfflineImporter for the directory of the file, nil when the directory is unknown.
+// newOfflineImporter 为文件所在目录创建 OfflineImporter，目录未知时返回 nil。
+func (ab *AstBundle) newOfflineImporter() *OfflineImporter {
+	root, err := ab.getRoot()
+	if err != nil {
+		return nil
+	}
+	return NewOfflineImporter(token.NewFileSet(), root)
+}
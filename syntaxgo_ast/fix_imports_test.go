@@ -0,0 +1,150 @@
+package syntaxgo_ast
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/runpath"
+	"github.com/yyle88/syntaxgo/syntaxgo_reflect"
+)
+
+// TestGuessPackageName tests guessing package names from import paths
+// Verifies version suffixes and "go-" prefixes are dropped
+//
+// TestGuessPackageName 测试根据导入路径推测包名
+// 验证版本后缀和 "go-" 前缀会被去掉
+func TestGuessPackageName(t *testing.T) {
+	require.Equal(t, "fmt", GuessPackageName("fmt"))
+	require.Equal(t, "template", GuessPackageName("text/template"))
+	require.Equal(t, "zaplog", GuessPackageName("github.com/yyle88/zaplog"))
+	require.Equal(t, "echo", GuessPackageName("github.com/labstack/echo/v4"))
+	require.Equal(t, "yaml", GuessPackageName("gopkg.in/yaml.v3"))
+	require.Equal(t, "difflib", GuessPackageName("github.com/pmezard/go-difflib"))
+	require.Equal(t, "syntaxgo_ast", GuessPackageName("github.com/yyle88/syntaxgo/syntaxgo_ast"))
+}
+
+// TestAstBundle_FixImports tests removing unused imports and adding missing ones from the registry
+// Verifies aliased and blank imports are handled and unknown qualifiers are left alone
+//
+// TestAstBundle_FixImports 测试删除未使用的导入并从注册表添加缺失的导入
+// 验证别名导入和空白导入被正确处理，未知的限定符保持不变
+func TestAstBundle_FixImports(t *testing.T) {
+	const code = `package main
+
+import (
+	_ "embed"
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+	zzz "github.com/yyle88/zaplog"
+)
+
+func main() {
+	strings := []string{"a"}
+	fmt.Println(strings, yaml.Marshal, tern.BFF, unknown.Value)
+}
+`
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+
+	registry := NewImportRegistry().
+		AddPkgPaths([]string{"fmt", "os", "strings"}).
+		AddNamedPkgPath("tern", "github.com/yyle88/tern")
+
+	removed, added := astBundle.FixImports(registry)
+	require.Equal(t, []string{"os", "strings", "github.com/yyle88/zaplog"}, removed)
+	require.Equal(t, []string{"fmt", "github.com/yyle88/tern"}, added)
+
+	newSrc, err := astBundle.FormatSource()
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `package main
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/yyle88/tern"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func main() {
+	strings := []string{"a"}
+	fmt.Println(strings, yaml.Marshal, tern.BFF, unknown.Value)
+}
+`
+	require.Equal(t, expected, string(newSrc))
+}
+
+// TestImportRegistry_AddReferencedTypes tests registering the package paths of reflect types
+// Verifies the package names are guessed from the paths
+//
+// TestImportRegistry_AddReferencedTypes 测试登记反射类型所在的包路径
+// 验证包名根据路径推测
+func TestImportRegistry_AddReferencedTypes(t *testing.T) {
+	registry := NewImportRegistry().AddReferencedTypes([]reflect.Type{
+		reflect.TypeOf(token.FileSet{}),
+		reflect.TypeOf(ImportPath{}),
+	})
+	pkgPath, ok := registry.LookupPkgPath("token")
+	require.True(t, ok)
+	require.Equal(t, "go/token", pkgPath)
+
+	pkgPath, ok = registry.LookupPkgPath("syntaxgo_ast")
+	require.True(t, ok)
+	require.Equal(t, syntaxgo_reflect.GetPkgPath(ImportPath{}), pkgPath)
+}
+
+// TestImportRegistry_AddModulePackages tests registering every package of the current module
+// Verifies the packages are registered under their declared names
+//
+// TestImportRegistry_AddModulePackages 测试登记当前模块的所有包
+// 验证包以其声明的名称登记
+func TestImportRegistry_AddModulePackages(t *testing.T) {
+	registry := NewImportRegistry()
+	require.NoError(t, registry.AddModulePackages(runpath.PARENT.Path()))
+
+	pkgPath, ok := registry.LookupPkgPath("syntaxgo_search")
+	require.True(t, ok)
+	require.Equal(t, "github.com/yyle88/syntaxgo/syntaxgo_search", pkgPath)
+
+	name, ok := registry.LookupName("github.com/yyle88/syntaxgo/internal/utils")
+	require.True(t, ok)
+	require.Equal(t, "utils", name)
+}
+
+// TestAstBundle_FindUsedQualifiers tests finding qualifiers in files parsed without object resolution or type-checked
+// Verifies a local variable named like a package is not counted as a qualifier
+//
+// TestAstBundle_FindUsedQualifiers 测试在未进行对象解析或已类型检查的文件中查找限定符
+// 验证与包同名的局部变量不会被算作限定符
+func TestAstBundle_FindUsedQualifiers(t *testing.T) {
+	const code = `package main
+
+import "os"
+
+type box struct{ Len int }
+
+func main() {
+	strings := box{}
+	_ = strings.Len
+	os.Exit(0)
+}
+`
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, "", code, parser.ParseComments|parser.SkipObjectResolution)
+	require.NoError(t, err)
+	require.Nil(t, astFile.Scope)
+	require.Equal(t, []string{"os"}, NewAstBundle(fset, astFile).FindUsedQualifiers())
+
+	astBundle, err := NewAstBundleV1([]byte(code))
+	require.NoError(t, err)
+	_, err = astBundle.TypeCheck()
+	require.NoError(t, err)
+	require.Equal(t, []string{"os"}, astBundle.FindUsedQualifiers())
+}
@@ -0,0 +1,289 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+	"github.com/yyle88/syntaxgo/syntaxgo_astnode"
+)
+
+// ImportPath is an import path with an optional alias, the alias can be a name, "_" or ".".
+// ImportPath 表示带可选别名的导入路径，别名可以是名称、"_" 或 "."。
+type ImportPath struct {
+	Alias string // Import alias, empty means no alias / 导入别名，为空表示没有别名
+	Path  string // Import path without quotes / 不带引号的导入路径
+}
+
+// NewImportPath creates an ImportPath with the alias and path.
+// NewImportPath 使用别名和路径创建 ImportPath。
+func NewImportPath(alias string, path string) *ImportPath {
+	return &ImportPath{Alias: alias, Path: path}
+}
+
+// NewImportPaths creates ImportPaths without alias from the paths.
+// NewImportPaths 根据路径创建不带别名的 ImportPath 列表。
+func NewImportPaths(paths []string) []*ImportPath {
+	var importPaths = make([]*ImportPath, 0, len(paths))
+	for _, path := range paths {
+		importPaths = append(importPaths, NewImportPath("", path))
+	}
+	return importPaths
+}
+
+// Validate checks the path is not empty and has no quotes, and the alias is an identifier, "_" or ".".
+// Validate 检查路径非空且不含引号，别名是标识符、"_" 或 "."。
+func (importPath *ImportPath) Validate() error {
+	if importPath.Path == "" {
+		return erero.New("import path is empty")
+	}
+	if strings.ContainsAny(importPath.Path, "\"` \t\n") {
+		return erero.Errorf("import path %q contains quotes or spaces", importPath.Path)
+	}
+	if alias := importPath.Alias; alias != "" && alias != "_" && alias != "." && !token.IsIdentifier(alias) {
+		return erero.Errorf("import alias %q of %s is not an identifier", alias, importPath.Path)
+	}
+	return nil
+}
+
+// String returns the import spec text, such as `"fmt"` or `xx "example.com/pkg"`.
+// String 返回导入声明文本，比如 `"fmt"` 或 `xx "example.com/pkg"`。
+func (importPath *ImportPath) String() string {
+	if importPath.Alias == "" {
+		return strconv.Quote(importPath.Path)
+	}
+	return importPath.Alias + " " + strconv.Quote(importPath.Path)
+}
+
+// importLine is one spec line of the merged import declaration, with its own comments.
+// importLine 是合并后导入声明中的一行，带有其自身的注释。
+type importLine struct {
+	path string // Import path / 导入路径
+	text string // Line text with doc and line comments / 包含文档注释和行尾注释的行文本
+}
+
+// InjectImportsV2 merges the import paths into the import declaration of the source and returns formatted source.
+// The imports are grouped as standard packages, third-party packages and packages of the current module (modulePath, can be empty).
+// Existing imports keep their alias and comments, "C" imports stay untouched. Errors are returned instead of panics.
+// An import without alias is skipped when the path is already imported with an alias, which the code then uses.
+//
+// InjectImportsV2 将导入路径合并到源代码的导入声明中，并返回格式化后的源代码。
+// 导入按标准库包、第三方包和当前模块（modulePath，可为空）的包分组。
+// 已有的导入保留其别名和注释，"C" 导入保持不变。出错时返回错误而不是 panic。
+// 路径已以别名导入时，跳过不带别名的导入，代码使用已有的别名。
+func InjectImportsV2(source []byte, importPaths []*ImportPath, modulePath string) ([]byte, error) {
+	for _, importPath := range importPaths {
+		if err := importPath.Validate(); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	fset := token.NewFileSet()
+	astBundle, err := NewAstBundleV2(fset, source)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	astFile := astBundle.file
+
+	// Collect the import declarations to merge, "C" imports are kept apart for the cgo preamble.
+	// 收集待合并的导入声明，"C" 导入为了 cgo 前导注释单独保留。
+	var importDecls []*ast.GenDecl
+	var lines []*importLine
+	var freeComments []string
+	var existing = map[string]bool{}
+	var imported = map[string]bool{}
+	var named = map[string]bool{}
+	for _, decl := range astFile.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		if isImportC(genDecl) {
+			continue
+		}
+		importDecls = append(importDecls, genDecl)
+		if len(importDecls) > 1 && genDecl.Doc != nil {
+			// The doc of a merged declaration is kept as a free comment. // 被合并的声明的文档注释作为独立注释保留。
+			freeComments = append(freeComments, syntaxgo_astnode.GetTextV2(fset, source, genDecl.Doc))
+		}
+		freeGroups := getFreeImportComments(astFile, genDecl)
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			path, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			var alias string
+			if importSpec.Name != nil {
+				alias = importSpec.Name.Name
+			}
+			existing[NewImportPath(alias, path).String()] = true
+			imported[path] = true
+			if alias != "_" && alias != "." {
+				named[path] = true
+			}
+			var text = getImportSpecText(fset, source, importSpec)
+			// Free comments before the spec move together with it. // 位于导入项之前的独立注释随其一起移动。
+			for len(freeGroups) > 0 && freeGroups[0].End() <= importSpec.Pos() {
+				freeComments = append(freeComments, syntaxgo_astnode.GetTextV2(fset, source, freeGroups[0]))
+				freeGroups = freeGroups[1:]
+			}
+			if len(freeComments) > 0 {
+				text = strings.Join(freeComments, "\n") + "\n" + text
+				freeComments = nil
+			}
+			lines = append(lines, &importLine{path: path, text: text})
+		}
+		for _, group := range freeGroups {
+			freeComments = append(freeComments, syntaxgo_astnode.GetTextV2(fset, source, group))
+		}
+	}
+
+	var changed = false
+	for _, importPath := range importPaths {
+		if existing[importPath.String()] {
+			continue
+		}
+		if importPath.Alias == "_" && imported[importPath.Path] {
+			continue // A blank import is redundant when the path is imported. // 路径已导入时空白导入是多余的。
+		}
+		if importPath.Alias == "" && named[importPath.Path] {
+			continue // The path is imported with an alias, which the code uses. // 路径已以别名导入，代码使用该别名。
+		}
+		existing[importPath.String()] = true
+		imported[importPath.Path] = true
+		lines = append(lines, &importLine{path: importPath.Path, text: importPath.String()})
+		changed = true
+	}
+	if !changed {
+		return source, nil
+	}
+
+	block := createGroupedImportBlock(lines, freeComments, modulePath)
+	editSet := syntaxgo_astnode.NewEditSet(fset)
+	if len(importDecls) == 0 {
+		lineEnd := getPackageLineEnd(fset, source, astFile)
+		editSet.InsertAfter(syntaxgo_astnode.NewNode(lineEnd, lineEnd), []byte("\n\n"+block))
+	} else {
+		editSet.Replace(importDecls[0], []byte(block))
+		for _, genDecl := range importDecls[1:] {
+			if genDecl.Doc != nil {
+				editSet.Delete(syntaxgo_astnode.NewNode(genDecl.Doc.Pos(), genDecl.End()))
+			} else {
+				editSet.Delete(genDecl)
+			}
+		}
+	}
+	newSource, _, err := editSet.Apply(source)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	result, err := format.Source(newSource)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return result, nil
+}
+
+// ClassifyImportPath returns the group of the import path: 0 for standard packages, 1 for third-party packages and 2 for packages of the module.
+// ClassifyImportPath 返回导入路径所属的分组：0 表示标准库包，1 表示第三方包，2 表示当前模块的包。
+func ClassifyImportPath(path string, modulePath string) int {
+	if modulePath != "" && hasPathPrefix(path, modulePath) {
+		return 2
+	}
+	if isStandardPath(path) {
+		return 0
+	}
+	return 1
+}
+
+func createGroupedImportBlock(lines []*importLine, trailingComments []string, modulePath string) string {
+	var groups = make([][]*importLine, 3)
+	for _, line := range lines {
+		group := ClassifyImportPath(line.path, modulePath)
+		groups[group] = append(groups[group], line)
+	}
+	ptx := utils.NewPTX()
+	ptx.Println("import (")
+	var first = true
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if !first {
+			ptx.Println() // Blank line between groups. // 分组之间留空行。
+		}
+		first = false
+		slices.SortStableFunc(group, func(a, b *importLine) int {
+			return strings.Compare(a.path, b.path)
+		})
+		for _, line := range group {
+			ptx.Println(line.text)
+		}
+	}
+	for _, comment := range trailingComments {
+		ptx.Println(comment)
+	}
+	ptx.Print(")")
+	return ptx.String()
+}
+
+// getFreeImportComments returns the comment groups inside the import declaration not attached to any spec.
+// getFreeImportComments 返回导入声明内未附着在任何导入项上的注释组。
+func getFreeImportComments(astFile *ast.File, genDecl *ast.GenDecl) []*ast.CommentGroup {
+	var attached = map[*ast.CommentGroup]bool{}
+	for _, spec := range genDecl.Specs {
+		importSpec := spec.(*ast.ImportSpec)
+		attached[importSpec.Doc] = true
+		attached[importSpec.Comment] = true
+	}
+	var groups []*ast.CommentGroup
+	for _, group := range astFile.Comments {
+		if group.Pos() > genDecl.Pos() && group.End() < genDecl.End() && !attached[group] {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// getPackageLineEnd returns the end of the package clause line, after its trailing comments.
+// getPackageLineEnd 返回 package 子句所在行的行尾，位于其行尾注释之后。
+func getPackageLineEnd(fset *token.FileSet, source []byte, astFile *ast.File) token.Pos {
+	end := astFile.Name.End()
+	line := fset.Position(end).Line
+	for _, group := range astFile.Comments {
+		if group.Pos() >= end && fset.Position(group.Pos()).Line == line && group.End() > end {
+			end = group.End()
+		}
+	}
+	tokenFile := fset.File(end)
+	offset := tokenFile.Offset(end)
+	for offset < len(source) && source[offset] != '\n' {
+		offset++
+	}
+	return tokenFile.Pos(offset)
+}
+
+func getImportSpecText(fset *token.FileSet, source []byte, importSpec *ast.ImportSpec) string {
+	var text = syntaxgo_astnode.GetTextV2(fset, source, importSpec)
+	if importSpec.Doc != nil {
+		text = syntaxgo_astnode.GetTextV2(fset, source, importSpec.Doc) + "\n" + text
+	}
+	if importSpec.Comment != nil {
+		text = text + " " + syntaxgo_astnode.GetTextV2(fset, source, importSpec.Comment)
+	}
+	return text
+}
+
+func isImportC(genDecl *ast.GenDecl) bool {
+	for _, spec := range genDecl.Specs {
+		if importSpec, ok := spec.(*ast.ImportSpec); ok && importSpec.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
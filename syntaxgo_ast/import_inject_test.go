@@ -0,0 +1,155 @@
+package syntaxgo_ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestInjectImportsV2 tests merging aliased imports into the existing import declarations
+// Verifies the result has one grouped import block and keeps existing comments
+//
+// TestInjectImportsV2 测试将带别名的导入合并到已有的导入声明中
+// 验证结果只有一个分组的导入块且保留已有的注释
+func TestInjectImportsV2(t *testing.T) {
+	const code = `package main
+
+import "time"
+
+import (
+	"github.com/yyle88/syntaxgo/syntaxgo_reflect" // reflect helpers
+)
+
+func main() {
+	fmt.Println(time.Now(), zaplog.LOG, syntaxgo_reflect.GetTypes(nil))
+}
+`
+	newSrc, err := InjectImportsV2([]byte(code), []*ImportPath{
+		NewImportPath("", "fmt"),
+		NewImportPath("", "github.com/yyle88/zaplog"),
+		NewImportPath("_", "embed"),
+		NewImportPath("", "time"),
+	}, "github.com/yyle88/syntaxgo")
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+
+	const expected = `package main
+
+import (
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/yyle88/zaplog"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_reflect" // reflect helpers
+)
+
+func main() {
+	fmt.Println(time.Now(), zaplog.LOG, syntaxgo_reflect.GetTypes(nil))
+}
+`
+	require.Equal(t, expected, string(newSrc))
+}
+
+// TestInjectImportsV2_NoImports tests injecting imports into source without import declarations
+// Verifies dot imports are written after the package clause
+//
+// TestInjectImportsV2_NoImports 测试向没有导入声明的源代码注入导入
+// 验证点导入写在 package 子句之后
+func TestInjectImportsV2_NoImports(t *testing.T) {
+	const code = "package main\n\nfunc main() {\n\tPrintln(strings.ToUpper(\"abc\"))\n}\n"
+	newSrc, err := InjectImportsV2([]byte(code), []*ImportPath{
+		NewImportPath(".", "fmt"),
+		NewImportPath("", "strings"),
+	}, "")
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Contains(t, string(newSrc), "import (\n\t. \"fmt\"\n\t\"strings\"\n)\n")
+}
+
+// TestInjectImportsV2_Errors tests returning errors on wrong input instead of panicking
+// Verifies wrong source, wrong paths and wrong aliases are reported
+//
+// TestInjectImportsV2_Errors 测试输入错误时返回错误而不是 panic
+// 验证错误的源代码、错误的路径和错误的别名都会被报告
+func TestInjectImportsV2_Errors(t *testing.T) {
+	_, err := InjectImportsV2([]byte("not go code"), NewImportPaths([]string{"fmt"}), "")
+	require.Error(t, err)
+
+	_, err = InjectImportsV2([]byte("package main\n"), NewImportPaths([]string{`"fmt"`}), "")
+	require.Error(t, err)
+
+	_, err = InjectImportsV2([]byte("package main\n"), []*ImportPath{NewImportPath("a-b", "fmt")}, "")
+	require.Error(t, err)
+}
+
+// TestPackageImportOptions_InjectImportsV2 tests injecting plain and aliased imports from options
+// Verifies aliases set on the options are written
+//
+// TestPackageImportOptions_InjectImportsV2 测试从选项注入普通导入和带别名的导入
+// 验证选项中设置的别名被写入
+func TestPackageImportOptions_InjectImportsV2(t *testing.T) {
+	options := NewPackageImportOptions().
+		SetPkgPath("fmt").
+		SetNamedPkgPath("pkgerrors", "github.com/pkg/errors")
+	require.Len(t, options.GetImportPaths(), 2)
+
+	newSrc, err := options.InjectImportsV2([]byte("package main\n"), "")
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Contains(t, string(newSrc), `pkgerrors "github.com/pkg/errors"`)
+}
+
+// TestInjectImportsV2_PackageComment tests injecting imports after a package clause with a trailing comment
+// Verifies the comment stays on the package line
+//
+// TestInjectImportsV2_PackageComment 测试在带行尾注释的 package 子句后注入导入
+// 验证注释保留在 package 所在行
+func TestInjectImportsV2_PackageComment(t *testing.T) {
+	newSrc, err := InjectImportsV2([]byte("package a // trailing\n\nvar _ = fmt.Sprint()\n"), NewImportPaths([]string{"fmt"}), "")
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package a // trailing\n\nimport (\n\t\"fmt\"\n)\n\nvar _ = fmt.Sprint()\n", string(newSrc))
+}
+
+// TestInjectImportsV2_FreeComments tests rebuilding an import block with comments not attached to any spec
+// Verifies the free comments and the doc of a merged declaration are kept in source order
+//
+// TestInjectImportsV2_FreeComments 测试重建包含未附着在导入项上的注释的导入块
+// 验证独立注释和被合并声明的文档注释都按源码顺序保留
+func TestInjectImportsV2_FreeComments(t *testing.T) {
+	const code = `package a
+
+import (
+	// standard packages
+
+	"os"
+
+	// keep at the end
+)
+
+// strings doc
+import "strings"
+`
+	newSrc, err := InjectImportsV2([]byte(code), NewImportPaths([]string{"fmt"}), "")
+	require.NoError(t, err)
+	t.Log(string(newSrc))
+	require.Equal(t, "package a\n\nimport (\n\t\"fmt\"\n\t// standard packages\n\t\"os\"\n\t// keep at the end\n\t// strings doc\n\t\"strings\"\n)\n", string(newSrc))
+}
+
+// TestInjectImportsV2_AliasedPath tests injecting a path already imported with an alias
+// Verifies the path is not imported twice
+//
+// TestInjectImportsV2_AliasedPath 测试注入一个已以别名导入的路径
+// 验证该路径不会被重复导入
+func TestInjectImportsV2_AliasedPath(t *testing.T) {
+	const code = "package a\n\nimport x \"fmt\"\n\nvar _ = x.Sprint()\n"
+	newSrc, err := InjectImportsV2([]byte(code), NewImportPaths([]string{"fmt"}), "")
+	require.NoError(t, err)
+	require.Equal(t, code, string(newSrc))
+
+	newSrc, err = InjectImportsV2([]byte("package a\n\nimport _ \"fmt\"\n"), NewImportPaths([]string{"fmt"}), "")
+	require.NoError(t, err)
+	require.Contains(t, string(newSrc), "\t\"fmt\"\n")
+}
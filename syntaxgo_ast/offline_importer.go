@@ -0,0 +1,330 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/yyle88/erero"
+)
+
+// OfflineImporter type-checks imported packages from source without network access.
+// It looks up standard packages in GOROOT, packages of the current module in its directory,
+// and required modules in the module cache (GOMODCACHE), following the go.mod of the current module.
+//
+// OfflineImporter 在不访问网络的情况下从源码类型检查被导入的包。
+// 标准库包从 GOROOT 中查找，当前模块的包从模块目录中查找，
+// 依赖模块根据当前模块的 go.mod 从模块缓存（GOMODCACHE）中查找。
+type OfflineImporter struct {
+	fset       *token.FileSet            // FileSet used when parsing imported packages. // 解析被导入包时使用的文件集
+	buildCtx   build.Context             // Build context used to select files. // 用于选择文件的构建上下文
+	modCache   string                    // Module cache directory. // 模块缓存目录
+	moduleRoot string                    // Directory holding go.mod of the current module. // 当前模块 go.mod 所在目录
+	modulePath string                    // Module path of the current module. // 当前模块的模块路径
+	requires   map[string]string         // Required module path to version. // 依赖模块路径到版本
+	replaces   map[string]string         // Module path to local replacement directory. // 模块路径到本地替换目录
+	packages   map[string]*types.Package // Checked packages by source directory. // 按源码目录缓存已检查的包
+}
+
+// NewOfflineImporter creates an OfflineImporter for code in the given directory.
+// The go.mod of the enclosing module is found by walking up from the directory.
+//
+// NewOfflineImporter 为给定目录中的代码创建 OfflineImporter。
+// 从该目录向上查找所属模块的 go.mod。
+func NewOfflineImporter(fset *token.FileSet, root string) *OfflineImporter {
+	buildCtx := build.Default
+	buildCtx.CgoEnabled = false // Select pure Go files, cgo cannot run offline here. // 选择纯 Go 文件，此处不运行 cgo
+
+	imp := &OfflineImporter{
+		fset:     fset,
+		buildCtx: buildCtx,
+		modCache: getModCache(),
+		requires: map[string]string{},
+		replaces: map[string]string{},
+		packages: map[string]*types.Package{},
+	}
+	if moduleRoot, ok := findModuleRoot(root); ok {
+		imp.moduleRoot = moduleRoot
+		imp.parseGoMod(filepath.Join(moduleRoot, "go.mod"))
+	}
+	return imp
+}
+
+// Import imports a package by its import path.
+// Import 根据导入路径导入包。
+func (imp *OfflineImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+// ImportFrom imports a package by its import path, the importing directory is used to resolve GOROOT vendored packages.
+// ImportFrom 根据导入路径导入包，导入方所在目录用于解析 GOROOT 中 vendor 的包。
+func (imp *OfflineImporter) ImportFrom(path string, srcDir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	root, ok := imp.FindPackageRoot(path, srcDir)
+	if !ok {
+		return nil, erero.Errorf("cannot find package %s offline", path)
+	}
+	// Packages are cached by directory, a GOROOT vendored package and a module dependency may share the import path.
+	// 包按目录缓存，GOROOT 中 vendor 的包和依赖模块中的包可能具有相同的导入路径。
+	if pkg, ok := imp.packages[root]; ok {
+		if !pkg.Complete() {
+			return nil, erero.Errorf("import cycle through %s", path)
+		}
+		return pkg, nil
+	}
+	astFiles, err := imp.parsePackage(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	// Register an incomplete package first to detect import cycles.
+	// 先登记一个不完整的包以便检测循环导入。
+	imp.packages[root] = types.NewPackage(path, "")
+
+	config := &types.Config{
+		Importer:    imp,
+		FakeImportC: true,
+		Error:       func(err error) {}, // Keep going on errors in dependencies. // 依赖中的错误不影响继续检查
+	}
+	pkg, _ := config.Check(path, imp.fset, astFiles, nil)
+	pkg.MarkComplete()
+	imp.packages[root] = pkg
+	return pkg, nil
+}
+
+// FindPackageRoot returns the directory holding the source files of the import path.
+// FindPackageRoot 返回导入路径对应源文件所在的目录。
+func (imp *OfflineImporter) FindPackageRoot(path string, srcDir string) (string, bool) {
+	if imp.modulePath != "" && hasPathPrefix(path, imp.modulePath) {
+		return checkDirectory(filepath.Join(imp.moduleRoot, strings.TrimPrefix(path, imp.modulePath)))
+	}
+	goRoot := filepath.Join(imp.buildCtx.GOROOT, "src")
+	if isStandardPath(path) {
+		return checkDirectory(filepath.Join(goRoot, path))
+	}
+	// Standard packages import vendored packages from GOROOT/src/vendor.
+	// 标准库包从 GOROOT/src/vendor 中导入 vendor 的包。
+	if srcDir != "" && strings.HasPrefix(srcDir, goRoot) {
+		if root, ok := checkDirectory(filepath.Join(goRoot, "vendor", path)); ok {
+			return root, true
+		}
+	}
+	modulePath := imp.matchModulePath(path)
+	if modulePath == "" {
+		return "", false
+	}
+	subPath := strings.TrimPrefix(path, modulePath)
+	if replace, ok := imp.replaces[modulePath]; ok {
+		return checkDirectory(filepath.Join(replace, subPath))
+	}
+	if imp.modCache == "" {
+		return "", false
+	}
+	moduleRoot := filepath.Join(imp.modCache, escapeModulePath(modulePath)+"@"+imp.requires[modulePath])
+	return checkDirectory(filepath.Join(moduleRoot, subPath))
+}
+
+// GetPackageName returns the package name declared by the source files of the import path.
+// GetPackageName 返回导入路径对应源文件中声明的包名。
+func (imp *OfflineImporter) GetPackageName(path string) (string, bool) {
+	root, ok := imp.FindPackageRoot(path, "")
+	if !ok {
+		return "", false
+	}
+	buildPkg, err := imp.buildCtx.ImportDir(root, 0)
+	if err != nil || buildPkg.Name == "" {
+		return "", false
+	}
+	return buildPkg.Name, true
+}
+
+// GetModulePath returns the module path of the current module, false when no go.mod is found.
+// GetModulePath 返回当前模块的模块路径，找不到 go.mod 时返回 false。
+func (imp *OfflineImporter) GetModulePath() (string, bool) {
+	return imp.modulePath, imp.modulePath != ""
+}
+
+// ImportPathOf returns the import path of a directory inside the current module.
+// ImportPathOf 返回当前模块内某个目录的导入路径。
+func (imp *OfflineImporter) ImportPathOf(root string) (string, bool) {
+	if imp.modulePath == "" {
+		return "", false
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(imp.moduleRoot, absRoot)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	if rel == "." {
+		return imp.modulePath, true
+	}
+	return imp.modulePath + "/" + filepath.ToSlash(rel), true
+}
+
+func (imp *OfflineImporter) parsePackage(root string) ([]*ast.File, error) {
+	buildPkg, err := imp.buildCtx.ImportDir(root, 0)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var astFiles = make([]*ast.File, 0, len(buildPkg.GoFiles))
+	for _, name := range buildPkg.GoFiles {
+		astFile, err := parser.ParseFile(imp.fset, filepath.Join(root, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		astFiles = append(astFiles, astFile)
+	}
+	return astFiles, nil
+}
+
+func (imp *OfflineImporter) parseGoMod(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var block string
+	for _, line := range strings.Split(string(data), "\n") {
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == ")" {
+			block = ""
+			continue
+		}
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		if block == "" {
+			block, fields = fields[0], fields[1:]
+			imp.parseGoModLine(block, fields)
+			block = ""
+			continue
+		}
+		imp.parseGoModLine(block, fields)
+	}
+}
+
+func (imp *OfflineImporter) parseGoModLine(verb string, fields []string) {
+	switch verb {
+	case "module":
+		if len(fields) >= 1 {
+			imp.modulePath = strings.Trim(fields[0], `"`)
+		}
+	case "require":
+		if len(fields) >= 2 {
+			imp.requires[fields[0]] = fields[1]
+		}
+	case "replace":
+		// Support "old [version] => ./local/dir" and "old [version] => new version".
+		// 支持 "old [version] => ./local/dir" 和 "old [version] => new version" 两种形式。
+		arrow := -1
+		for idx, field := range fields {
+			if field == "=>" {
+				arrow = idx
+			}
+		}
+		if arrow < 1 || arrow+1 >= len(fields) {
+			return
+		}
+		oldPath, newPath := fields[0], fields[arrow+1]
+		if strings.HasPrefix(newPath, ".") || filepath.IsAbs(newPath) {
+			if !filepath.IsAbs(newPath) {
+				newPath = filepath.Join(imp.moduleRoot, newPath)
+			}
+			imp.replaces[oldPath] = newPath
+		} else if arrow+2 < len(fields) {
+			imp.replaces[oldPath] = filepath.Join(imp.modCache, escapeModulePath(newPath)+"@"+fields[arrow+2])
+		}
+	}
+}
+
+func (imp *OfflineImporter) matchModulePath(path string) string {
+	var result string
+	for modulePath := range imp.requires {
+		if hasPathPrefix(path, modulePath) && len(modulePath) > len(result) {
+			result = modulePath
+		}
+	}
+	for modulePath := range imp.replaces {
+		if hasPathPrefix(path, modulePath) && len(modulePath) > len(result) {
+			result = modulePath
+		}
+	}
+	return result
+}
+
+func getModCache() string {
+	if modCache := os.Getenv("GOMODCACHE"); modCache != "" {
+		return modCache
+	}
+	goPath := build.Default.GOPATH
+	if goPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.SplitList(goPath)[0], "pkg", "mod")
+}
+
+func findModuleRoot(root string) (string, bool) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if info, err := os.Stat(filepath.Join(absRoot, "go.mod")); err == nil && !info.IsDir() {
+			return absRoot, true
+		}
+		parent := filepath.Dir(absRoot)
+		if parent == absRoot {
+			return "", false
+		}
+		absRoot = parent
+	}
+}
+
+func checkDirectory(root string) (string, bool) {
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		return root, true
+	}
+	return "", false
+}
+
+// isStandardPath reports whether the import path looks like a standard package, whose first element has no dot.
+// isStandardPath 判断导入路径是否像标准库包，即第一段不包含点号。
+func isStandardPath(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// escapeModulePath escapes upper-case letters the way the module cache does, "A" becomes "!a".
+// escapeModulePath 按模块缓存的规则转义大写字母，"A" 变为 "!a"。
+func escapeModulePath(path string) string {
+	var sb strings.Builder
+	for _, c := range path {
+		if unicode.IsUpper(c) {
+			sb.WriteByte('!')
+			sb.WriteRune(unicode.ToLower(c))
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
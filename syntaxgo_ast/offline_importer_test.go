@@ -0,0 +1,92 @@
+package syntaxgo_ast
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/runpath"
+	"github.com/yyle88/syntaxgo/internal/tests"
+)
+
+// TestOfflineImporter_FindPackageRoot tests locating standard, module and dependency packages
+// Verifies each import path resolves to a directory without network access
+//
+// TestOfflineImporter_FindPackageRoot 测试定位标准库、当前模块和依赖模块中的包
+// 验证每个导入路径无需网络即可解析为目录
+func TestOfflineImporter_FindPackageRoot(t *testing.T) {
+	importer := NewOfflineImporter(token.NewFileSet(), runpath.PARENT.Path())
+
+	root, ok := importer.FindPackageRoot("go/ast", "")
+	require.True(t, ok)
+	t.Log(root)
+
+	root, ok = importer.FindPackageRoot("github.com/yyle88/syntaxgo/syntaxgo_astnode", "")
+	require.True(t, ok)
+	require.Equal(t, filepath.Join(filepath.Dir(runpath.PARENT.Path()), "syntaxgo_astnode"), root)
+
+	root, ok = importer.FindPackageRoot("github.com/yyle88/erero", "")
+	require.True(t, ok)
+	t.Log(root)
+
+	_, ok = importer.FindPackageRoot("example.com/not/required", "")
+	require.False(t, ok)
+}
+
+// TestOfflineImporter_ImportFrom_SharedPath tests importing a GOROOT vendored package and a module dependency with the same import path
+// Verifies each importer gets the package from its own directory
+//
+// TestOfflineImporter_ImportFrom_SharedPath 测试导入具有相同导入路径的 GOROOT vendor 包和依赖模块包
+// 验证每个导入方都得到其自身目录中的包
+func TestOfflineImporter_ImportFrom_SharedPath(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/demo", map[string]string{
+		// Overwrites the go.mod written by the helper, the dependency is replaced by a local directory.
+		// 覆盖辅助函数写入的 go.mod，依赖被替换为本地目录。
+		"go.mod":                     "module example.com/demo\n\ngo 1.22\n\nrequire golang.org/x/net v0.0.0\n\nreplace golang.org/x/net => ./xnet\n",
+		"xnet/http/httpguts/guts.go": "package httpguts\n\nfunc Custom() int { return 1 }\n",
+	})
+	importer := NewOfflineImporter(token.NewFileSet(), root)
+	const path = "golang.org/x/net/http/httpguts"
+
+	vendored, err := importer.ImportFrom(path, filepath.Join(importer.buildCtx.GOROOT, "src", "net", "http"), 0)
+	require.NoError(t, err)
+	require.NotNil(t, vendored.Scope().Lookup("ValidHeaderFieldName"))
+	require.Nil(t, vendored.Scope().Lookup("Custom"))
+
+	replaced, err := importer.Import(path)
+	require.NoError(t, err)
+	require.NotNil(t, replaced.Scope().Lookup("Custom"))
+	require.NotSame(t, vendored, replaced)
+}
+
+// TestOfflineImporter_ImportPathOf tests working out import paths of directories in the module
+// Verifies the module root and the package directory map to their import paths
+//
+// TestOfflineImporter_ImportPathOf 测试推断模块内目录的导入路径
+// 验证模块根目录和包目录能映射到对应的导入路径
+func TestOfflineImporter_ImportPathOf(t *testing.T) {
+	importer := NewOfflineImporter(token.NewFileSet(), runpath.PARENT.Path())
+
+	pkgPath, ok := importer.ImportPathOf(runpath.PARENT.Path())
+	require.True(t, ok)
+	require.Equal(t, "github.com/yyle88/syntaxgo/syntaxgo_ast", pkgPath)
+
+	pkgPath, ok = importer.ImportPathOf(filepath.Dir(runpath.PARENT.Path()))
+	require.True(t, ok)
+	require.Equal(t, "github.com/yyle88/syntaxgo", pkgPath)
+
+	modulePath, ok := importer.GetModulePath()
+	require.True(t, ok)
+	require.Equal(t, "github.com/yyle88/syntaxgo", modulePath)
+}
+
+// TestEscapeModulePath tests escaping upper-case letters in module paths
+// Verifies the module cache escaping rule is applied
+//
+// TestEscapeModulePath 测试转义模块路径中的大写字母
+// 验证应用了模块缓存的转义规则
+func TestEscapeModulePath(t *testing.T) {
+	require.Equal(t, "github.com/!burnt!sushi/toml", escapeModulePath("github.com/BurntSushi/toml"))
+	require.Equal(t, "github.com/yyle88/erero", escapeModulePath("github.com/yyle88/erero"))
+}
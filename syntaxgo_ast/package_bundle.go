@@ -0,0 +1,214 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+)
+
+// PackageBundleOptions controls which Go source files are loaded into a PackageBundle.
+// PackageBundleOptions 控制哪些 Go 源文件被加载到 PackageBundle 中。
+type PackageBundleOptions struct {
+	includeTests bool        // Whether to load _test.go files. // 是否加载 _test.go 文件
+	parserMode   parser.Mode // Parser mode used on each file. // 解析每个文件时使用的模式
+}
+
+// NewPackageBundleOptions creates options that skip _test.go files and keep comments.
+// NewPackageBundleOptions 创建默认选项：跳过 _test.go 文件并保留注释。
+func NewPackageBundleOptions() *PackageBundleOptions {
+	return &PackageBundleOptions{
+		includeTests: false,
+		parserMode:   parser.ParseComments,
+	}
+}
+
+// SetIncludeTests sets whether _test.go files are loaded.
+// SetIncludeTests 设置是否加载 _test.go 文件。
+func (opts *PackageBundleOptions) SetIncludeTests(includeTests bool) *PackageBundleOptions {
+	opts.includeTests = includeTests
+	return opts
+}
+
+// SetParserMode sets the parser mode used on each file.
+// SetParserMode 设置解析每个文件时使用的模式。
+func (opts *PackageBundleOptions) SetParserMode(mode parser.Mode) *PackageBundleOptions {
+	opts.parserMode = mode
+	return opts
+}
+
+// PackageBundle holds every Go source file of one package directory, parsed through one shared FileSet.
+// PackageBundle 保存同一个包目录下的所有 Go 源文件，这些文件通过同一个 FileSet 解析。
+type PackageBundle struct {
+	// fset is the file set shared by every file in the package.
+	// fset 是包内所有文件共享的文件集。
+	fset *token.FileSet
+
+	// root is the package directory.
+	// root 是包所在的目录。
+	root string
+
+	// packageName is the package name shared by every file.
+	// packageName 是所有文件共同的包名。
+	packageName string
+
+	// astBundles are the parsed files, sorted by path.
+	// astBundles 是已解析的文件，按路径排序。
+	astBundles []*AstBundle
+
+	// typesBundle is the go/types result, set when the package is type-checked.
+	// typesBundle 是 go/types 的结果，在类型检查后设置。
+	typesBundle *TypesBundle
+
+	// externalTests holds the external "<name>_test" package files, nil when there are none.
+	// externalTests 保存外部 "<name>_test" 包的文件，没有时为 nil。
+	externalTests *PackageBundle
+}
+
+// NewPackageBundle parses the Go source files in the directory with the provided FileSet and options.
+// With tests included, _test.go files of the external "<name>_test" package are kept in a separate bundle.
+// Returns an error when the directory has no Go source files or when the files declare different packages.
+//
+// NewPackageBundle 使用给定的 FileSet 和选项解析目录中的 Go 源文件。
+// 包含测试文件时，外部 "<name>_test" 包的 _test.go 文件保存在单独的 bundle 中。
+// 当目录中没有 Go 源文件，或者文件声明的包名不一致时返回错误。
+func NewPackageBundle(fset *token.FileSet, root string, options *PackageBundleOptions) (*PackageBundle, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if !utils.IsGoSourceFile(info) {
+			continue
+		}
+		if !options.includeTests && strings.HasSuffix(info.Name(), "_test.go") {
+			continue
+		}
+		paths = append(paths, filepath.Join(root, info.Name()))
+	}
+	if len(paths) == 0 {
+		return nil, erero.Errorf("no go source files in %s", root)
+	}
+	slices.Sort(paths) // Sort paths to keep file order stable. // 排序路径以保持文件顺序稳定。
+
+	var astBundles = make([]*AstBundle, 0, len(paths))
+	for _, path := range paths {
+		astBundle, err := NewAstBundleV5(fset, path, options.parserMode)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		astBundles = append(astBundles, astBundle)
+	}
+
+	// The package name comes from the first file outside the external test package.
+	// 包名取自第一个不属于外部测试包的文件。
+	var packageName = astBundles[0].GetPackageName()
+	for _, astBundle := range astBundles {
+		if !isExternalTestFile(astBundle) {
+			packageName = astBundle.GetPackageName()
+			break
+		}
+	}
+
+	var packageBundles, externalBundles []*AstBundle
+	for _, astBundle := range astBundles {
+		switch name := astBundle.GetPackageName(); {
+		case name == packageName:
+			packageBundles = append(packageBundles, astBundle)
+		case name == packageName+"_test" && isExternalTestFile(astBundle):
+			externalBundles = append(externalBundles, astBundle)
+		default:
+			return nil, erero.Errorf("mixed package clauses %s and %s in %s", packageName, name, astBundle.GetPath())
+		}
+	}
+	pkgBundle := &PackageBundle{
+		fset:        fset,
+		root:        root,
+		packageName: packageName,
+		astBundles:  packageBundles,
+	}
+	if len(externalBundles) > 0 {
+		pkgBundle.externalTests = &PackageBundle{
+			fset:        fset,
+			root:        root,
+			packageName: packageName + "_test",
+			astBundles:  externalBundles,
+		}
+	}
+	return pkgBundle, nil
+}
+
+// isExternalTestFile reports whether the file is a _test.go file of an external "<name>_test" package.
+// isExternalTestFile 判断文件是否为外部 "<name>_test" 包的 _test.go 文件。
+func isExternalTestFile(astBundle *AstBundle) bool {
+	return strings.HasSuffix(astBundle.GetPath(), "_test.go") && strings.HasSuffix(astBundle.GetPackageName(), "_test")
+}
+
+// NewPackageBundleV1 parses the non-test Go source files in the directory using a new FileSet.
+// NewPackageBundleV1 使用新的 FileSet 解析目录中的非测试 Go 源文件。
+func NewPackageBundleV1(root string) (*PackageBundle, error) {
+	return NewPackageBundle(token.NewFileSet(), root, NewPackageBundleOptions())
+}
+
+// NewPackageBundleV2 parses the Go source files in the directory using a new FileSet and the provided options.
+// NewPackageBundleV2 使用新的 FileSet 和给定选项解析目录中的 Go 源文件。
+func NewPackageBundleV2(root string, options *PackageBundleOptions) (*PackageBundle, error) {
+	return NewPackageBundle(token.NewFileSet(), root, options)
+}
+
+// GetFileSet returns the FileSet shared by every file in the package.
+// GetFileSet 返回包内所有文件共享的 FileSet。
+func (pb *PackageBundle) GetFileSet() *token.FileSet {
+	return pb.fset
+}
+
+// GetRoot returns the package directory.
+// GetRoot 返回包所在的目录。
+func (pb *PackageBundle) GetRoot() string {
+	return pb.root
+}
+
+// GetPackageName returns the package name shared by every file.
+// GetPackageName 返回所有文件共同的包名。
+func (pb *PackageBundle) GetPackageName() string {
+	return pb.packageName
+}
+
+// GetExternalTestBundle returns the bundle of the external "<name>_test" package files, nil when there are none.
+// GetExternalTestBundle 返回外部 "<name>_test" 包文件的 bundle，没有时返回 nil。
+func (pb *PackageBundle) GetExternalTestBundle() *PackageBundle {
+	return pb.externalTests
+}
+
+// GetAstBundles returns the AstBundle of each file, sorted by path.
+// GetAstBundles 返回每个文件的 AstBundle，按路径排序。
+func (pb *PackageBundle) GetAstBundles() []*AstBundle {
+	return pb.astBundles
+}
+
+// GetAstFiles returns the AST of each file, sorted by path.
+// GetAstFiles 返回每个文件的 AST，按路径排序。
+func (pb *PackageBundle) GetAstFiles() []*ast.File {
+	var astFiles = make([]*ast.File, 0, len(pb.astBundles))
+	for _, astBundle := range pb.astBundles {
+		astFiles = append(astFiles, astBundle.file)
+	}
+	return astFiles
+}
+
+// GetFilePath returns the path of the file that contains the given AST file.
+// GetFilePath 返回包含给定 AST 文件的文件路径。
+func (pb *PackageBundle) GetFilePath(astFile *ast.File) string {
+	return pb.fset.Position(astFile.Package).Filename
+}
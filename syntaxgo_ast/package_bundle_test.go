@@ -0,0 +1,93 @@
+package syntaxgo_ast
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/runpath"
+)
+
+// TestNewPackageBundleV1 tests loading the non-test files of the current package
+// Verifies each file shares one FileSet and no _test.go file is loaded
+//
+// TestNewPackageBundleV1 测试加载当前包的非测试文件
+// 验证所有文件共享同一个 FileSet 且不加载 _test.go 文件
+func TestNewPackageBundleV1(t *testing.T) {
+	pkgBundle := rese.P1(NewPackageBundleV1(runpath.PARENT.Path()))
+	require.Equal(t, "syntaxgo_ast", pkgBundle.GetPackageName())
+
+	astFiles := pkgBundle.GetAstFiles()
+	require.NotEmpty(t, astFiles)
+	for _, astFile := range astFiles {
+		path := pkgBundle.GetFilePath(astFile)
+		t.Log(path)
+		require.False(t, strings.HasSuffix(path, "_test.go"))
+		require.NotNil(t, pkgBundle.GetFileSet().File(astFile.Pos()))
+	}
+}
+
+// TestNewPackageBundleV2 tests loading the current package with test files included
+// Verifies the current test file is part of the loaded files
+//
+// TestNewPackageBundleV2 测试加载包含测试文件的当前包
+// 验证当前测试文件在已加载的文件中
+func TestNewPackageBundleV2(t *testing.T) {
+	pkgBundle := rese.P1(NewPackageBundleV2(runpath.PARENT.Path(), NewPackageBundleOptions().SetIncludeTests(true)))
+
+	var paths []string
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		paths = append(paths, pkgBundle.GetFilePath(astFile))
+	}
+	t.Log(paths)
+	require.Contains(t, paths, runpath.CurrentPath())
+}
+
+// TestNewPackageBundle_MixedPackages tests rejecting a directory with different package clauses
+// Verifies an error is returned instead of a partial bundle
+//
+// TestNewPackageBundle_MixedPackages 测试拒绝包含不同包声明的目录
+// 验证返回错误而不是部分加载的结果
+func TestNewPackageBundle_MixedPackages(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b.go"), []byte("package b\n"), 0644))
+
+	_, err := NewPackageBundleV1(root)
+	require.Error(t, err)
+	t.Log(err)
+}
+
+// TestNewPackageBundle_ExternalTests tests loading a package with black-box tests in the "<name>_test" package
+// Verifies the external test files are kept in a separate bundle sharing the FileSet
+//
+// TestNewPackageBundle_ExternalTests 测试加载带有 "<name>_test" 黑盒测试的包
+// 验证外部测试文件保存在共享 FileSet 的单独 bundle 中
+func TestNewPackageBundle_ExternalTests(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a_test.go"), []byte("package a_test\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.go"), []byte("package a\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "b_test.go"), []byte("package a\n"), 0644))
+
+	pkgBundle := rese.P1(NewPackageBundleV1(root))
+	require.Equal(t, "a", pkgBundle.GetPackageName())
+	require.Len(t, pkgBundle.GetAstFiles(), 1)
+	require.Nil(t, pkgBundle.GetExternalTestBundle())
+
+	pkgBundle = rese.P1(NewPackageBundleV2(root, NewPackageBundleOptions().SetIncludeTests(true)))
+	require.Equal(t, "a", pkgBundle.GetPackageName())
+	require.Len(t, pkgBundle.GetAstFiles(), 2)
+
+	externalTests := pkgBundle.GetExternalTestBundle()
+	require.NotNil(t, externalTests)
+	require.Equal(t, "a_test", externalTests.GetPackageName())
+	require.Len(t, externalTests.GetAstFiles(), 1)
+	require.Same(t, pkgBundle.GetFileSet(), externalTests.GetFileSet())
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "c.go"), []byte("package a_test\n"), 0644))
+	_, err := NewPackageBundleV1(root)
+	require.Error(t, err)
+}
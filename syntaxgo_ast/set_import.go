@@ -47,6 +47,7 @@ type PackageImportOptions struct {
 	pkgPaths        []string       // List of package paths. // 直接设置包路径列表
 	referencedTypes []reflect.Type // List of referenced types to find package paths. // 设置反射类型，通过类型能找到包路径
 	inferredObjects []any          // List of inferred objects to find package paths. // 设置要引用的对象列表(非指针对象)，通过对象也能找到对象的包路径
+	namedPkgPaths   []*ImportPath  // List of package paths with aliases. // 设置带别名的包路径列表
 }
 
 // NewPackageImportOptions creates and returns a new PackageImportOptions instance.
@@ -69,6 +70,13 @@ func (param *PackageImportOptions) SetPkgPaths(pkgPaths []string) *PackageImport
 	return param
 }
 
+// SetNamedPkgPath adds a package path with an alias (a name, "_" or "."), used by InjectImportsV2.
+// SetNamedPkgPath 添加带别名（名称、"_" 或 "."）的包路径，由 InjectImportsV2 使用。
+func (param *PackageImportOptions) SetNamedPkgPath(alias string, pkgPath string) *PackageImportOptions {
+	param.namedPkgPaths = append(param.namedPkgPaths, NewImportPath(alias, pkgPath))
+	return param
+}
+
 // SetReferencedType adds a referenced type to the list of referenced types.
 // SetReferencedType 将一个引用类型添加到引用类型列表中。
 func (param *PackageImportOptions) SetReferencedType(reflectType reflect.Type) *PackageImportOptions {
@@ -99,6 +107,21 @@ func (param *PackageImportOptions) InjectImports(source []byte) []byte {
 	return InjectImports(source, param.GetPkgPaths())
 }
 
+// GetImportPaths returns the package paths without alias followed by the package paths with aliases.
+// GetImportPaths 返回不带别名的包路径，以及其后的带别名包路径。
+func (param *PackageImportOptions) GetImportPaths() []*ImportPath {
+	return utils.SafeMerge(
+		NewImportPaths(param.GetPkgPaths()),
+		param.namedPkgPaths,
+	)
+}
+
+// InjectImportsV2 merges the import paths, aliases included, into the import declaration of the source.
+// InjectImportsV2 将包括别名在内的导入路径合并到源代码的导入声明中。
+func (param *PackageImportOptions) InjectImportsV2(source []byte, modulePath string) ([]byte, error) {
+	return InjectImportsV2(source, param.GetImportPaths(), modulePath)
+}
+
 // CreateImports generates a string containing import statements for the given package paths.
 // CreateImports 根据给定的包路径生成包含导入语句的字符串。
 func (param *PackageImportOptions) CreateImports() string {
@@ -191,7 +214,7 @@ func InjectImports(source []byte, packages []string) []byte {
 
 		// Find the position to insert the new import statements.
 		// 找到插入新导入语句的位置。
-		posIdx := int(astFile.Name.End() - 1)
+		posIdx := syntaxgo_astnode.GetOffset(astBundle.fset, astFile.Name.End())
 		for posIdx < len(source) && source[posIdx] != ('\n') {
 			posIdx++
 		}
@@ -0,0 +1,336 @@
+package syntaxgo_ast
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+)
+
+// DiffOptions controls the unified diff output.
+// DiffOptions 控制 unified diff 的输出。
+type DiffOptions struct {
+	contextLines int    // Unchanged lines shown around each change. // 每处修改前后显示的未修改行数
+	oldLabel     string // Label of the original source on the "---" line. // "---" 行上原始源代码的标签
+	newLabel     string // Label of the rewritten source on the "+++" line. // "+++" 行上改写后源代码的标签
+}
+
+// NewDiffOptions creates options with 3 context lines and the labels "a" and "b".
+// NewDiffOptions 创建默认选项：3 行上下文，标签为 "a" 和 "b"。
+func NewDiffOptions() *DiffOptions {
+	return &DiffOptions{
+		contextLines: 3,
+		oldLabel:     "a",
+		newLabel:     "b",
+	}
+}
+
+// SetContextLines sets the count of unchanged lines shown around each change.
+// SetContextLines 设置每处修改前后显示的未修改行数。
+func (opts *DiffOptions) SetContextLines(contextLines int) *DiffOptions {
+	opts.contextLines = max(contextLines, 0)
+	return opts
+}
+
+// SetLabels sets the labels of the original and rewritten source, such as "a/main.go" and "b/main.go".
+// SetLabels 设置原始源代码和改写后源代码的标签，比如 "a/main.go" 和 "b/main.go"。
+func (opts *DiffOptions) SetLabels(oldLabel string, newLabel string) *DiffOptions {
+	opts.oldLabel = oldLabel
+	opts.newLabel = newLabel
+	return opts
+}
+
+// UnifiedDiff returns the unified diff from the original source to the rewritten source, empty when they are equal.
+// The output can be applied with `patch` or `git apply`.
+//
+// UnifiedDiff 返回从原始源代码到改写后源代码的 unified diff，两者相同时返回空。
+// 输出可以通过 `patch` 或 `git apply` 应用。
+func UnifiedDiff(oldSource []byte, newSource []byte, options *DiffOptions) []byte {
+	if bytes.Equal(oldSource, newSource) {
+		return nil
+	}
+	oldLines := splitLines(oldSource)
+	newLines := splitLines(newSource)
+	diffOps := diffLines(oldLines, newLines)
+
+	ptx := utils.NewPTX()
+	ptx.Println("--- " + options.oldLabel)
+	ptx.Println("+++ " + options.newLabel)
+	for _, hunk := range groupHunks(diffOps, options.contextLines) {
+		ptx.Println(hunk.header())
+		for _, op := range hunk.ops {
+			ptx.Print(string(op.kind) + op.line)
+			if len(op.line) == 0 || op.line[len(op.line)-1] != '\n' {
+				ptx.Println()
+				ptx.Println(`\ No newline at end of file`)
+			}
+		}
+	}
+	return []byte(ptx.String())
+}
+
+// Diff returns the unified diff from the loaded source to the formatted AST, empty when nothing changed.
+// A non-empty result means the file is out of date, which is what a CI check of generated code needs.
+//
+// Diff 返回从加载时的源代码到格式化后 AST 的 unified diff，没有变化时返回空。
+// 非空结果表示文件已过期，可用于 CI 中检查生成的代码是否最新。
+func (ab *AstBundle) Diff() ([]byte, error) {
+	var options = NewDiffOptions()
+	if ab.path != "" {
+		options.SetLabels(ab.path, ab.path)
+	}
+	return ab.DiffV2(options)
+}
+
+// DiffV2 returns the unified diff from the loaded source to the formatted AST with options.
+// DiffV2 使用选项返回从加载时的源代码到格式化后 AST 的 unified diff。
+func (ab *AstBundle) DiffV2(options *DiffOptions) ([]byte, error) {
+	if ab.source == nil {
+		return nil, erero.New("bundle has no loaded source to diff against")
+	}
+	newSource, err := ab.FormatSource()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return UnifiedDiff(ab.source, newSource, options), nil
+}
+
+// diffOp is one line of the edit script, kind is ' ' for equal, '-' for delete and '+' for insert.
+// diffOp 是编辑脚本中的一行，kind 为 ' ' 表示相同，'-' 表示删除，'+' 表示插入。
+type diffOp struct {
+	kind    byte   // Operation kind / 操作类型
+	line    string // Line text with its line break / 带换行符的行文本
+	oldLine int    // Index of the line in the original source / 在原始源代码中的行索引
+	newLine int    // Index of the line in the rewritten source / 在改写后源代码中的行索引
+}
+
+// splitLines splits the source into lines keeping the line breaks, the last line may lack one.
+// splitLines 将源代码按行拆分并保留换行符，最后一行可能没有换行符。
+func splitLines(source []byte) []string {
+	var lines []string
+	for len(source) > 0 {
+		idx := bytes.IndexByte(source, '\n')
+		if idx < 0 {
+			lines = append(lines, string(source))
+			break
+		}
+		lines = append(lines, string(source[:idx+1]))
+		source = source[idx+1:]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script between the lines with the linear-space Myers algorithm.
+// diffLines 使用线性空间的 Myers 算法计算两组行之间的最短编辑脚本。
+func diffLines(a []string, b []string) []*diffOp {
+	size := len(a) + len(b) + 3
+	differ := &lineDiffer{
+		a:       a,
+		b:       b,
+		forward: make([]int, 2*size),
+		reverse: make([]int, 2*size),
+		offset:  size,
+	}
+	differ.compare(0, len(a), 0, len(b))
+	return sortChangeRuns(differ.diffOps)
+}
+
+// sortChangeRuns puts the deletes before the inserts in each run of changes, like diff tools show them.
+// sortChangeRuns 将每段连续修改中的删除放在插入之前，与 diff 工具的显示方式一致。
+func sortChangeRuns(diffOps []*diffOp) []*diffOp {
+	for start := 0; start < len(diffOps); {
+		if diffOps[start].kind == ' ' {
+			start++
+			continue
+		}
+		end := start
+		for end < len(diffOps) && diffOps[end].kind != ' ' {
+			end++
+		}
+		x, y := diffOps[start].oldLine, diffOps[start].newLine
+		var deletes, inserts []*diffOp
+		for _, op := range diffOps[start:end] {
+			if op.kind == '-' {
+				deletes = append(deletes, op)
+			} else {
+				inserts = append(inserts, op)
+			}
+		}
+		for index, op := range deletes {
+			op.oldLine, op.newLine = x+index, y
+		}
+		for index, op := range inserts {
+			op.oldLine, op.newLine = x+len(deletes), y+index
+		}
+		copy(diffOps[start:end], append(deletes, inserts...))
+		start = end
+	}
+	return diffOps
+}
+
+// lineDiffer holds the state of a diff, the diagonal arrays are shared by each middle snake search.
+// lineDiffer 保存一次比较的状态，对角线数组由每次中间蛇查找共享。
+type lineDiffer struct {
+	a, b    []string  // Old and new lines / 旧行和新行
+	forward []int     // Furthest x on each diagonal searching forwards / 正向查找时每条对角线上最远的 x
+	reverse []int     // Furthest x on each diagonal searching backwards / 反向查找时每条对角线上最远的 x
+	offset  int       // Index of diagonal 0 in the arrays / 对角线 0 在数组中的下标
+	diffOps []*diffOp // Operations in order / 按顺序排列的操作
+}
+
+// compare appends the operations turning a[aLo:aHi] into b[bLo:bHi].
+// compare 追加将 a[aLo:aHi] 变为 b[bLo:bHi] 的操作。
+func (differ *lineDiffer) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && differ.a[aLo] == differ.b[bLo] {
+		differ.appendOp(' ', aLo, bLo)
+		aLo++
+		bLo++
+	}
+	var suffix int
+	for aLo < aHi-suffix && bLo < bHi-suffix && differ.a[aHi-suffix-1] == differ.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for ; bLo < bHi; bLo++ {
+			differ.appendOp('+', aLo, bLo)
+		}
+	case bLo == bHi:
+		for ; aLo < aHi; aLo++ {
+			differ.appendOp('-', aLo, bLo)
+		}
+	default:
+		x, y, u, v := differ.findMiddleSnake(aLo, aHi, bLo, bHi)
+		differ.compare(aLo, x, bLo, y)
+		for ; x < u; x, y = x+1, y+1 {
+			differ.appendOp(' ', x, y)
+		}
+		differ.compare(u, aHi, v, bHi)
+	}
+
+	for index := 0; index < suffix; index++ {
+		differ.appendOp(' ', aHi+index, bHi+index)
+	}
+}
+
+// findMiddleSnake finds the snake (x, y) to (u, v) in the middle of a shortest edit script of the ranges.
+// findMiddleSnake 查找两个区间最短编辑脚本中间位置的蛇 (x, y) 到 (u, v)。
+func (differ *lineDiffer) findMiddleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	a, b := differ.a, differ.b
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	forward, reverse, offset := differ.forward, differ.reverse, differ.offset
+	forward[offset+1], reverse[offset+1] = 0, 0
+	for d := 0; d <= (n+m+1)/2; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[aLo+x] == b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			if c := delta - k; odd && c >= -(d-1) && c <= d-1 && x+reverse[offset+c] >= n {
+				return aLo + x0, bLo + y0, aLo + x, bLo + y
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && reverse[offset+k-1] < reverse[offset+k+1]) {
+				x = reverse[offset+k+1]
+			} else {
+				x = reverse[offset+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[aHi-1-x] == b[bHi-1-y] {
+				x++
+				y++
+			}
+			reverse[offset+k] = x
+			if c := delta - k; !odd && c >= -d && c <= d && x+forward[offset+c] >= n {
+				return aHi - x, bHi - y, aHi - x0, bHi - y0
+			}
+		}
+	}
+	panic("unreachable: no middle snake") // The paths always meet within (n+m+1)/2 steps. // 两条路径总会在 (n+m+1)/2 步内相遇。
+}
+
+// appendOp appends the operation at the old line index x and the new line index y.
+// appendOp 在旧行下标 x 和新行下标 y 处追加操作。
+func (differ *lineDiffer) appendOp(kind byte, x int, y int) {
+	var line string
+	if kind == '+' {
+		line = differ.b[y]
+	} else {
+		line = differ.a[x]
+	}
+	differ.diffOps = append(differ.diffOps, &diffOp{kind: kind, line: line, oldLine: x, newLine: y})
+}
+
+// diffHunk is a run of operations shown under one "@@" header.
+// diffHunk 是显示在同一个 "@@" 头下的一段操作。
+type diffHunk struct {
+	ops []*diffOp
+}
+
+// header returns the "@@ -l,s +l,s @@" line, the count is omitted when it is 1 like GNU diff does.
+// header 返回 "@@ -l,s +l,s @@" 行，与 GNU diff 一致，数量为 1 时省略。
+func (hunk *diffHunk) header() string {
+	var oldCount, newCount int
+	for _, op := range hunk.ops {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+	first := hunk.ops[0]
+	return fmt.Sprintf("@@ -%s +%s @@", formatRange(first.oldLine, oldCount), formatRange(first.newLine, newCount))
+}
+
+func formatRange(start int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start) // An empty range names the line before it. // 空区间使用其前一行的行号。
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+// groupHunks groups the changes with their context lines, changes closer than twice the context share one hunk.
+// groupHunks 将修改与其上下文行分组，间隔小于两倍上下文的修改共用一个 hunk。
+func groupHunks(diffOps []*diffOp, contextLines int) []*diffHunk {
+	var hunks []*diffHunk
+	var sdx, edx = -1, -1
+	for idx, op := range diffOps {
+		if op.kind == ' ' {
+			continue
+		}
+		if sdx >= 0 && idx-edx > 2*contextLines {
+			hunks = append(hunks, &diffHunk{ops: diffOps[sdx:min(edx+contextLines, len(diffOps))]})
+			sdx = -1
+		}
+		if sdx < 0 {
+			sdx = max(idx-contextLines, 0)
+		}
+		edx = idx + 1
+	}
+	if sdx >= 0 {
+		hunks = append(hunks, &diffHunk{ops: diffOps[sdx:min(edx+contextLines, len(diffOps))]})
+	}
+	return hunks
+}
@@ -0,0 +1,169 @@
+package syntaxgo_ast
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnifiedDiff tests the unified diff between two sources with one context line
+// Verifies distant changes get separate hunks and a missing final newline is marked
+//
+// TestUnifiedDiff 测试两段源代码之间带一行上下文的 unified diff
+// 验证相距较远的修改分属不同的 hunk，缺少末尾换行符时会被标记
+func TestUnifiedDiff(t *testing.T) {
+	const oldCode = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("a")
+	fmt.Println("b")
+	fmt.Println("c")
+	fmt.Println("d")
+	fmt.Println("e")
+}
+`
+	const newCode = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("A")
+	fmt.Println("b")
+	fmt.Println("c")
+	fmt.Println("d")
+}`
+	diff := UnifiedDiff([]byte(oldCode), []byte(newCode), NewDiffOptions().SetContextLines(1).SetLabels("old.go", "new.go"))
+	t.Log(string(diff))
+
+	const expected = `--- old.go
++++ new.go
+@@ -5,3 +5,3 @@
+ func main() {
+-	fmt.Println("a")
++	fmt.Println("A")
+ 	fmt.Println("b")
+@@ -9,3 +9,2 @@
+ 	fmt.Println("d")
+-	fmt.Println("e")
+-}
++}
+\ No newline at end of file
+`
+	require.Equal(t, expected, string(diff))
+	require.Empty(t, UnifiedDiff([]byte(oldCode), []byte(oldCode), NewDiffOptions()))
+}
+
+// TestAstBundle_Diff tests the diff between the loaded file and the changed AST
+// Verifies the file path is used in the labels and an unchanged bundle has no diff
+//
+// TestAstBundle_Diff 测试加载的文件与修改后 AST 之间的差异
+// 验证标签中使用文件路径，未修改的 bundle 没有差异
+func TestAstBundle_Diff(t *testing.T) {
+	const code = `package example
+
+func Hello() string { return "hello" }
+`
+	path := filepath.Join(t.TempDir(), "example.go")
+	require.NoError(t, os.WriteFile(path, []byte(code), 0644))
+
+	astBundle, err := NewAstBundleV4(path)
+	require.NoError(t, err)
+	require.Equal(t, code, string(astBundle.GetSource()))
+
+	diff, err := astBundle.Diff()
+	require.NoError(t, err)
+	require.Empty(t, diff)
+
+	require.True(t, astBundle.AddImport("fmt"))
+	diff, err = astBundle.Diff()
+	require.NoError(t, err)
+	t.Log(string(diff))
+
+	expected := "--- " + path + "\n" +
+		"+++ " + path + "\n" +
+		"@@ -1,3 +1,5 @@\n" +
+		" package example\n" +
+		" \n" +
+		"+import \"fmt\"\n" +
+		"+\n" +
+		" func Hello() string { return \"hello\" }\n"
+	require.Equal(t, expected, string(diff))
+}
+
+// TestDiffLines tests the edit scripts of random line lists against the longest common subsequence
+// Verifies the script rebuilds both lists and has the shortest length
+//
+// TestDiffLines 使用最长公共子序列检验随机行列表的编辑脚本
+// 验证脚本能还原两个列表且长度最短
+func TestDiffLines(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, random.Intn(12))
+		for index := range lines {
+			lines[index] = string(rune('a' + random.Intn(4)))
+		}
+		return lines
+	}
+	for round := 0; round < 500; round++ {
+		a, b := randomLines(), randomLines()
+		var oldLines, newLines []string
+		var changes int
+		for _, op := range diffLines(a, b) {
+			if op.kind != '+' {
+				require.Equal(t, a[op.oldLine], op.line)
+				oldLines = append(oldLines, op.line)
+			}
+			if op.kind != '-' {
+				require.Equal(t, b[op.newLine], op.line)
+				newLines = append(newLines, op.line)
+			}
+			if op.kind != ' ' {
+				changes++
+			}
+		}
+		require.Equal(t, len(a), len(oldLines))
+		require.Equal(t, len(b), len(newLines))
+
+		lengths := make([][]int, len(a)+1)
+		for i := range lengths {
+			lengths[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					lengths[i][j] = lengths[i+1][j+1] + 1
+				} else {
+					lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+				}
+			}
+		}
+		require.Equal(t, len(a)+len(b)-2*lengths[0][0], changes, "%v %v", a, b)
+	}
+}
+
+// TestUnifiedDiff_Rewrite tests the diff of a rewrite of every line in a large source
+// Verifies the memory stays linear in the size of the source
+//
+// TestUnifiedDiff_Rewrite 测试大文件中每一行都被改写时的 diff
+// 验证内存占用与源代码大小呈线性关系
+func TestUnifiedDiff_Rewrite(t *testing.T) {
+	var oldSource, newSource []byte
+	for index := 0; index < 4000; index++ {
+		oldSource = fmt.Appendf(oldSource, "old line %d\n", index)
+		newSource = fmt.Appendf(newSource, "new line %d\n", index)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	diff := UnifiedDiff(oldSource, newSource, NewDiffOptions())
+	runtime.ReadMemStats(&after)
+	require.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(64<<20))
+	require.Equal(t, 2+1+8000, bytes.Count(diff, []byte("\n")))
+}
@@ -0,0 +1,165 @@
+package syntaxgo_ast
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+
+	"github.com/yyle88/erero"
+)
+
+// TypesBundle holds the go/types result of a type-checked AstBundle or PackageBundle.
+// TypesBundle 保存已类型检查的 AstBundle 或 PackageBundle 的 go/types 结果。
+type TypesBundle struct {
+	// pkg is the checked package.
+	// pkg 是已检查的包。
+	pkg *types.Package
+
+	// info records the types and objects of the AST nodes.
+	// info 记录 AST 节点对应的类型和对象。
+	info *types.Info
+}
+
+// GetPackage returns the checked package.
+// GetPackage 返回已检查的包。
+func (tb *TypesBundle) GetPackage() *types.Package {
+	return tb.pkg
+}
+
+// GetInfo returns the types info recorded while checking.
+// GetInfo 返回检查过程中记录的类型信息。
+func (tb *TypesBundle) GetInfo() *types.Info {
+	return tb.info
+}
+
+// TypeOf returns the type of the expression, or nil when not recorded.
+// TypeOf 返回表达式的类型，没有记录时返回 nil。
+func (tb *TypesBundle) TypeOf(expr ast.Expr) types.Type {
+	return tb.info.TypeOf(expr)
+}
+
+// ObjectOf returns the object defined or used by the identifier, or nil when not recorded.
+// ObjectOf 返回标识符定义或使用的对象，没有记录时返回 nil。
+func (tb *TypesBundle) ObjectOf(ident *ast.Ident) types.Object {
+	return tb.info.ObjectOf(ident)
+}
+
+// LookupType returns the type of the package-level name, or nil when the name is not declared.
+// LookupType 返回包级别名称对应的类型，名称未声明时返回 nil。
+func (tb *TypesBundle) LookupType(name string) types.Type {
+	if object := tb.pkg.Scope().Lookup(name); object != nil {
+		return object.Type()
+	}
+	return nil
+}
+
+// NewTypesInfo creates a types.Info with every map allocated.
+// NewTypesInfo 创建一个所有映射都已分配的 types.Info。
+func NewTypesInfo() *types.Info {
+	return &types.Info{
+		Types:        map[ast.Expr]types.TypeAndValue{},
+		Instances:    map[*ast.Ident]types.Instance{},
+		Defs:         map[*ast.Ident]types.Object{},
+		Uses:         map[*ast.Ident]types.Object{},
+		Implicits:    map[ast.Node]types.Object{},
+		Selections:   map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:       map[ast.Node]*types.Scope{},
+		FileVersions: map[*ast.File]string{},
+	}
+}
+
+// CheckTypes runs go/types on the files with the importer, checking them as the package with the given import path.
+// Returns every type error joined into one error.
+//
+// CheckTypes 使用给定的导入器对文件运行 go/types，将其作为给定导入路径的包进行检查。
+// 返回合并后的所有类型错误。
+func CheckTypes(fset *token.FileSet, astFiles []*ast.File, pkgPath string, importer types.Importer) (*TypesBundle, error) {
+	if len(astFiles) == 0 {
+		return nil, erero.New("no files to check")
+	}
+	var errs []error
+	config := &types.Config{
+		Importer:    importer,
+		FakeImportC: true,
+		Error: func(err error) {
+			errs = append(errs, err)
+		},
+	}
+	info := NewTypesInfo()
+	pkg, _ := config.Check(pkgPath, fset, astFiles, info)
+	if len(errs) > 0 {
+		return nil, erero.Joins(errs)
+	}
+	return &TypesBundle{pkg: pkg, info: info}, nil
+}
+
+// TypeCheck type-checks the file with an OfflineImporter and keeps the result on the bundle.
+// The file is checked alone, so it must not depend on declarations in sibling files, use PackageBundle.TypeCheck in that case.
+//
+// TypeCheck 使用 OfflineImporter 对文件进行类型检查，并将结果保存在 bundle 中。
+// 该文件会被单独检查，因此不能依赖同目录其他文件中的声明，这种情况请使用 PackageBundle.TypeCheck。
+func (ab *AstBundle) TypeCheck() (*TypesBundle, error) {
+	root, err := ab.getRoot()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	importer := NewOfflineImporter(ab.fset, root)
+	pkgPath := ab.GetPackageName()
+	if ab.fset.Position(ab.file.Package).Filename != "" {
+		if importPath, ok := importer.ImportPathOf(root); ok {
+			pkgPath = importPath
+		}
+	}
+	typesBundle, err := CheckTypes(ab.fset, []*ast.File{ab.file}, pkgPath, importer)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	ab.typesBundle = typesBundle
+	return typesBundle, nil
+}
+
+// getRoot returns the directory of the file, code parsed from bytes uses the working directory.
+// getRoot 返回文件所在目录，从字节解析的代码使用工作目录。
+func (ab *AstBundle) getRoot() (string, error) {
+	if path := ab.fset.Position(ab.file.Package).Filename; path != "" {
+		return filepath.Dir(path), nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return wd, nil
+}
+
+// GetTypesBundle returns the result of TypeCheck, or nil when the bundle is not type-checked.
+// GetTypesBundle 返回 TypeCheck 的结果，未进行类型检查时返回 nil。
+func (ab *AstBundle) GetTypesBundle() *TypesBundle {
+	return ab.typesBundle
+}
+
+// TypeCheck type-checks the package with an OfflineImporter and keeps the result on the bundle.
+// TypeCheck 使用 OfflineImporter 对包进行类型检查，并将结果保存在 bundle 中。
+func (pb *PackageBundle) TypeCheck() (*TypesBundle, error) {
+	importer := NewOfflineImporter(pb.fset, pb.root)
+	pkgPath, ok := importer.ImportPathOf(pb.root)
+	if !ok {
+		pkgPath = pb.packageName
+	}
+	typesBundle, err := CheckTypes(pb.fset, pb.GetAstFiles(), pkgPath, importer)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	pb.typesBundle = typesBundle
+	for _, astBundle := range pb.astBundles {
+		astBundle.typesBundle = typesBundle
+	}
+	return typesBundle, nil
+}
+
+// GetTypesBundle returns the result of TypeCheck, or nil when the package is not type-checked.
+// GetTypesBundle 返回 TypeCheck 的结果，未进行类型检查时返回 nil。
+func (pb *PackageBundle) GetTypesBundle() *TypesBundle {
+	return pb.typesBundle
+}
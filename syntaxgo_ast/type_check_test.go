@@ -0,0 +1,80 @@
+package syntaxgo_ast
+
+import (
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/runpath"
+)
+
+// TestAstBundle_TypeCheck tests type-checking a single file that imports standard packages
+// Verifies resolved types are recorded for declarations in the file
+//
+// TestAstBundle_TypeCheck 测试对导入标准库包的单个文件进行类型检查
+// 验证文件中声明的类型被解析并记录
+func TestAstBundle_TypeCheck(t *testing.T) {
+	const code = `package example
+
+import (
+	"strings"
+	"time"
+)
+
+type User struct {
+	Name    string
+	Created time.Time
+}
+
+func Upper(user *User) string {
+	return strings.ToUpper(user.Name)
+}
+`
+	astBundle := rese.P1(NewAstBundleV1([]byte(code)))
+	require.Nil(t, astBundle.GetTypesBundle())
+
+	typesBundle, err := astBundle.TypeCheck()
+	require.NoError(t, err)
+	require.Same(t, typesBundle, astBundle.GetTypesBundle())
+
+	userType := typesBundle.LookupType("User")
+	require.NotNil(t, userType)
+	structType, ok := userType.Underlying().(*types.Struct)
+	require.True(t, ok)
+	require.Equal(t, "time.Time", structType.Field(1).Type().String())
+
+	upperType := typesBundle.LookupType("Upper")
+	require.Equal(t, "func(user *example.User) string", upperType.String())
+}
+
+// TestAstBundle_TypeCheck_Error tests type-checking a file with a type error
+// Verifies the error is returned instead of a partial result
+//
+// TestAstBundle_TypeCheck_Error 测试对包含类型错误的文件进行类型检查
+// 验证返回错误而不是部分结果
+func TestAstBundle_TypeCheck_Error(t *testing.T) {
+	astBundle := rese.P1(NewAstBundleV2(token.NewFileSet(), []byte("package example\n\nvar x int = \"abc\"\n")))
+	_, err := astBundle.TypeCheck()
+	require.Error(t, err)
+	t.Log(err)
+	require.Nil(t, astBundle.GetTypesBundle())
+}
+
+// TestPackageBundle_TypeCheck tests type-checking the current package with its module dependencies
+// Verifies imports are resolved offline from GOROOT, the module and the module cache
+//
+// TestPackageBundle_TypeCheck 测试对当前包及其模块依赖进行类型检查
+// 验证导入从 GOROOT、当前模块和模块缓存中离线解析
+func TestPackageBundle_TypeCheck(t *testing.T) {
+	pkgBundle := rese.P1(NewPackageBundleV1(runpath.PARENT.Path()))
+	typesBundle, err := pkgBundle.TypeCheck()
+	require.NoError(t, err)
+	require.Equal(t, "github.com/yyle88/syntaxgo/syntaxgo_ast", typesBundle.GetPackage().Path())
+
+	for _, astBundle := range pkgBundle.GetAstBundles() {
+		require.Same(t, typesBundle, astBundle.GetTypesBundle())
+	}
+	t.Log(typesBundle.LookupType("NewAstBundleV1"))
+}
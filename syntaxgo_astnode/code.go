@@ -2,22 +2,52 @@ package syntaxgo_astnode
 
 import (
 	"go/ast"
+	"go/token"
 
 	"github.com/yyle88/syntaxgo/internal/utils"
 )
 
+// GetOffset converts a position into a byte offset inside the file that holds the position.
+// It resolves the position through its token.File in the FileSet, which stays correct when many files share one FileSet.
+// When the FileSet is nil or does not hold the position, it falls back to pos-1, which is only right for the first file of a fresh FileSet.
+//
+// GetOffset 将位置转换为该位置所在文件内的字节偏移量。
+// 通过 FileSet 中该位置对应的 token.File 计算偏移量，即使多个文件共享同一个 FileSet 也是正确的。
+// 当 FileSet 为 nil 或不包含该位置时，回退为 pos-1，这仅对新 FileSet 中的第一个文件是正确的。
+func GetOffset(fset *token.FileSet, pos token.Pos) int {
+	if fset != nil {
+		if tokenFile := fset.File(pos); tokenFile != nil {
+			return tokenFile.Offset(pos)
+		}
+	}
+	return int(pos - 1)
+}
+
 // SdxEdx returns the start and end positions of the given AST node as integers.
 // SdxEdx 返回给定 AST 节点的起始和结束位置（整数）。
 func SdxEdx(astNode ast.Node) (sdx, edx int) {
-	sdx = int(astNode.Pos() - 1)
-	edx = int(astNode.End() - 1)
+	return SdxEdxV2(nil, astNode)
+}
+
+// SdxEdxV2 returns the start and end byte offsets of the AST node inside its own file.
+// SdxEdxV2 返回 AST 节点在其所在文件内的起始和结束字节偏移量。
+func SdxEdxV2(fset *token.FileSet, astNode ast.Node) (sdx, edx int) {
+	sdx = GetOffset(fset, astNode.Pos())
+	edx = GetOffset(fset, astNode.End())
 	return sdx, edx
 }
 
 // GetCode returns the code corresponding to the given AST node from the source.
 // GetCode 从源代码中返回与给定 AST 节点对应的代码。
 func GetCode(source []byte, astNode ast.Node) []byte {
-	return source[astNode.Pos()-1 : astNode.End()-1]
+	return GetCodeV2(nil, source, astNode)
+}
+
+// GetCodeV2 returns the code of the AST node from the source, resolving offsets through the FileSet.
+// GetCodeV2 从源代码中返回 AST 节点对应的代码，通过 FileSet 计算偏移量。
+func GetCodeV2(fset *token.FileSet, source []byte, astNode ast.Node) []byte {
+	sdx, edx := SdxEdxV2(fset, astNode)
+	return source[sdx:edx]
 }
 
 // GetText returns the text corresponding to the given AST node from the source.
@@ -26,37 +56,64 @@ func GetText(source []byte, astNode ast.Node) string {
 	return string(GetCode(source, astNode))
 }
 
+// GetTextV2 returns the text of the AST node from the source, resolving offsets through the FileSet.
+// GetTextV2 从源代码中返回 AST 节点对应的文本，通过 FileSet 计算偏移量。
+func GetTextV2(fset *token.FileSet, source []byte, astNode ast.Node) string {
+	return string(GetCodeV2(fset, source, astNode))
+}
+
 // DeleteNodeCode removes the code corresponding to the given AST node from the source.
 // DeleteNodeCode 从源代码中删除与给定 AST 节点对应的代码。
 func DeleteNodeCode(source []byte, astNode ast.Node) []byte {
+	return DeleteNodeCodeV2(nil, source, astNode)
+}
+
+// DeleteNodeCodeV2 removes the code of the AST node from the source, resolving offsets through the FileSet.
+// DeleteNodeCodeV2 从源代码中删除 AST 节点对应的代码，通过 FileSet 计算偏移量。
+func DeleteNodeCodeV2(fset *token.FileSet, source []byte, astNode ast.Node) []byte {
+	sdx, edx := SdxEdxV2(fset, astNode)
 	return utils.SafeMerge(
-		source[:astNode.Pos()-1],
-		source[astNode.End()-1:],
+		source[:sdx],
+		source[edx:],
 	)
 }
 
 // ChangeNodeCode replaces the code corresponding to the given AST node with new code in the source.
 // ChangeNodeCode 用新代码替换源代码中与给定 AST 节点对应的代码。
 func ChangeNodeCode(source []byte, astNode ast.Node, newCode []byte) []byte {
+	return ChangeNodeCodeV2(nil, source, astNode, newCode)
+}
+
+// ChangeNodeCodeV2 replaces the code of the AST node with new code, resolving offsets through the FileSet.
+// ChangeNodeCodeV2 用新代码替换 AST 节点对应的代码，通过 FileSet 计算偏移量。
+func ChangeNodeCodeV2(fset *token.FileSet, source []byte, astNode ast.Node, newCode []byte) []byte {
+	sdx, edx := SdxEdxV2(fset, astNode)
 	return utils.SafeMerge(
-		source[:astNode.Pos()-1],
+		source[:sdx],
 		newCode,
-		source[astNode.End()-1:],
+		source[edx:],
 	)
 }
 
 // ChangeNodeCodeSetSomeNewLines replaces the code corresponding to the given AST node with new code and adds new lines in the source.
 // ChangeNodeCodeSetSomeNewLines 用新代码替换源代码中与给定 AST 节点对应的代码，并添加新行。
 func ChangeNodeCodeSetSomeNewLines(source []byte, astNode ast.Node, newCode []byte, numLine int) []byte {
+	return ChangeNodeCodeSetSomeNewLinesV2(nil, source, astNode, newCode, numLine)
+}
+
+// ChangeNodeCodeSetSomeNewLinesV2 replaces the code of the AST node with new code and new lines, resolving offsets through the FileSet.
+// ChangeNodeCodeSetSomeNewLinesV2 用新代码替换 AST 节点对应的代码并添加新行，通过 FileSet 计算偏移量。
+func ChangeNodeCodeSetSomeNewLinesV2(fset *token.FileSet, source []byte, astNode ast.Node, newCode []byte, numLine int) []byte {
 	var newLines = make([]byte, 0, numLine)
 	for idx := 0; idx < numLine; idx++ {
 		newLines = append(newLines, '\n')
 	}
+	sdx, edx := SdxEdxV2(fset, astNode)
 	return utils.SafeMerge(
-		source[:astNode.Pos()-1],
+		source[:sdx],
 		newLines,
 		newCode,
 		newLines,
-		source[astNode.End()-1:],
+		source[edx:],
 	)
 }
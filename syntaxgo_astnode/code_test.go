@@ -1,6 +1,9 @@
 package syntaxgo_astnode
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -75,3 +78,38 @@ func TestChangeNodeCodeSetSomeNewLines(t *testing.T) {
 	require.Equal(t, "a\n\n88\n\nc", string(ChangeNodeCodeSetSomeNewLines([]byte("abc"), NewNode(2, 3), []byte("88"), 2)))
 	require.Equal(t, "\n\n\n666\n\n\nc", string(ChangeNodeCodeSetSomeNewLines([]byte("abc"), NewNode(1, 3), []byte("666"), 3)))
 }
+
+// TestGetCodeV2 tests extracting code of a node parsed as the second file of a shared FileSet
+// Verifies offsets are resolved through the token.File instead of Pos()-1
+//
+// TestGetCodeV2 测试从共享 FileSet 中第二个文件的节点提取代码
+// 验证偏移量通过 token.File 计算而不是 Pos()-1
+func TestGetCodeV2(t *testing.T) {
+	fset := token.NewFileSet()
+	_, err := parser.ParseFile(fset, "a.go", "package a\n\nfunc A() {}\n", 0)
+	require.NoError(t, err)
+
+	source := []byte("package b\n\nfunc B() {}\n")
+	astFile, err := parser.ParseFile(fset, "b.go", source, 0)
+	require.NoError(t, err)
+	astFunc := astFile.Decls[0].(*ast.FuncDecl)
+
+	sdx, edx := SdxEdxV2(fset, astFunc)
+	require.Equal(t, 11, sdx)
+	require.Equal(t, 22, edx)
+	require.Equal(t, "func B() {}", string(GetCodeV2(fset, source, astFunc)))
+	require.Equal(t, "B", GetTextV2(fset, source, astFunc.Name))
+	require.Equal(t, "package b\n\n\n", string(DeleteNodeCodeV2(fset, source, astFunc)))
+	require.Equal(t, "package b\n\nfunc C() {}\n", string(ChangeNodeCodeV2(fset, source, astFunc.Name, []byte("C"))))
+	require.Equal(t, "B", NewNodeV1(astFunc.Name).GetTextV2(fset, source))
+}
+
+// TestGetOffset tests converting positions into offsets with and without a FileSet
+// Verifies positions outside the FileSet fall back to pos-1
+//
+// TestGetOffset 测试在有和没有 FileSet 时将位置转换为偏移量
+// 验证不在 FileSet 中的位置回退为 pos-1
+func TestGetOffset(t *testing.T) {
+	require.Equal(t, 2, GetOffset(nil, token.Pos(3)))
+	require.Equal(t, 2, GetOffset(token.NewFileSet(), token.Pos(3)))
+}
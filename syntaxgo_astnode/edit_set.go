@@ -0,0 +1,153 @@
+package syntaxgo_astnode
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+
+	"github.com/yyle88/erero"
+)
+
+// Edit is a single change on the original source, replacing source[Sdx:Edx] with NewCode.
+// An insert has Sdx == Edx, a delete has an empty NewCode.
+//
+// Edit 表示对原始源代码的一次修改，用 NewCode 替换 source[Sdx:Edx]。
+// 插入操作的 Sdx == Edx，删除操作的 NewCode 为空。
+type Edit struct {
+	Sdx     int    // Start offset in the original source / 在原始源代码中的起始偏移量
+	Edx     int    // End offset in the original source / 在原始源代码中的结束偏移量
+	NewCode []byte // Code written in place of the range / 替换该区间的代码
+}
+
+// EditSet collects edits keyed by AST nodes and applies them in one pass against the original source.
+// Because every edit refers to the original positions, AST nodes stay valid while the edits are collected.
+//
+// EditSet 收集以 AST 节点为键的修改，并在原始源代码上一次性应用。
+// 由于每次修改都基于原始位置，收集修改期间 AST 节点始终有效。
+type EditSet struct {
+	fset  *token.FileSet // FileSet used to resolve node offsets, nil means pos-1 / 用于计算节点偏移量的文件集，nil 表示 pos-1
+	edits []*Edit        // Edits in the order they were added / 按添加顺序排列的修改
+}
+
+// NewEditSet creates an EditSet resolving node offsets through the FileSet, a nil FileSet falls back to pos-1.
+// NewEditSet 创建一个通过 FileSet 计算节点偏移量的 EditSet，FileSet 为 nil 时回退为 pos-1。
+func NewEditSet(fset *token.FileSet) *EditSet {
+	return &EditSet{fset: fset}
+}
+
+// Replace replaces the code of the AST node with new code.
+// Replace 用新代码替换 AST 节点对应的代码。
+func (es *EditSet) Replace(astNode ast.Node, newCode []byte) *EditSet {
+	sdx, edx := SdxEdxV2(es.fset, astNode)
+	return es.ReplaceRange(sdx, edx, newCode)
+}
+
+// Delete removes the code of the AST node.
+// Delete 删除 AST 节点对应的代码。
+func (es *EditSet) Delete(astNode ast.Node) *EditSet {
+	sdx, edx := SdxEdxV2(es.fset, astNode)
+	return es.ReplaceRange(sdx, edx, nil)
+}
+
+// InsertBefore inserts code right before the AST node.
+// InsertBefore 在 AST 节点之前插入代码。
+func (es *EditSet) InsertBefore(astNode ast.Node, newCode []byte) *EditSet {
+	sdx := GetOffset(es.fset, astNode.Pos())
+	return es.ReplaceRange(sdx, sdx, newCode)
+}
+
+// InsertAfter inserts code right after the AST node.
+// InsertAfter 在 AST 节点之后插入代码。
+func (es *EditSet) InsertAfter(astNode ast.Node, newCode []byte) *EditSet {
+	edx := GetOffset(es.fset, astNode.End())
+	return es.ReplaceRange(edx, edx, newCode)
+}
+
+// ReplaceRange replaces source[sdx:edx] of the original source with new code.
+// ReplaceRange 用新代码替换原始源代码中的 source[sdx:edx]。
+func (es *EditSet) ReplaceRange(sdx, edx int, newCode []byte) *EditSet {
+	es.edits = append(es.edits, &Edit{Sdx: sdx, Edx: edx, NewCode: newCode})
+	return es
+}
+
+// GetEdits returns the collected edits in the order they were added.
+// GetEdits 返回按添加顺序排列的已收集修改。
+func (es *EditSet) GetEdits() []*Edit {
+	return es.edits
+}
+
+// Apply applies every edit against the original source in one pass.
+// Returns an error when an edit is out of range or two edits overlap.
+// Inserts at the same offset keep the order they were added, and come before a replacement starting there.
+//
+// Apply 在原始源代码上一次性应用所有修改。
+// 当修改越界或两个修改重叠时返回错误。
+// 同一位置的多个插入保持添加顺序，并排在从该位置开始的替换之前。
+func (es *EditSet) Apply(source []byte) ([]byte, *OffsetMapping, error) {
+	edits := slices.Clone(es.edits)
+	for _, edit := range edits {
+		if edit.Sdx < 0 || edit.Sdx > edit.Edx || edit.Edx > len(source) {
+			return nil, nil, erero.Errorf("edit range [%d:%d] out of source length %d", edit.Sdx, edit.Edx, len(source))
+		}
+	}
+	slices.SortStableFunc(edits, compareEdit)
+	for idx := 1; idx < len(edits); idx++ {
+		prev, next := edits[idx-1], edits[idx]
+		if next.Sdx < prev.Edx {
+			return nil, nil, erero.Errorf("edit range [%d:%d] overlaps edit range [%d:%d]", next.Sdx, next.Edx, prev.Sdx, prev.Edx)
+		}
+	}
+
+	var result = make([]byte, 0, len(source))
+	var cursor = 0
+	for _, edit := range edits {
+		result = append(result, source[cursor:edit.Sdx]...)
+		result = append(result, edit.NewCode...)
+		cursor = edit.Edx
+	}
+	result = append(result, source[cursor:]...)
+	return result, &OffsetMapping{edits: edits}, nil
+}
+
+// compareEdit orders edits by start offset, inserts come before a replacement starting at the same offset.
+// compareEdit 按起始偏移量排序，同一位置的插入排在替换之前。
+func compareEdit(a, b *Edit) int {
+	if a.Sdx != b.Sdx {
+		return a.Sdx - b.Sdx
+	}
+	return (a.Edx - a.Sdx) - (b.Edx - b.Sdx)
+}
+
+// OffsetMapping maps offsets in the original source to offsets in the edited source.
+// OffsetMapping 将原始源代码中的偏移量映射为修改后源代码中的偏移量。
+type OffsetMapping struct {
+	edits []*Edit // Applied edits sorted by start offset / 按起始偏移量排序的已应用修改
+}
+
+// MapOffset maps an offset in the original source to the edited source.
+// An offset at an insert moves after the inserted code, an offset at the start of a replacement maps to the start of the new code.
+// Returns false when the offset was inside a replaced or deleted range, the result is then the start of the new code.
+//
+// MapOffset 将原始源代码中的偏移量映射到修改后的源代码。
+// 位于插入点的偏移量移到插入代码之后，位于替换起点的偏移量映射到新代码的起点。
+// 当偏移量位于被替换或删除的区间内部时返回 false，此时结果为新代码的起点。
+func (m *OffsetMapping) MapOffset(offset int) (int, bool) {
+	var delta = 0
+	for _, edit := range m.edits {
+		if offset < edit.Sdx {
+			break
+		}
+		if edit.Sdx == edit.Edx {
+			delta += len(edit.NewCode)
+			continue
+		}
+		if offset == edit.Sdx {
+			break
+		}
+		if offset < edit.Edx {
+			return edit.Sdx + delta, false
+		}
+		delta += len(edit.NewCode) - (edit.Edx - edit.Sdx)
+	}
+	return offset + delta, true
+}
@@ -0,0 +1,90 @@
+package syntaxgo_astnode
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestEditSet_Apply tests applying many edits keyed by AST nodes in one pass
+// Verifies replace, insert and delete use the original positions
+//
+// TestEditSet_Apply 测试一次性应用多个以 AST 节点为键的修改
+// 验证替换、插入和删除都使用原始位置
+func TestEditSet_Apply(t *testing.T) {
+	source := []byte("package a\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n")
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, "a.go", source, 0)
+	require.NoError(t, err)
+	funcA := astFile.Decls[0].(*ast.FuncDecl)
+	funcB := astFile.Decls[1].(*ast.FuncDecl)
+	funcC := astFile.Decls[2].(*ast.FuncDecl)
+
+	editSet := NewEditSet(fset).
+		Replace(funcA.Name, []byte("Alpha")).
+		Delete(funcB).
+		InsertBefore(funcC, []byte("// C is the third\n")).
+		InsertAfter(funcC, []byte("\n\nfunc D() {}"))
+
+	newSource, mapping, err := editSet.Apply(source)
+	require.NoError(t, err)
+	t.Log(string(newSource))
+	require.Equal(t, "package a\n\nfunc Alpha() {}\n\n\n\n// C is the third\nfunc C() {}\n\nfunc D() {}\n", string(newSource))
+
+	offset, ok := mapping.MapOffset(GetOffset(fset, funcC.Name.Pos()))
+	require.True(t, ok)
+	require.Equal(t, "C", string(newSource[offset:offset+1]))
+
+	offset, ok = mapping.MapOffset(GetOffset(fset, funcB.Name.Pos()))
+	require.False(t, ok)
+	t.Log(offset)
+}
+
+// TestEditSet_Apply_Overlap tests rejecting overlapping edits
+// Verifies an error is returned when an edit falls inside another one
+//
+// TestEditSet_Apply_Overlap 测试拒绝重叠的修改
+// 验证当一个修改落在另一个修改内部时返回错误
+func TestEditSet_Apply_Overlap(t *testing.T) {
+	source := []byte("abcdef")
+	_, _, err := NewEditSet(nil).
+		Replace(NewNode(1, 4), []byte("x")).
+		Replace(NewNode(3, 6), []byte("y")).
+		Apply(source)
+	require.Error(t, err)
+	t.Log(err)
+
+	_, _, err = NewEditSet(nil).
+		Delete(NewNode(1, 4)).
+		ReplaceRange(2, 2, []byte("z")).
+		Apply(source)
+	require.Error(t, err)
+	t.Log(err)
+}
+
+// TestEditSet_Apply_SameOffset tests inserts at the same offset and next to a replacement
+// Verifies inserts keep their order and come before a replacement starting there
+//
+// TestEditSet_Apply_SameOffset 测试同一位置的插入以及紧邻替换的插入
+// 验证插入保持顺序并排在从该位置开始的替换之前
+func TestEditSet_Apply_SameOffset(t *testing.T) {
+	newSource, mapping, err := NewEditSet(nil).
+		ReplaceRange(2, 4, []byte("XY")).
+		ReplaceRange(2, 2, []byte("1")).
+		ReplaceRange(2, 2, []byte("2")).
+		ReplaceRange(4, 4, []byte("3")).
+		Apply([]byte("abcdef"))
+	require.NoError(t, err)
+	require.Equal(t, "ab12XY3ef", string(newSource))
+
+	offset, ok := mapping.MapOffset(2)
+	require.True(t, ok)
+	require.Equal(t, 4, offset)
+
+	offset, ok = mapping.MapOffset(5)
+	require.True(t, ok)
+	require.Equal(t, "f", string(newSource[offset:offset+1]))
+}
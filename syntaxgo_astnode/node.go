@@ -64,11 +64,23 @@ func (x *Node) SetEnd(end token.Pos) {
 // GetCode returns the code corresponding to the Node from the source.
 // GetCode 从源代码中返回与 Node 对应的代码。
 func (x *Node) GetCode(source []byte) []byte {
-	return source[x.Pos()-1 : x.End()-1]
+	return GetCodeV2(nil, source, x)
 }
 
 // GetText returns the text corresponding to the Node from the source.
 // GetText 从源代码中返回与 Node 对应的文本。
 func (x *Node) GetText(source []byte) string {
-	return string(source[x.Pos()-1 : x.End()-1])
+	return string(GetCodeV2(nil, source, x))
+}
+
+// GetCodeV2 returns the code of the Node from the source, resolving offsets through the FileSet.
+// GetCodeV2 从源代码中返回 Node 对应的代码，通过 FileSet 计算偏移量。
+func (x *Node) GetCodeV2(fset *token.FileSet, source []byte) []byte {
+	return GetCodeV2(fset, source, x)
+}
+
+// GetTextV2 returns the text of the Node from the source, resolving offsets through the FileSet.
+// GetTextV2 从源代码中返回 Node 对应的文本，通过 FileSet 计算偏移量。
+func (x *Node) GetTextV2(fset *token.FileSet, source []byte) string {
+	return string(GetCodeV2(fset, source, x))
 }
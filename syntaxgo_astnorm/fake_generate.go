@@ -0,0 +1,360 @@
+package syntaxgo_astnorm
+
+import (
+	"go/ast"
+	"go/format"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+	"github.com/yyle88/syntaxgo/syntaxgo_search"
+)
+
+// FakeOptions controls the name and the qualification of a generated fake.
+// FakeOptions 控制生成的 fake 的名称和类型限定。
+type FakeOptions struct {
+	fakeName    string // Fake struct name, interface name with "Fake" prefix by default / fake 结构体名，默认为接口名加 "Fake" 前缀
+	importPath  string // Import path of the source package, empty when the fake is in the same package / 源包的导入路径，fake 在同一个包中时为空
+	packageName string // Name to qualify the types of the source package, the package name by default / 限定源包类型时使用的名称，默认为包名
+}
+
+// NewFakeOptions creates options for a fake in the same package as the interface.
+// NewFakeOptions 创建 fake 与接口在同一个包中时的选项。
+func NewFakeOptions() *FakeOptions {
+	return &FakeOptions{}
+}
+
+// SetFakeName sets the fake struct name.
+// SetFakeName 设置 fake 结构体名。
+func (opts *FakeOptions) SetFakeName(fakeName string) *FakeOptions {
+	opts.fakeName = fakeName
+	return opts
+}
+
+// SetImportPath sets the import path of the source package, so exported types of the package get qualified and imported.
+// SetImportPath 设置源包的导入路径，使包中导出的类型被限定并被导入。
+func (opts *FakeOptions) SetImportPath(importPath string) *FakeOptions {
+	opts.importPath = importPath
+	return opts
+}
+
+// SetPackageName sets the name to qualify the types of the source package.
+// SetPackageName 设置限定源包类型时使用的名称。
+func (opts *FakeOptions) SetPackageName(packageName string) *FakeOptions {
+	opts.packageName = packageName
+	return opts
+}
+
+// FakeDecl is a fake implementation of an interface, with a XxxFunc field and a XxxCalls record per method.
+// Methods delegate to the XxxFunc fields and panic when the field is not set.
+//
+// FakeDecl 是接口的 fake 实现，每个方法对应一个 XxxFunc 字段和一个 XxxCalls 调用记录。
+// 方法委托给 XxxFunc 字段，字段未设置时 panic。
+type FakeDecl struct {
+	Name          string                     // Fake struct name / fake 结构体名
+	InterfaceName string                     // Interface name / 接口名
+	TypeParams    NameTypeElements           // Type parameters of a generic interface, Kind is the constraint / 泛型接口的类型参数，Kind 为约束
+	Methods       []*FuncSignature           // Methods including those of embedded interfaces / 方法，包括嵌入接口的方法
+	Imports       []*syntaxgo_ast.ImportPath // Imports the code needs, sorted by path / 代码需要的导入，按路径排序
+}
+
+// NewFakeDecl creates the fake of the interface declared in the package.
+// Embedded interfaces of the package are read from their declarations. The built-in error, interfaces of imported packages
+// like fmt.Stringer, and instances of generic interfaces like Base[T] are resolved through go/types.
+// Like ExtractInterface, conflicting import names across the files are reported as an error.
+//
+// NewFakeDecl 创建包中声明的接口的 fake。
+// 包中的嵌入接口从其声明中读取。内置的 error、被导入包中的接口（比如 fmt.Stringer）
+// 以及泛型接口的实例（比如 Base[T]）通过 go/types 解析。
+// 与 ExtractInterface 一样，文件之间冲突的导入名称会报错。
+func NewFakeDecl(pkgBundle *syntaxgo_ast.PackageBundle, interfaceName string, opts *FakeOptions) (*FakeDecl, error) {
+	signatureOptions := NewFuncSignatureOptions().SetImportPath(opts.importPath).SetPackageName(opts.packageName)
+	fakeDecl := &FakeDecl{
+		Name:          opts.fakeName,
+		InterfaceName: interfaceName,
+	}
+	if fakeDecl.Name == "" {
+		fakeDecl.Name = "Fake" + interfaceName
+	}
+
+	astBundle, typeSpec, ok := findInterfaceSpec(pkgBundle, interfaceName)
+	if !ok {
+		return nil, erero.Errorf("interface %s is not found", interfaceName)
+	}
+	typeParams := GetGenericTypeParamsMap(typeSpec.TypeParams)
+	if typeSpec.TypeParams != nil {
+		astFile, fset := astBundle.GetBundle()
+		packageName := ""
+		if opts.importPath != "" {
+			packageName = opts.packageName
+			if packageName == "" {
+				packageName = astFile.Name.Name
+			}
+		}
+		fakeDecl.TypeParams = ExtractNameTypeElementsV2(fset, typeSpec.TypeParams.List, makeForwardNameFunction("T"), astBundle.GetSource(), packageName, typeParams)
+	}
+
+	var importPaths = map[string]*syntaxgo_ast.ImportPath{"sync": syntaxgo_ast.NewImportPath("", "sync")}
+	var visited = map[string]bool{interfaceName: true}
+	var resolver = &embedResolver{pkgBundle: pkgBundle}
+	var collect func(astBundle *syntaxgo_ast.AstBundle, typeSpec *ast.TypeSpec) error
+	collect = func(astBundle *syntaxgo_ast.AstBundle, typeSpec *ast.TypeSpec) error {
+		astFile, fset := astBundle.GetBundle()
+		for _, field := range typeSpec.Type.(*ast.InterfaceType).Methods.List {
+			if funcType, ok := field.Type.(*ast.FuncType); ok {
+				for _, name := range field.Names {
+					if slices.ContainsFunc(fakeDecl.Methods, func(method *FuncSignature) bool { return method.Name == name.Name }) {
+						continue
+					}
+					funcDecl := &ast.FuncDecl{Name: name, Type: funcType}
+					signature, err := newFuncSignature(fset, astBundle.GetSource(), astFile, funcDecl, signatureOptions, typeParams, "fake")
+					if err != nil {
+						return erero.Wro(err)
+					}
+					fakeDecl.Methods = append(fakeDecl.Methods, signature)
+					for _, importPath := range signature.Imports {
+						if err := addImportPath(importPaths, importPath); err != nil {
+							return erero.Wro(err)
+						}
+					}
+				}
+				continue
+			}
+			embedName := types.ExprString(field.Type)
+			if visited[embedName] {
+				continue
+			}
+			visited[embedName] = true
+			if ident, ok := field.Type.(*ast.Ident); ok {
+				if embeddedBundle, embeddedSpec, ok := findInterfaceSpec(pkgBundle, ident.Name); ok && embeddedSpec.TypeParams == nil {
+					if err := collect(embeddedBundle, embeddedSpec); err != nil {
+						return erero.Wro(err)
+					}
+					continue
+				}
+			}
+			iface, localPkg, err := resolver.resolve(astFile, field.Type)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			renderedBundle, renderedSpec, err := renderInterfaceSpec(astFile, localPkg, iface)
+			if err != nil {
+				return erero.Wro(err)
+			}
+			if err := collect(renderedBundle, renderedSpec); err != nil {
+				return erero.Wro(err)
+			}
+		}
+		return nil
+	}
+	if err := collect(astBundle, typeSpec); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	for _, importPath := range importPaths {
+		fakeDecl.Imports = append(fakeDecl.Imports, importPath)
+	}
+	slices.SortFunc(fakeDecl.Imports, func(a, b *syntaxgo_ast.ImportPath) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return fakeDecl, nil
+}
+
+// Generate generates the gofmt-clean fake struct, the call record structs and the methods.
+// Member, call record and field names go through NameScope, so `mutex()`, `X` with `XFunc`, or `Put(a, A int)` do not clash.
+//
+// Generate 生成按 gofmt 格式化的 fake 结构体、调用记录结构体和方法。
+// 成员名、调用记录名和字段名经由 NameScope 生成，因此 `mutex()`、`X` 与 `XFunc`、以及 `Put(a, A int)` 不会冲突。
+func (fakeDecl *FakeDecl) Generate() ([]byte, error) {
+	typeParams, typeArgs := "", ""
+	if len(fakeDecl.TypeParams) > 0 {
+		typeParams = "[" + fakeDecl.TypeParams.FormatNamesWithKinds().MergeParts() + "]"
+		typeArgs = "[" + fakeDecl.TypeParams.Names().MergeParts() + "]"
+	}
+
+	var methodNames = make([]string, 0, len(fakeDecl.Methods))
+	for _, method := range fakeDecl.Methods {
+		methodNames = append(methodNames, method.Name)
+	}
+	memberScope := NewNameScope(methodNames...)
+	typeScope := NewNameScope(fakeDecl.Name)
+	mutexName := memberScope.Unique("mutex")
+	var funcNames, callsNames, callNames []string
+	for _, method := range fakeDecl.Methods {
+		funcNames = append(funcNames, memberScope.Unique(method.Name+"Func"))
+		callsNames = append(callsNames, memberScope.Unique(method.Name+"Calls"))
+		callNames = append(callNames, typeScope.Unique(fakeDecl.Name+method.Name+"Call"))
+	}
+
+	ptx := utils.NewPTX()
+	ptx.Println("// " + fakeDecl.Name + " is a fake implementation of " + fakeDecl.InterfaceName + ".")
+	ptx.Println("type " + fakeDecl.Name + typeParams + " struct {")
+	ptx.Println("\t" + mutexName + " sync.Mutex")
+	for idx, method := range fakeDecl.Methods {
+		ptx.Println("\t" + funcNames[idx] + " func(" + method.FormatParams() + ") " + method.FormatResults())
+		ptx.Println("\t" + callsNames[idx] + " []" + callNames[idx] + typeArgs)
+	}
+	ptx.Println("}")
+	for idx, method := range fakeDecl.Methods {
+		callName, funcName, callsName := callNames[idx], funcNames[idx], callsNames[idx]
+		ptx.Println()
+		ptx.Println("// " + callName + " records the arguments of a call to " + fakeDecl.Name + "." + method.Name + ".")
+		fieldScope := NewNameScope()
+		var fields, values []string
+		for _, param := range method.Params {
+			fieldName := fieldScope.Unique(strings.ToUpper(param.Name[:1]) + param.Name[1:])
+			fields = append(fields, "\t"+fieldName+" "+strings.Replace(param.Kind, "...", "[]", 1)+"\n")
+			values = append(values, fieldName+": "+param.Name)
+		}
+		if len(fields) == 0 {
+			ptx.Println("type " + callName + typeParams + " struct{}")
+		} else {
+			ptx.Println("type " + callName + typeParams + " struct {\n" + strings.Join(fields, "") + "}")
+		}
+		ptx.Println()
+		ptx.Println("func (fake *" + fakeDecl.Name + typeArgs + ") " + method.Name + "(" + method.FormatParams() + ") " + method.FormatResults() + " {")
+		ptx.Println("\tfake." + mutexName + ".Lock()")
+		ptx.Println("\tfake." + callsName + " = append(fake." + callsName + ", " + callName + typeArgs + "{" + strings.Join(values, ", ") + "})")
+		ptx.Println("\tfake." + mutexName + ".Unlock()")
+		ptx.Println("\tif fake." + funcName + " == nil {")
+		ptx.Println("\t\tpanic(\"" + fakeDecl.Name + "." + funcName + " is not set\")")
+		ptx.Println("\t}")
+		ptx.Println("\t" + method.GenerateReturn("fake."+funcName))
+		ptx.Println("}")
+	}
+	code, err := format.Source(ptx.Bytes())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return code, nil
+}
+
+// findInterfaceSpec finds the interface type declaration with the name across the files of the package.
+// findInterfaceSpec 在包内所有文件中查找具有该名称的接口类型声明。
+func findInterfaceSpec(pkgBundle *syntaxgo_ast.PackageBundle, interfaceName string) (*syntaxgo_ast.AstBundle, *ast.TypeSpec, bool) {
+	for _, astBundle := range pkgBundle.GetAstBundles() {
+		astFile, _ := astBundle.GetBundle()
+		for _, typeSpec := range syntaxgo_search.FindTypes(astFile) {
+			if typeSpec.Name.Name != interfaceName {
+				continue
+			}
+			if _, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				return astBundle, typeSpec, true
+			}
+			return nil, nil, false
+		}
+	}
+	return nil, nil, false
+}
+
+// embedResolver resolves embedded interfaces through go/types, the importer and the type check of the package are made on demand.
+// embedResolver 通过 go/types 解析嵌入的接口，导入器和包的类型检查按需创建。
+type embedResolver struct {
+	pkgBundle *syntaxgo_ast.PackageBundle   // Package of the interface / 接口所在的包
+	importer  *syntaxgo_ast.OfflineImporter // Importer of the packages imported by the files / 导入文件所导入的包的导入器
+}
+
+// resolve returns the interface type of the embedded element, with the package checked when the element needs the package.
+// The built-in error and interfaces of imported packages come without checking the package, generic instances need it.
+//
+// resolve 返回嵌入元素的接口类型，需要检查包时同时返回被检查的包。
+// 内置的 error 和被导入包中的接口无需检查包，泛型实例则需要。
+func (resolver *embedResolver) resolve(astFile *ast.File, fieldType ast.Expr) (*types.Interface, *types.Package, error) {
+	var embedType types.Type
+	var localPkg *types.Package
+	switch x := fieldType.(type) {
+	case *ast.Ident:
+		if x.Name == "error" {
+			embedType = types.Universe.Lookup("error").Type()
+		}
+	case *ast.SelectorExpr:
+		if ident, ok := x.X.(*ast.Ident); ok {
+			importPath, ok := findImportByName(astFile, ident.Name)
+			if !ok {
+				return nil, nil, erero.Errorf("package %s of embedded interface %s is not imported", ident.Name, types.ExprString(x))
+			}
+			if resolver.importer == nil {
+				resolver.importer = syntaxgo_ast.NewOfflineImporter(resolver.pkgBundle.GetFileSet(), resolver.pkgBundle.GetRoot())
+			}
+			pkg, err := resolver.importer.Import(importPath.Path)
+			if err != nil {
+				return nil, nil, erero.Wro(err)
+			}
+			typeName, ok := pkg.Scope().Lookup(x.Sel.Name).(*types.TypeName)
+			if !ok {
+				return nil, nil, erero.Errorf("embedded interface %s is not found in package %s", types.ExprString(x), importPath.Path)
+			}
+			embedType = typeName.Type()
+		}
+	}
+	if embedType == nil {
+		typesBundle := resolver.pkgBundle.GetTypesBundle()
+		if typesBundle == nil {
+			var err error
+			if typesBundle, err = resolver.pkgBundle.TypeCheck(); err != nil {
+				return nil, nil, erero.Wro(err)
+			}
+		}
+		if embedType = typesBundle.TypeOf(fieldType); embedType == nil {
+			return nil, nil, erero.Errorf("type of embedded element %s is not found", types.ExprString(fieldType))
+		}
+		localPkg = typesBundle.GetPackage()
+	}
+	iface, ok := embedType.Underlying().(*types.Interface)
+	if !ok || !iface.IsMethodSet() {
+		return nil, nil, erero.Errorf("embedded element %s is not an interface of methods", types.ExprString(fieldType))
+	}
+	return iface, localPkg, nil
+}
+
+// renderInterfaceSpec renders the methods of the interface type into an interface declaration of a file in the package,
+// so they are collected like the methods declared in the package. Types of the local package are left unqualified,
+// types of other packages are qualified with the names the file imports them with.
+//
+// renderInterfaceSpec 将接口类型的方法渲染为包内某个文件中的接口声明，使其能像包中声明的方法一样被收集。
+// 本地包的类型不带包名，其他包的类型使用文件导入它们时的名称限定。
+func renderInterfaceSpec(astFile *ast.File, localPkg *types.Package, iface *types.Interface) (*syntaxgo_ast.AstBundle, *ast.TypeSpec, error) {
+	var importPaths []*syntaxgo_ast.ImportPath
+	qualifier := func(pkg *types.Package) string {
+		if pkg == localPkg {
+			return ""
+		}
+		importPath := syntaxgo_ast.NewImportPath("", pkg.Path())
+		for _, importSpec := range astFile.Imports {
+			if path, err := strconv.Unquote(importSpec.Path.Value); err == nil && path == pkg.Path() && importSpec.Name != nil && importSpec.Name.Name != "_" && importSpec.Name.Name != "." {
+				importPath.Alias = importSpec.Name.Name
+			}
+		}
+		if importPath.Alias == "" && pkg.Name() != syntaxgo_ast.GuessPackageName(pkg.Path()) {
+			importPath.Alias = pkg.Name()
+		}
+		if !slices.ContainsFunc(importPaths, func(item *syntaxgo_ast.ImportPath) bool { return item.Path == importPath.Path }) {
+			importPaths = append(importPaths, importPath)
+		}
+		return getImportName(importPath)
+	}
+
+	var methods []string
+	for index := 0; index < iface.NumMethods(); index++ {
+		method := iface.Method(index)
+		methods = append(methods, "\t"+method.Name()+strings.TrimPrefix(types.TypeString(method.Type(), qualifier), "func")+"\n")
+	}
+	ptx := utils.NewPTX()
+	ptx.Println("package " + astFile.Name.Name)
+	ptx.Println()
+	for _, importPath := range importPaths {
+		ptx.Println("import " + importPath.String())
+	}
+	ptx.Println()
+	ptx.Println("type _ interface {\n" + strings.Join(methods, "") + "}")
+	astBundle, err := syntaxgo_ast.NewAstBundleV1(ptx.Bytes())
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	astFile, _ = astBundle.GetBundle()
+	return astBundle, syntaxgo_search.FindTypes(astFile)[0], nil
+}
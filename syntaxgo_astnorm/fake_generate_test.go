@@ -0,0 +1,179 @@
+package syntaxgo_astnorm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const fakeStoreCode = `package store
+
+import "context"
+
+type Closer interface {
+	Close() error
+}
+
+type Store[K comparable, V any] interface {
+	Closer
+	Get(ctx context.Context, key K) (V, bool)
+	Put(key K, values ...V)
+}
+`
+
+// newFakePackage writes the store package into a temp module and loads it.
+// newFakePackage 将 store 包写入临时模块并加载。
+func newFakePackage(t *testing.T) (string, *syntaxgo_ast.PackageBundle) {
+	root := tests.NewTempModule(t, "example.com/store", map[string]string{
+		"store.go": fakeStoreCode,
+	})
+	return root, rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+}
+
+// TestNewFakeDecl tests generating the fake of a generic interface with an embedded interface and variadic params
+// Verifies the generated code and that it implements the interface after type checking
+//
+// TestNewFakeDecl 测试为带嵌入接口和变参的泛型接口生成 fake
+// 验证生成的代码，并在类型检查后确认其实现了该接口
+func TestNewFakeDecl(t *testing.T) {
+	root, pkgBundle := newFakePackage(t)
+
+	fakeDecl := rese.P1(NewFakeDecl(pkgBundle, "Store", NewFakeOptions()))
+	require.Equal(t, "FakeStore", fakeDecl.Name)
+	require.Len(t, fakeDecl.Methods, 3)
+	require.Equal(t, []string{"context", "sync"}, []string{fakeDecl.Imports[0].Path, fakeDecl.Imports[1].Path})
+
+	code := rese.V1(fakeDecl.Generate())
+	t.Log(string(code))
+	require.Contains(t, string(code), "type FakeStore[K comparable, V any] struct {")
+	require.Contains(t, string(code), "\tPutFunc    func(key K, values ...V)\n\tPutCalls   []FakeStorePutCall[K, V]\n")
+	require.Contains(t, string(code), `func (fake *FakeStore[K, V]) Put(key K, values ...V) {
+	fake.mutex.Lock()
+	fake.PutCalls = append(fake.PutCalls, FakeStorePutCall[K, V]{Key: key, Values: values})
+	fake.mutex.Unlock()
+	if fake.PutFunc == nil {
+		panic("FakeStore.PutFunc is not set")
+	}
+	fake.PutFunc(key, values...)
+}
+`)
+	require.Contains(t, string(code), "\treturn fake.GetFunc(ctx, key)\n")
+	require.Contains(t, string(code), "type FakeStoreCloseCall[K comparable, V any] struct{}\n")
+
+	source := []byte("package store\n\n" + string(code) + "\nvar _ Store[string, int] = (*FakeStore[string, int])(nil)\n")
+	source = rese.V1(syntaxgo_ast.InjectImportsV2(source, fakeDecl.Imports, ""))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "fake_store.go"), source, 0644))
+	rese.P1(rese.P1(syntaxgo_ast.NewPackageBundleV1(root)).TypeCheck())
+}
+
+// TestNewFakeDecl_Package tests generating the fake in another package
+// Verifies the types of the package are qualified, and unsupported interfaces give errors
+//
+// TestNewFakeDecl_Package 测试在另一个包中生成 fake
+// 验证包中的类型带包名，且不支持的接口会报错
+func TestNewFakeDecl_Package(t *testing.T) {
+	_, pkgBundle := newFakePackage(t)
+
+	fakeDecl := rese.P1(NewFakeDecl(pkgBundle, "Closer", NewFakeOptions().SetFakeName("Closer").SetImportPath("example.com/store")))
+	require.Len(t, fakeDecl.Methods, 1)
+	require.Equal(t, []string{"sync"}, []string{fakeDecl.Imports[0].Path})
+
+	code := rese.V1(fakeDecl.Generate())
+	require.Contains(t, string(code), "\treturn fake.CloseFunc()\n")
+
+	_, err := NewFakeDecl(pkgBundle, "Missing", NewFakeOptions())
+	require.Error(t, err)
+}
+
+const fakeSourceCode = `package source
+
+import (
+	"fmt"
+	stdio "io"
+)
+
+type Key string
+
+type Base[T any] interface {
+	Load(key Key) (T, error)
+}
+
+type Source[T any] interface {
+	error
+	fmt.Stringer
+	stdio.Closer
+	stdio.WriterTo
+	Base[T]
+	Load(key Key) (T, error)
+}
+`
+
+// TestNewFakeDecl_Embeds tests generating the fake of an interface embedding error, imported interfaces and a generic instance
+// Verifies the embedded methods are collected with the import names of the file, and the fake implements the interface
+//
+// TestNewFakeDecl_Embeds 测试为嵌入 error、被导入接口和泛型实例的接口生成 fake
+// 验证嵌入的方法使用文件的导入名称被收集，且 fake 实现了该接口
+func TestNewFakeDecl_Embeds(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/source", map[string]string{
+		"source.go": fakeSourceCode,
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	fakeDecl := rese.P1(NewFakeDecl(pkgBundle, "Source", NewFakeOptions()))
+	var methods []string
+	for _, method := range fakeDecl.Methods {
+		methods = append(methods, method.Name+"("+method.FormatParams()+") "+method.FormatResults())
+	}
+	require.Equal(t, []string{"Error() string", "String() string", "Close() error", "WriteTo(w stdio.Writer) (n int64, err error)", "Load(key Key) (T, error)"}, methods)
+	require.Equal(t, []string{"stdio \"io\"", "\"sync\""}, []string{fakeDecl.Imports[0].String(), fakeDecl.Imports[1].String()})
+
+	code := rese.V1(fakeDecl.Generate())
+	t.Log(string(code))
+	source := []byte("package source\n\n" + string(code) + "\nvar _ Source[int] = (*FakeSource[int])(nil)\n")
+	source = rese.V1(syntaxgo_ast.InjectImportsV2(source, fakeDecl.Imports, ""))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "fake_source.go"), source, 0644))
+	rese.P1(rese.P1(syntaxgo_ast.NewPackageBundleV1(root)).TypeCheck())
+
+	fakeDecl = rese.P1(NewFakeDecl(pkgBundle, "Base", NewFakeOptions().SetImportPath("example.com/source")))
+	require.Equal(t, "key source.Key", fakeDecl.Methods[0].FormatParams())
+}
+
+const fakeClashCode = `package clash
+
+type Clash interface {
+	Put(a, A int)
+	mutex()
+	X()
+	XFunc()
+}
+`
+
+// TestNewFakeDecl_NameClashes tests generating the fake of an interface whose names clash with the generated names
+// Verifies params differing in case, a method named mutex, and methods X and XFunc get unique names and type-check
+//
+// TestNewFakeDecl_NameClashes 测试为名称与生成名称冲突的接口生成 fake
+// 验证仅大小写不同的参数、名为 mutex 的方法以及 X 和 XFunc 方法得到唯一的名称并通过类型检查
+func TestNewFakeDecl_NameClashes(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/clash", map[string]string{
+		"clash.go": fakeClashCode,
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	fakeDecl := rese.P1(NewFakeDecl(pkgBundle, "Clash", NewFakeOptions()))
+	code := rese.V1(fakeDecl.Generate())
+	t.Log(string(code))
+	require.Contains(t, string(code), "\tmutex1     sync.Mutex\n")
+	require.Contains(t, string(code), "type FakeClashPutCall struct {\n\tA  int\n\tA1 int\n}\n")
+	require.Contains(t, string(code), "\tXFunc1     func()\n")
+	require.Contains(t, string(code), "\tXFuncFunc  func()\n")
+
+	source := []byte("package clash\n\n" + string(code) + "\nvar _ Clash = (*FakeClash)(nil)\n")
+	source = rese.V1(syntaxgo_ast.InjectImportsV2(source, fakeDecl.Imports, ""))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "fake_clash.go"), source, 0644))
+	rese.P1(rese.P1(syntaxgo_ast.NewPackageBundleV1(root)).TypeCheck())
+}
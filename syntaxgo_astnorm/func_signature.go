@@ -0,0 +1,349 @@
+package syntaxgo_astnorm
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// FuncSignatureOptions controls how a FuncSignature qualifies the types of the source package.
+// FuncSignatureOptions 控制 FuncSignature 如何限定源包中的类型。
+type FuncSignatureOptions struct {
+	importPath  string // Import path of the source package, empty when the wrapper is in the same package / 源包的导入路径，包裹函数在同一个包中时为空
+	packageName string // Name to qualify the types of the source package, the file's package name by default / 限定源包类型时使用的名称，默认为文件的包名
+}
+
+// NewFuncSignatureOptions creates options for a wrapper in the same package as the function.
+// NewFuncSignatureOptions 创建包裹函数与原函数在同一个包中时的选项。
+func NewFuncSignatureOptions() *FuncSignatureOptions {
+	return &FuncSignatureOptions{}
+}
+
+// SetImportPath sets the import path of the source package, so exported types of the package get qualified and imported.
+// SetImportPath 设置源包的导入路径，使包中导出的类型被限定并被导入。
+func (opts *FuncSignatureOptions) SetImportPath(importPath string) *FuncSignatureOptions {
+	opts.importPath = importPath
+	return opts
+}
+
+// SetPackageName sets the name to qualify the types of the source package, the package is imported with it as the alias when needed.
+// SetPackageName 设置限定源包类型时使用的名称，需要时会以该名称作为别名导入包。
+func (opts *FuncSignatureOptions) SetPackageName(packageName string) *FuncSignatureOptions {
+	opts.packageName = packageName
+	return opts
+}
+
+// FuncSignature is the signature of a function or method, ready to generate a forwarding function.
+// Params are named through a NameScope, so unnamed and "_" params get names derived from their types,
+// and no param shadows an import, the receiver or a type parameter.
+//
+// FuncSignature 是函数或方法的签名，可用于生成转发函数。
+// 参数通过 NameScope 命名，未命名和 "_" 参数得到根据类型推导的名称，
+// 且参数不会遮蔽导入、接收者或类型参数。
+type FuncSignature struct {
+	Name         string                     // Function or method name / 函数或方法名
+	Recv         *NameTypeElement           // Receiver, nil for functions / 接收者，函数时为 nil
+	TypeParams   NameTypeElements           // Type parameters, Kind is the constraint / 类型参数，Kind 为约束
+	Params       NameTypeElements           // Params / 参数
+	Results      NameTypeElements           // Results / 返回值
+	NamedResults bool                       // Whether the results are named in the declaration / 声明中的返回值是否有名称
+	Imports      []*syntaxgo_ast.ImportPath // Imports the types need, sorted by path / 类型需要的导入，按路径排序
+}
+
+// NewFuncSignature creates the signature of the function declared in the file, the source is the code of the file.
+// With an import path set, it returns an error when the signature refers to unexported types of the package.
+//
+// NewFuncSignature 创建文件中声明的函数的签名，source 为文件的源代码。
+// 设置了导入路径时，签名引用包中未导出的类型会返回错误。
+func NewFuncSignature(fset *token.FileSet, source []byte, astFile *ast.File, funcDecl *ast.FuncDecl, opts *FuncSignatureOptions) (*FuncSignature, error) {
+	return newFuncSignature(fset, source, astFile, funcDecl, opts, nil)
+}
+
+// newFuncSignature creates the signature with the outer type parameters in scope, like those of a generic interface.
+// The reserved names, like the receiver name of generated methods, are kept from the param names.
+//
+// newFuncSignature 创建签名，外层的类型参数（比如泛型接口的类型参数）在作用域内。
+// 参数名会避开保留名称，比如生成的方法的接收者名。
+func newFuncSignature(fset *token.FileSet, source []byte, astFile *ast.File, funcDecl *ast.FuncDecl, opts *FuncSignatureOptions, outerTypeParams map[string]ast.Expr, reservedNames ...string) (*FuncSignature, error) {
+	packageName := opts.packageName
+	if opts.importPath != "" && packageName == "" {
+		packageName = astFile.Name.Name
+	}
+	if opts.importPath == "" {
+		packageName = "" // Same package, nothing to qualify. // 同一个包，不需要限定。
+	}
+
+	genericTypeParams := GetFuncGenericTypeParamsMap(funcDecl)
+	for name, expr := range outerTypeParams {
+		genericTypeParams[name] = expr
+	}
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		for name, expr := range getRecvTypeParamsMap(funcDecl.Recv.List[0].Type) {
+			genericTypeParams[name] = expr
+		}
+	}
+	var fieldLists = []*ast.FieldList{funcDecl.Type.TypeParams, funcDecl.Type.Params, funcDecl.Type.Results}
+	if packageName != "" {
+		if name, ok := findUnexportedTypeName(fieldLists, genericTypeParams); ok {
+			return nil, erero.Errorf("signature of %s refers to %s, which is not exported from package %s", funcDecl.Name.Name, name, packageName)
+		}
+	}
+
+	signature := &FuncSignature{Name: funcDecl.Name.Name}
+	if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+		recvElements := ExtractNameTypeElementsV2(fset, funcDecl.Recv.List[:1], makeForwardNameFunction("recv"), source, packageName, genericTypeParams)
+		signature.Recv = recvElements[0]
+	}
+	if typeParams := funcDecl.Type.TypeParams; typeParams != nil {
+		signature.TypeParams = ExtractNameTypeElementsV2(fset, typeParams.List, makeForwardNameFunction("T"), source, packageName, genericTypeParams)
+	}
+	scope := NewNameScopeFromFunc(astFile, funcDecl).Add(packageName).Add(reservedNames...)
+	for name := range outerTypeParams {
+		scope.Add(name)
+	}
+	signature.Params = NewNameTypeElementsV2(fset, funcDecl.Type.Params, scope.MakeNameFunction("arg"), source, packageName, genericTypeParams)
+	signature.Results = NewNameTypeElementsV2(fset, funcDecl.Type.Results, scope.MakeNameFunction("res"), source, packageName, genericTypeParams)
+	if results := funcDecl.Type.Results; results != nil && len(results.List) > 0 {
+		signature.NamedResults = len(results.List[0].Names) > 0
+	}
+
+	signature.Imports = collectSignatureImports(astFile, fieldLists)
+	if packageName != "" && signature.isQualified(packageName) {
+		importPath := syntaxgo_ast.NewImportPath("", opts.importPath)
+		if packageName != syntaxgo_ast.GuessPackageName(opts.importPath) {
+			importPath.Alias = packageName
+		}
+		signature.Imports = append(signature.Imports, importPath)
+	}
+	slices.SortFunc(signature.Imports, func(a, b *syntaxgo_ast.ImportPath) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return signature, nil
+}
+
+// isQualified reports whether some type of the params, results or type parameters is qualified with the package name,
+// by looking for selectors like `pkg.User` in the parsed types, so `kvstore.Reader` does not count for package "store".
+//
+// isQualified 判断参数、返回值或类型参数中是否有类型被该包名限定，
+// 通过在解析后的类型中查找类似 `pkg.User` 的选择器实现，因此 `kvstore.Reader` 不会被算作包 "store" 的限定。
+func (signature *FuncSignature) isQualified(packageName string) bool {
+	for _, elements := range []NameTypeElements{signature.TypeParams, signature.Params, signature.Results} {
+		for _, element := range elements {
+			typeExpr, err := parser.ParseExpr(strings.TrimPrefix(element.Kind, "..."))
+			if err != nil {
+				continue
+			}
+			var qualified bool
+			ast.Inspect(typeExpr, func(node ast.Node) bool {
+				if selectorExpr, ok := node.(*ast.SelectorExpr); ok {
+					if ident, ok := selectorExpr.X.(*ast.Ident); ok && ident.Name == packageName {
+						qualified = true
+					}
+				}
+				return !qualified
+			})
+			if qualified {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findUnexportedTypeName finds an unexported package-level name, like the type `option`, used by the types in the field lists.
+// Predeclared identifiers, generic type parameters and the names of params, results and fields are skipped.
+//
+// findUnexportedTypeName 查找字段列表的类型中使用的未导出包级名称，比如类型 `option`。
+// 跳过预声明标识符、泛型类型参数以及参数、返回值和字段的名称。
+func findUnexportedTypeName(fieldLists []*ast.FieldList, genericTypeParams map[string]ast.Expr) (string, bool) {
+	var name string
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			return false // Qualified with another package. // 已被其他包限定。
+		case *ast.Field:
+			if x.Type != nil {
+				ast.Inspect(x.Type, inspect) // Skips the names. // 跳过名称。
+			}
+			return false
+		case *ast.Ident:
+			if _, ok := genericTypeParams[x.Name]; !ok && !x.IsExported() && types.Universe.Lookup(x.Name) == nil && name == "" {
+				name = x.Name
+			}
+		}
+		return name == ""
+	}
+	for _, fieldList := range fieldLists {
+		if fieldList != nil {
+			ast.Inspect(fieldList, inspect)
+		}
+	}
+	return name, name != ""
+}
+
+// FormatTypeParams returns the type parameter list, such as "[K comparable, V any]", empty without type parameters.
+// FormatTypeParams 返回类型参数列表，比如 "[K comparable, V any]"，没有类型参数时为空。
+func (signature *FuncSignature) FormatTypeParams() string {
+	if len(signature.TypeParams) == 0 {
+		return ""
+	}
+	return "[" + signature.TypeParams.FormatNamesWithKinds().MergeParts() + "]"
+}
+
+// FormatTypeArgs returns the type arguments to instantiate the function, such as "[K, V]", empty without type parameters.
+// FormatTypeArgs 返回实例化函数的类型实参，比如 "[K, V]"，没有类型参数时为空。
+func (signature *FuncSignature) FormatTypeArgs() string {
+	if len(signature.TypeParams) == 0 {
+		return ""
+	}
+	return "[" + signature.TypeParams.Names().MergeParts() + "]"
+}
+
+// FormatParams returns the param list, such as "name string, opts ...pkg.Option".
+// FormatParams 返回参数列表，比如 "name string, opts ...pkg.Option"。
+func (signature *FuncSignature) FormatParams() string {
+	return signature.Params.FormatNamesWithKinds().MergeParts()
+}
+
+// FormatResults returns the result list, such as "error", "(int, error)" or "(n int, err error)", empty without results.
+// FormatResults 返回返回值列表，比如 "error"、"(int, error)" 或 "(n int, err error)"，没有返回值时为空。
+func (signature *FuncSignature) FormatResults() string {
+	switch {
+	case len(signature.Results) == 0:
+		return ""
+	case signature.NamedResults:
+		return "(" + signature.Results.FormatNamesWithKinds().MergeParts() + ")"
+	case len(signature.Results) == 1:
+		return signature.Results[0].Kind
+	default:
+		return "(" + strings.Join(signature.Results.Kinds(), ", ") + ")"
+	}
+}
+
+// GenerateCall returns the call forwarding the params to the callee, such as `store.Get[T](key, opts...)`.
+// The callee is the function or method expression, like "store.Get" or "client.Get".
+//
+// GenerateCall 返回将参数转发给被调用者的调用，比如 `store.Get[T](key, opts...)`。
+// callee 为函数或方法表达式，比如 "store.Get" 或 "client.Get"。
+func (signature *FuncSignature) GenerateCall(callee string) string {
+	return callee + signature.FormatTypeArgs() + "(" + signature.Params.GenerateFunctionParams().MergeParts() + ")"
+}
+
+// GenerateReturn returns the statement forwarding the call, with "return" when the function has results.
+// GenerateReturn 返回转发调用的语句，函数有返回值时带 "return"。
+func (signature *FuncSignature) GenerateReturn(callee string) string {
+	if len(signature.Results) == 0 {
+		return signature.GenerateCall(callee)
+	}
+	return "return " + signature.GenerateCall(callee)
+}
+
+// GenerateWrapper generates a gofmt-clean function named name with the same signature, forwarding to the callee.
+// Methods of generic types are not supported, since their type parameter constraints are declared on the type.
+//
+// GenerateWrapper 生成一个名为 name、签名相同并转发给被调用者的函数，代码已按 gofmt 格式化。
+// 不支持泛型类型的方法，因为其类型参数的约束声明在类型上。
+func (signature *FuncSignature) GenerateWrapper(name string, callee string) ([]byte, error) {
+	if signature.Recv != nil && len(getRecvTypeParamsMap(signature.Recv.Type)) > 0 {
+		return nil, erero.Errorf("method %s of a generic type is not supported", signature.Name)
+	}
+	ptx := utils.NewPTX()
+	ptx.Println("func " + name + signature.FormatTypeParams() + "(" + signature.FormatParams() + ") " + signature.FormatResults() + " {")
+	ptx.Println("\t" + signature.GenerateReturn(callee))
+	ptx.Println("}")
+	code, err := format.Source(ptx.Bytes())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return code, nil
+}
+
+// makeForwardNameFunction keeps the param names and names the unnamed and "_" params with the prefix and index.
+// makeForwardNameFunction 保留参数名，并用前缀和序号为未命名和 "_" 参数命名。
+func makeForwardNameFunction(prefix string) MakeNameFunction {
+	return func(ident *ast.Ident, kind string, nameIndex int, anonymousIndex int) string {
+		if ident != nil && ident.Name != "" && ident.Name != "_" {
+			return ident.Name
+		}
+		return prefix + strconv.Itoa(nameIndex)
+	}
+}
+
+// getRecvTypeParamsMap returns the type parameter names of a receiver type like `*Box[K, V]`.
+// getRecvTypeParamsMap 返回 `*Box[K, V]` 这样的接收者类型中的类型参数名称。
+func getRecvTypeParamsMap(recvType ast.Expr) map[string]ast.Expr {
+	var nameMap = map[string]ast.Expr{}
+	if starExpr, ok := recvType.(*ast.StarExpr); ok {
+		recvType = starExpr.X
+	}
+	var indices []ast.Expr
+	switch x := recvType.(type) {
+	case *ast.IndexExpr:
+		indices = []ast.Expr{x.Index}
+	case *ast.IndexListExpr:
+		indices = x.Indices
+	}
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			nameMap[ident.Name] = ident
+		}
+	}
+	return nameMap
+}
+
+// collectSignatureImports returns the imports of the file used by the qualified types in the field lists.
+// collectSignatureImports 返回字段列表中带包名的类型所使用的文件导入。
+func collectSignatureImports(astFile *ast.File, fieldLists []*ast.FieldList) []*syntaxgo_ast.ImportPath {
+	var importPaths []*syntaxgo_ast.ImportPath
+	var seen = map[string]bool{}
+	for _, fieldList := range fieldLists {
+		if fieldList == nil {
+			continue
+		}
+		ast.Inspect(fieldList, func(node ast.Node) bool {
+			selectorExpr, ok := node.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok && !seen[ident.Name] {
+				seen[ident.Name] = true
+				if importPath, ok := findImportByName(astFile, ident.Name); ok {
+					importPaths = append(importPaths, importPath)
+				}
+			}
+			return false
+		})
+	}
+	return importPaths
+}
+
+// findImportByName finds the import of the file referred to by the name, keeping its alias.
+// findImportByName 查找文件中以该名称引用的导入，并保留其别名。
+func findImportByName(astFile *ast.File, name string) (*syntaxgo_ast.ImportPath, bool) {
+	for _, importSpec := range astFile.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if importSpec.Name != nil {
+			if importSpec.Name.Name == name {
+				return syntaxgo_ast.NewImportPath(name, path), true
+			}
+			continue
+		}
+		if syntaxgo_ast.GuessPackageName(path) == name {
+			return syntaxgo_ast.NewImportPath("", path), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,141 @@
+package syntaxgo_astnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+	"github.com/yyle88/syntaxgo/syntaxgo_search"
+)
+
+const funcSignatureExampleCode = `package store
+
+import (
+	"context"
+	tm "time"
+)
+
+type Option func(*Config)
+
+type Config struct{}
+
+type Number interface{ ~int | ~int64 }
+
+func Get[K comparable, V Number](ctx context.Context, key K, _ tm.Duration, opts ...Option) (V, bool, error) {
+	panic("x")
+}
+
+func Close(int) {}
+
+func (c *Config) Load(path string) (n int, err error) {
+	return 0, nil
+}
+
+type Box[T any] struct{}
+
+func (b *Box[T]) Put(v T) {}
+`
+
+// TestNewFuncSignature tests generating a forwarding wrapper of a generic variadic function in another package
+// Verifies the names, qualified types, type arguments and imports of the wrapper
+//
+// TestNewFuncSignature 测试在另一个包中为泛型变参函数生成转发包裹函数
+// 验证包裹函数的名称、带包名的类型、类型实参和导入
+func TestNewFuncSignature(t *testing.T) {
+	source := []byte(funcSignatureExampleCode)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "Get")
+	require.NotNil(t, astFunc)
+
+	signature := rese.P1(NewFuncSignature(fset, source, astFile, astFunc, NewFuncSignatureOptions().SetImportPath("example.com/store")))
+	require.Equal(t, "Get", signature.Name)
+	require.Nil(t, signature.Recv)
+	require.Equal(t, "[K comparable, V store.Number]", signature.FormatTypeParams())
+	require.Equal(t, "[K, V]", signature.FormatTypeArgs())
+	require.Equal(t, "ctx context.Context, key K, duration tm.Duration, opts ...store.Option", signature.FormatParams())
+	require.Equal(t, "(V, bool, error)", signature.FormatResults())
+	require.Equal(t, []string{"context", "example.com/store", "time"}, []string{signature.Imports[0].Path, signature.Imports[1].Path, signature.Imports[2].Path})
+	require.Equal(t, "tm", signature.Imports[2].Alias)
+
+	code := rese.V1(signature.GenerateWrapper("GetValue", "store.Get"))
+	t.Log(string(code))
+	require.Equal(t, `func GetValue[K comparable, V store.Number](ctx context.Context, key K, duration tm.Duration, opts ...store.Option) (V, bool, error) {
+	return store.Get[K, V](ctx, key, duration, opts...)
+}
+`, string(code))
+}
+
+// TestNewFuncSignature_SamePackage tests generating wrappers in the same package for a function and methods
+// Verifies unnamed params, named results, results-free calls and the error on generic receivers
+//
+// TestNewFuncSignature_SamePackage 测试在同一个包中为函数和方法生成包裹函数
+// 验证未命名参数、命名返回值、无返回值调用以及泛型接收者的报错
+func TestNewFuncSignature_SamePackage(t *testing.T) {
+	source := []byte(funcSignatureExampleCode)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+
+	closeFunc := syntaxgo_search.FindFunctionByName(astFile, "Close")
+	signature := rese.P1(NewFuncSignature(fset, source, astFile, closeFunc, NewFuncSignatureOptions()))
+	require.Empty(t, signature.Imports)
+	require.Equal(t, "Close(n)", signature.GenerateReturn("Close"))
+	code := rese.V1(signature.GenerateWrapper("CloseQuietly", "Close"))
+	require.Equal(t, "func CloseQuietly(n int) {\n\tClose(n)\n}\n", string(code))
+
+	loadFunc, ok := syntaxgo_search.FindFunctionByReceiverAndName(astFile, "Config", "Load")
+	require.True(t, ok)
+	signature = rese.P1(NewFuncSignature(fset, source, astFile, loadFunc, NewFuncSignatureOptions()))
+	require.Equal(t, "c", signature.Recv.Name)
+	require.Equal(t, "*Config", signature.Recv.Kind)
+	require.Equal(t, "(n int, err error)", signature.FormatResults())
+	code = rese.V1(signature.GenerateWrapper("Load", "defaultConfig.Load"))
+	require.Equal(t, "func Load(path string) (n int, err error) {\n\treturn defaultConfig.Load(path)\n}\n", string(code))
+
+	putFunc, ok := syntaxgo_search.FindFunctionByReceiverAndName(astFile, "Box", "Put")
+	require.True(t, ok)
+	signature = rese.P1(NewFuncSignature(fset, source, astFile, putFunc, NewFuncSignatureOptions()))
+	require.Equal(t, "v T", signature.FormatParams())
+	_, err := signature.GenerateWrapper("Put", "box.Put")
+	require.Error(t, err)
+}
+
+// TestNewFuncSignature_OtherPackage tests signatures for another package with look-alike qualifiers and unexported types
+// Verifies `kvstore.Reader` does not import package store, and unexported types of the package are rejected
+//
+// TestNewFuncSignature_OtherPackage 测试为另一个包创建带相似限定名和未导出类型的签名
+// 验证 `kvstore.Reader` 不会导入 store 包，包中未导出的类型会被拒绝
+func TestNewFuncSignature_OtherPackage(t *testing.T) {
+	const code = `package store
+
+import "example.com/kvstore"
+
+type option struct{}
+
+func Open(reader kvstore.Reader, size int) error { return nil }
+
+func Put(o option) {}
+
+func Each(fn func(key string, o *option)) {}
+`
+	source := []byte(code)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+	opts := NewFuncSignatureOptions().SetImportPath("example.com/store")
+
+	signature := rese.P1(NewFuncSignature(fset, source, astFile, syntaxgo_search.FindFunctionByName(astFile, "Open"), opts))
+	require.Equal(t, "reader kvstore.Reader, size int", signature.FormatParams())
+	require.Len(t, signature.Imports, 1)
+	require.Equal(t, "example.com/kvstore", signature.Imports[0].Path)
+
+	for _, name := range []string{"Put", "Each"} {
+		_, err := NewFuncSignature(fset, source, astFile, syntaxgo_search.FindFunctionByName(astFile, name), opts)
+		require.Error(t, err)
+		t.Log(err)
+	}
+
+	signature = rese.P1(NewFuncSignature(fset, source, astFile, syntaxgo_search.FindFunctionByName(astFile, "Put"), NewFuncSignatureOptions()))
+	require.Equal(t, "o option", signature.FormatParams())
+}
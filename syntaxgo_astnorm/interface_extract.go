@@ -0,0 +1,195 @@
+package syntaxgo_astnorm
+
+import (
+	"go/format"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+	"github.com/yyle88/syntaxgo/syntaxgo_search"
+)
+
+// InterfaceOptions controls the name, the qualification and the assertion of an extracted interface.
+// InterfaceOptions 控制提取出的接口的名称、类型限定和断言。
+type InterfaceOptions struct {
+	interfaceName string // Interface name, receiver name with "Interface" suffix by default / 接口名，默认为接收者名称加 "Interface" 后缀
+	importPath    string // Import path of the source package, empty when the interface is in the same package / 源包的导入路径，接口在同一个包中时为空
+	packageName   string // Name to qualify the types of the source package, the package name by default / 限定源包类型时使用的名称，默认为包名
+	assertion     bool   // Whether to add `var _ XxxInterface = (*Xxx)(nil)` / 是否添加 `var _ XxxInterface = (*Xxx)(nil)`
+}
+
+// NewInterfaceOptions creates options for an interface in the same package as the receiver type, without assertion.
+// NewInterfaceOptions 创建接口与接收者类型在同一个包中且不带断言时的选项。
+func NewInterfaceOptions() *InterfaceOptions {
+	return &InterfaceOptions{}
+}
+
+// SetInterfaceName sets the interface name.
+// SetInterfaceName 设置接口名。
+func (opts *InterfaceOptions) SetInterfaceName(interfaceName string) *InterfaceOptions {
+	opts.interfaceName = interfaceName
+	return opts
+}
+
+// SetImportPath sets the import path of the source package, so exported types of the package get qualified and imported.
+// SetImportPath 设置源包的导入路径，使包中导出的类型被限定并被导入。
+func (opts *InterfaceOptions) SetImportPath(importPath string) *InterfaceOptions {
+	opts.importPath = importPath
+	return opts
+}
+
+// SetPackageName sets the name to qualify the types of the source package.
+// SetPackageName 设置限定源包类型时使用的名称。
+func (opts *InterfaceOptions) SetPackageName(packageName string) *InterfaceOptions {
+	opts.packageName = packageName
+	return opts
+}
+
+// SetAssertion sets whether to add the `var _ XxxInterface = (*Xxx)(nil)` assertion.
+// SetAssertion 设置是否添加 `var _ XxxInterface = (*Xxx)(nil)` 断言。
+func (opts *InterfaceOptions) SetAssertion(assertion bool) *InterfaceOptions {
+	opts.assertion = assertion
+	return opts
+}
+
+// InterfaceMethod is a method of an extracted interface, with its doc comment.
+// InterfaceMethod 是提取出的接口中的方法，带有其文档注释。
+type InterfaceMethod struct {
+	Signature *FuncSignature // Method signature / 方法签名
+	Doc       string         // Doc comment text / 文档注释文本
+}
+
+// InterfaceDecl is an interface extracted from the exported methods of a receiver type.
+// InterfaceDecl 是从接收者类型的导出方法中提取出的接口。
+type InterfaceDecl struct {
+	Name         string                     // Interface name / 接口名
+	ReceiverType string                     // Receiver type, qualified when the interface is in another package / 接收者类型，接口在另一个包中时带包名
+	Methods      []*InterfaceMethod         // Methods in declaration order / 按声明顺序排列的方法
+	Assertion    bool                       // Whether to add the assertion / 是否添加断言
+	Imports      []*syntaxgo_ast.ImportPath // Imports the types need, sorted by path / 类型需要的导入，按路径排序
+}
+
+// ExtractInterface extracts an interface from the exported methods of the receiver type across the files of the package.
+// Generic receiver types are not supported, since their type parameter constraints are declared on the type.
+// Files importing one package under different names, or different packages under one name, are reported as an error.
+//
+// ExtractInterface 从包内所有文件中接收者类型的导出方法提取接口。
+// 不支持泛型接收者类型，因为其类型参数的约束声明在类型上。
+// 文件以不同名称导入同一个包、或以同一名称导入不同的包时会报错。
+func ExtractInterface(pkgBundle *syntaxgo_ast.PackageBundle, receiverName string, opts *InterfaceOptions) (*InterfaceDecl, error) {
+	signatureOptions := NewFuncSignatureOptions().SetImportPath(opts.importPath).SetPackageName(opts.packageName)
+	interfaceDecl := &InterfaceDecl{
+		Name:         opts.interfaceName,
+		ReceiverType: receiverName,
+		Assertion:    opts.assertion,
+	}
+	if interfaceDecl.Name == "" {
+		interfaceDecl.Name = receiverName + "Interface"
+	}
+	if opts.importPath != "" {
+		packageName := opts.packageName
+		if packageName == "" {
+			packageName = pkgBundle.GetPackageName()
+		}
+		interfaceDecl.ReceiverType = packageName + "." + receiverName
+	}
+
+	var importPaths = map[string]*syntaxgo_ast.ImportPath{}
+	for _, astBundle := range pkgBundle.GetAstBundles() {
+		astFile, fset := astBundle.GetBundle()
+		for _, funcDecl := range syntaxgo_search.FindFunctionsByReceiverName(astFile, receiverName, true) {
+			signature, err := NewFuncSignature(fset, astBundle.GetSource(), astFile, funcDecl, signatureOptions)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			if len(getRecvTypeParamsMap(signature.Recv.Type)) > 0 {
+				return nil, erero.Errorf("generic receiver type %s is not supported", receiverName)
+			}
+			interfaceDecl.Methods = append(interfaceDecl.Methods, &InterfaceMethod{
+				Signature: signature,
+				Doc:       strings.TrimSpace(funcDecl.Doc.Text()),
+			})
+			for _, importPath := range signature.Imports {
+				if err := addImportPath(importPaths, importPath); err != nil {
+					return nil, erero.Wro(err)
+				}
+			}
+		}
+	}
+	if len(interfaceDecl.Methods) == 0 {
+		return nil, erero.Errorf("no exported methods of %s", receiverName)
+	}
+	if opts.assertion && opts.importPath != "" {
+		// The receiver type is qualified in the assertion. // 断言中的接收者类型带包名。
+		importPath := syntaxgo_ast.NewImportPath("", opts.importPath)
+		if opts.packageName != "" && opts.packageName != syntaxgo_ast.GuessPackageName(opts.importPath) {
+			importPath.Alias = opts.packageName
+		}
+		if err := addImportPath(importPaths, importPath); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	for _, importPath := range importPaths {
+		interfaceDecl.Imports = append(interfaceDecl.Imports, importPath)
+	}
+	slices.SortFunc(interfaceDecl.Imports, func(a, b *syntaxgo_ast.ImportPath) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return interfaceDecl, nil
+}
+
+// Generate generates the gofmt-clean interface declaration, followed by the assertion when enabled.
+// Generate 生成按 gofmt 格式化的接口声明，启用断言时后面跟着断言。
+func (interfaceDecl *InterfaceDecl) Generate() ([]byte, error) {
+	ptx := utils.NewPTX()
+	ptx.Println("type " + interfaceDecl.Name + " interface {")
+	for _, method := range interfaceDecl.Methods {
+		if method.Doc != "" {
+			for _, line := range strings.Split(method.Doc, "\n") {
+				ptx.Println(strings.TrimRight("\t// "+line, " "))
+			}
+		}
+		signature := method.Signature
+		ptx.Println("\t" + signature.Name + "(" + signature.FormatParams() + ") " + signature.FormatResults())
+	}
+	ptx.Println("}")
+	if interfaceDecl.Assertion {
+		ptx.Println()
+		ptx.Println("var _ " + interfaceDecl.Name + " = (*" + interfaceDecl.ReceiverType + ")(nil)")
+	}
+	code, err := format.Source(ptx.Bytes())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return code, nil
+}
+
+// addImportPath adds the import to the imports keyed by path. The generated code has one import block for the types of many files,
+// so it returns an error when the path is imported with another name, or another path is imported with the same name.
+//
+// addImportPath 将导入加入以路径为键的导入集合。生成的代码只有一个导入块来容纳多个文件中的类型，
+// 因此当该路径以其他名称导入、或其他路径以相同名称导入时返回错误。
+func addImportPath(importPaths map[string]*syntaxgo_ast.ImportPath, importPath *syntaxgo_ast.ImportPath) error {
+	name := getImportName(importPath)
+	for _, existing := range importPaths {
+		if existing.Path == importPath.Path && getImportName(existing) != name {
+			return erero.Errorf("package %s is imported as both %s and %s", importPath.Path, getImportName(existing), name)
+		}
+		if existing.Path != importPath.Path && getImportName(existing) == name {
+			return erero.Errorf("name %s refers to both package %s and package %s", name, existing.Path, importPath.Path)
+		}
+	}
+	importPaths[importPath.Path] = importPath
+	return nil
+}
+
+// getImportName returns the name the code refers to the import with, the alias or the guessed package name.
+// getImportName 返回代码引用该导入时使用的名称，即别名或推测的包名。
+func getImportName(importPath *syntaxgo_ast.ImportPath) string {
+	if importPath.Alias != "" {
+		return importPath.Alias
+	}
+	return syntaxgo_ast.GuessPackageName(importPath.Path)
+}
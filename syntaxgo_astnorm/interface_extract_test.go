@@ -0,0 +1,128 @@
+package syntaxgo_astnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const interfaceServiceCode = `package service
+
+import "context"
+
+type User struct{}
+
+type Service struct{}
+
+// Find finds the user.
+// It returns nil when missing.
+func (s *Service) Find(ctx context.Context, id int) (*User, error) {
+	return nil, nil
+}
+
+func (s *Service) helper() {}
+`
+
+const interfaceServiceMoreCode = `package service
+
+import "time"
+
+func (s Service) Touch(at time.Time, names ...string) {}
+
+type Box[T any] struct{}
+
+func (b *Box[T]) Get() T {
+	var v T
+	return v
+}
+`
+
+// newInterfacePackage writes a package of two files into a temp module and loads it.
+// newInterfacePackage 将包含两个文件的包写入临时模块并加载。
+func newInterfacePackage(t *testing.T) *syntaxgo_ast.PackageBundle {
+	root := tests.NewTempModule(t, "example.com/service", map[string]string{
+		"find.go":  interfaceServiceCode,
+		"touch.go": interfaceServiceMoreCode,
+	})
+	return rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+}
+
+// TestExtractInterface tests extracting an interface in the same package from methods across files
+// Verifies only exported methods are kept with their doc comments
+//
+// TestExtractInterface 测试在同一个包中从多个文件的方法提取接口
+// 验证只保留导出的方法及其文档注释
+func TestExtractInterface(t *testing.T) {
+	pkgBundle := newInterfacePackage(t)
+
+	interfaceDecl := rese.P1(ExtractInterface(pkgBundle, "Service", NewInterfaceOptions()))
+	require.Equal(t, "ServiceInterface", interfaceDecl.Name)
+	require.Len(t, interfaceDecl.Methods, 2)
+	require.Equal(t, []string{"context", "time"}, []string{interfaceDecl.Imports[0].Path, interfaceDecl.Imports[1].Path})
+
+	code := rese.V1(interfaceDecl.Generate())
+	t.Log(string(code))
+	require.Equal(t, `type ServiceInterface interface {
+	// Find finds the user.
+	// It returns nil when missing.
+	Find(ctx context.Context, id int) (*User, error)
+	Touch(at time.Time, names ...string)
+}
+`, string(code))
+}
+
+// TestExtractInterface_Assertion tests extracting an interface into another package with the assertion
+// Verifies the types and the receiver type are qualified and the package is imported
+//
+// TestExtractInterface_Assertion 测试带断言地将接口提取到另一个包中
+// 验证类型和接收者类型都带包名，并导入该包
+func TestExtractInterface_Assertion(t *testing.T) {
+	pkgBundle := newInterfacePackage(t)
+
+	options := NewInterfaceOptions().SetInterfaceName("UserService").SetImportPath("example.com/service").SetAssertion(true)
+	interfaceDecl := rese.P1(ExtractInterface(pkgBundle, "Service", options))
+	require.Equal(t, []string{"context", "example.com/service", "time"}, []string{interfaceDecl.Imports[0].Path, interfaceDecl.Imports[1].Path, interfaceDecl.Imports[2].Path})
+
+	code := rese.V1(interfaceDecl.Generate())
+	t.Log(string(code))
+	require.Equal(t, `type UserService interface {
+	// Find finds the user.
+	// It returns nil when missing.
+	Find(ctx context.Context, id int) (*service.User, error)
+	Touch(at time.Time, names ...string)
+}
+
+var _ UserService = (*service.Service)(nil)
+`, string(code))
+
+	_, err := ExtractInterface(pkgBundle, "Box", NewInterfaceOptions())
+	require.Error(t, err)
+	_, err = ExtractInterface(pkgBundle, "User", NewInterfaceOptions())
+	require.Error(t, err)
+}
+
+// TestExtractInterface_ImportConflicts tests extracting from files importing a package under different names
+// Verifies an error is returned for one path with two names and for one name with two paths
+//
+// TestExtractInterface_ImportConflicts 测试从以不同名称导入同一个包的文件中提取接口
+// 验证同一路径有两个名称、同一名称对应两个路径时都返回错误
+func TestExtractInterface_ImportConflicts(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/service", map[string]string{
+		"a.go": "package service\n\nimport tm \"time\"\n\ntype Service struct{}\n\nfunc (s *Service) Wait(d tm.Duration) {}\n",
+		"b.go": "package service\n\nimport \"time\"\n\nfunc (s *Service) Touch(at time.Time) {}\n",
+	})
+	_, err := ExtractInterface(rese.P1(syntaxgo_ast.NewPackageBundleV1(root)), "Service", NewInterfaceOptions())
+	require.Error(t, err)
+	t.Log(err)
+
+	root = tests.NewTempModule(t, "example.com/service", map[string]string{
+		"a.go": "package service\n\nimport \"text/template\"\n\ntype Service struct{}\n\nfunc (s *Service) Text(t *template.Template) {}\n",
+		"b.go": "package service\n\nimport \"html/template\"\n\nfunc (s *Service) HTML(t *template.Template) {}\n",
+	})
+	_, err = ExtractInterface(rese.P1(syntaxgo_ast.NewPackageBundleV1(root)), "Service", NewInterfaceOptions())
+	require.Error(t, err)
+	t.Log(err)
+}
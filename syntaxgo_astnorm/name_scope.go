@@ -0,0 +1,252 @@
+package syntaxgo_astnorm
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// NameScope holds the names in scope, such as import names, the receiver name and type parameter names.
+// Names made through the scope never collide with the names in scope, Go keywords, predeclared identifiers or each other.
+//
+// NameScope 保存作用域内的名称，比如导入名、接收者名和类型参数名。
+// 通过作用域生成的名称不会与作用域内的名称、Go 关键字、预声明标识符以及彼此冲突。
+type NameScope struct {
+	names    map[string]bool // Names in scope / 作用域内的名称
+	declared map[string]bool // Declared field names reserved for their own fields / 为其自身字段保留的已声明字段名
+}
+
+// NewNameScope creates a scope with the names.
+// NewNameScope 创建包含这些名称的作用域。
+func NewNameScope(names ...string) *NameScope {
+	return (&NameScope{names: map[string]bool{}, declared: map[string]bool{}}).Add(names...)
+}
+
+// NewNameScopeFromFunc creates a scope with the import names of the file, the receiver name and the type parameter names of the function,
+// with the declared param and result names reserved.
+//
+// NewNameScopeFromFunc 创建包含文件的导入名、函数的接收者名和类型参数名的作用域，
+// 并保留已声明的参数名和返回值名。
+func NewNameScopeFromFunc(astFile *ast.File, funcDecl *ast.FuncDecl) *NameScope {
+	scope := NewNameScope()
+	for _, importSpec := range astFile.Imports {
+		if importSpec.Name != nil {
+			scope.Add(importSpec.Name.Name)
+		} else if path, err := strconv.Unquote(importSpec.Path.Value); err == nil {
+			scope.Add(syntaxgo_ast.GuessPackageName(path))
+		}
+	}
+	if funcDecl.Recv != nil {
+		for _, field := range funcDecl.Recv.List {
+			for _, name := range field.Names {
+				scope.Add(name.Name)
+			}
+			for name := range getRecvTypeParamsMap(field.Type) {
+				scope.Add(name)
+			}
+		}
+	}
+	for name := range GetFuncGenericTypeParamsMap(funcDecl) {
+		scope.Add(name)
+	}
+	return scope.Reserve(funcDecl.Type.Params, funcDecl.Type.Results)
+}
+
+// Reserve reserves the declared names of the fields that are free to use, so they are kept by MakeNameFunction
+// even when a field before them is named from its type, like `user` in `func F(_ *User, user string)`.
+// Declared names taken by the names in scope, even those added later, are not kept, these fields get new names.
+//
+// Reserve 保留字段中可用的已声明名称，使 MakeNameFunction 保留这些名称，
+// 即使其前面的字段根据类型命名，比如 `func F(_ *User, user string)` 中的 `user`。
+// 已被作用域内名称占用的声明名称（包括之后添加的名称）不会被保留，这些字段会得到新的名称。
+func (scope *NameScope) Reserve(fieldLists ...*ast.FieldList) *NameScope {
+	for _, fieldList := range fieldLists {
+		if fieldList == nil {
+			continue
+		}
+		for _, field := range fieldList.List {
+			for _, name := range field.Names {
+				if name.Name != "_" && scope.isFree(name.Name) {
+					scope.declared[name.Name] = true
+				}
+			}
+		}
+	}
+	return scope
+}
+
+// Add adds the names to the scope, "_" and "." are skipped.
+// Add 将这些名称加入作用域，跳过 "_" 和 "."。
+func (scope *NameScope) Add(names ...string) *NameScope {
+	for _, name := range names {
+		if name != "" && name != "_" && name != "." {
+			scope.names[name] = true
+		}
+	}
+	return scope
+}
+
+// Contains reports whether the name is in scope.
+// Contains 判断名称是否在作用域内。
+func (scope *NameScope) Contains(name string) bool {
+	return scope.names[name]
+}
+
+// Unique returns the base name, or the base name with the smallest number suffix, that is free to use, and adds it to the scope.
+// Unique 返回可用的基础名称，或带最小数字后缀的基础名称，并将其加入作用域。
+func (scope *NameScope) Unique(base string) string {
+	name := base
+	for index := 1; !scope.isUnused(name); index++ {
+		name = base + strconv.Itoa(index)
+	}
+	scope.Add(name)
+	return name
+}
+
+// isFree reports whether the name is not in scope and not a keyword or predeclared identifier.
+// isFree 判断名称不在作用域内，且不是关键字或预声明标识符。
+func (scope *NameScope) isFree(name string) bool {
+	return !scope.names[name] && !token.IsKeyword(name) && types.Universe.Lookup(name) == nil
+}
+
+// isUnused reports whether the name is free to use and not reserved for a declared field.
+// isUnused 判断名称可用且未被保留给已声明的字段。
+func (scope *NameScope) isUnused(name string) bool {
+	return scope.isFree(name) && !scope.declared[name]
+}
+
+// MakeNameFunction returns a function keeping the declared names free to use, and naming the others from their types.
+// Names reserved through Reserve are kept for their own fields and never derived for the others.
+// Unnamed and "_" fields get names like ctx, user and users, falling back to the prefix when no name fits the type.
+//
+// MakeNameFunction 返回一个函数，保留可用的声明名称，并根据类型为其余的命名。
+// 通过 Reserve 保留的名称留给其自身的字段，不会推导给其他字段。
+// 未命名和 "_" 字段得到 ctx、user 和 users 这样的名称，类型没有合适的名称时使用前缀。
+func (scope *NameScope) MakeNameFunction(prefix string) MakeNameFunction {
+	return func(ident *ast.Ident, kind string, nameIndex int, anonymousIndex int) string {
+		if ident != nil && ident.Name != "" && ident.Name != "_" && scope.isFree(ident.Name) {
+			delete(scope.declared, ident.Name) // Taken by its own field now. // 现在由其自身字段占用。
+			return scope.Unique(ident.Name)
+		}
+		if name := DeriveNameFromType(kind); name != "" {
+			return scope.Unique(name)
+		}
+		return scope.Unique(prefix)
+	}
+}
+
+// DeriveNameFromType derives a variable name from the type source text, such as
+// ctx for context.Context, user for *User, users for []User and err for error.
+// It returns empty when the type text cannot be parsed.
+//
+// DeriveNameFromType 根据类型的源码文本推导变量名，比如
+// context.Context 得到 ctx，*User 得到 user，[]User 得到 users，error 得到 err。
+// 类型文本无法解析时返回空。
+func DeriveNameFromType(kind string) string {
+	expr, err := parser.ParseExpr(strings.TrimPrefix(kind, "..."))
+	if err != nil {
+		return ""
+	}
+	if strings.HasPrefix(kind, "...") {
+		return pluralizeName(deriveNameFromExpr(expr))
+	}
+	return deriveNameFromExpr(expr)
+}
+
+// deriveNameFromExpr derives a variable name from the type expression.
+// deriveNameFromExpr 根据类型表达式推导变量名。
+func deriveNameFromExpr(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return deriveNameFromTypeName(x.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok && pkg.Name == "context" && x.Sel.Name == "Context" {
+			return "ctx"
+		}
+		return lowerCamelName(x.Sel.Name)
+	case *ast.StarExpr:
+		return deriveNameFromExpr(x.X)
+	case *ast.ParenExpr:
+		return deriveNameFromExpr(x.X)
+	case *ast.IndexExpr:
+		return deriveNameFromExpr(x.X)
+	case *ast.IndexListExpr:
+		return deriveNameFromExpr(x.X)
+	case *ast.ArrayType:
+		if elt, ok := x.Elt.(*ast.Ident); ok && elt.Name == "byte" {
+			return "data"
+		}
+		return pluralizeName(deriveNameFromExpr(x.Elt))
+	case *ast.Ellipsis:
+		return pluralizeName(deriveNameFromExpr(x.Elt))
+	case *ast.MapType:
+		return deriveNameFromExpr(x.Value) + "Map"
+	case *ast.ChanType:
+		return deriveNameFromExpr(x.Value) + "Ch"
+	case *ast.FuncType:
+		return "fn"
+	case *ast.InterfaceType:
+		return "v"
+	default:
+		return "value"
+	}
+}
+
+// deriveNameFromTypeName derives a variable name from a type name, with short names for predeclared types.
+// deriveNameFromTypeName 根据类型名推导变量名，预声明类型使用短名称。
+func deriveNameFromTypeName(typeName string) string {
+	switch typeName {
+	case "error":
+		return "err"
+	case "string":
+		return "s"
+	case "bool":
+		return "ok"
+	case "byte":
+		return "b"
+	case "rune":
+		return "r"
+	case "any":
+		return "v"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr":
+		return "n"
+	case "float32", "float64":
+		return "f"
+	case "complex64", "complex128":
+		return "c"
+	}
+	return lowerCamelName(typeName)
+}
+
+// lowerCamelName lowers the leading upper case letters, such as "user" for "User" and "httpClient" for "HTTPClient".
+// lowerCamelName 将开头的大写字母转为小写，比如 "User" 得到 "user"，"HTTPClient" 得到 "httpClient"。
+func lowerCamelName(name string) string {
+	runes := []rune(name)
+	for index := 0; index < len(runes) && unicode.IsUpper(runes[index]); index++ {
+		if index > 0 && index+1 < len(runes) && unicode.IsLower(runes[index+1]) {
+			break // Keeps the first letter of the next word. // 保留下一个单词的首字母。
+		}
+		runes[index] = unicode.ToLower(runes[index])
+	}
+	return string(runes)
+}
+
+// pluralizeName returns the plural name, "values" for names of one letter.
+// pluralizeName 返回复数名称，单字母名称得到 "values"。
+func pluralizeName(name string) string {
+	if len(name) <= 1 {
+		return "values"
+	}
+	for _, suffix := range []string{"s", "x", "ch", "sh"} {
+		if strings.HasSuffix(name, suffix) {
+			return name + "es"
+		}
+	}
+	return name + "s"
+}
@@ -0,0 +1,109 @@
+package syntaxgo_astnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+	"github.com/yyle88/syntaxgo/syntaxgo_search"
+)
+
+// TestDeriveNameFromType tests deriving variable names from type source texts
+// Verifies named, pointer, slice, variadic, map and predeclared types
+//
+// TestDeriveNameFromType 测试根据类型源码文本推导变量名
+// 验证命名类型、指针、切片、变参、映射和预声明类型
+func TestDeriveNameFromType(t *testing.T) {
+	testCases := map[string]string{
+		"context.Context":      "ctx",
+		"*User":                "user",
+		"[]User":               "users",
+		"[]*pkg.Address":       "addresses",
+		"...Option":            "options",
+		"map[string]*User":     "userMap",
+		"chan Event":           "eventCh",
+		"*HTTPClient":          "httpClient",
+		"ID":                   "id",
+		"List[User]":           "list",
+		"error":                "err",
+		"string":               "s",
+		"[]string":             "values",
+		"[]byte":               "data",
+		"func(int) error":      "fn",
+		"interface{ Close() }": "v",
+		"T":                    "t",
+		"struct{}":             "value",
+		"[":                    "",
+	}
+	for kind, expected := range testCases {
+		require.Equal(t, expected, DeriveNameFromType(kind), kind)
+	}
+}
+
+// TestNameScope_MakeNameFunction tests naming params with the imports, receiver and type parameters in scope
+// Verifies colliding names are replaced and generated names are unique
+//
+// TestNameScope_MakeNameFunction 测试在导入、接收者和类型参数处于作用域内时为参数命名
+// 验证冲突的名称会被替换，且生成的名称唯一
+func TestNameScope_MakeNameFunction(t *testing.T) {
+	source := []byte(`package example
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type User struct{}
+
+type Service[T any] struct{}
+
+func (user *Service[T]) Save(_ context.Context, errors string, time time.Duration, _ *User, _ []User, t T, _ Type, _ error, _ error) {}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "Save")
+	require.NotNil(t, astFunc)
+
+	scope := NewNameScopeFromFunc(astFile, astFunc)
+	require.True(t, scope.Contains("errors"))
+	require.True(t, scope.Contains("user"))
+	require.True(t, scope.Contains("T"))
+
+	params := NewNameTypeElementsV2(fset, astFunc.Type.Params, scope.MakeNameFunction("arg"), source, "", nil)
+	require.Equal(t, []string{"ctx", "s", "duration", "user1", "users", "t", "type1", "err", "err1"}, []string(params.Names()))
+
+	require.Equal(t, "arg", NewNameScope().Unique("arg"))
+	require.Equal(t, "res2", NewNameScope("res", "res1").Unique("res"))
+}
+
+// TestNameScope_Reserve tests naming unnamed params before declared params with the names derived for them
+// Verifies the declared names are kept and the derived names avoid them
+//
+// TestNameScope_Reserve 测试在已声明参数之前为未命名参数命名，且推导出的名称与已声明名称相同
+// 验证保留已声明的名称，推导出的名称会避开它们
+func TestNameScope_Reserve(t *testing.T) {
+	source := []byte(`package example
+
+import "context"
+
+type User struct{}
+
+func F(_ *User, user string, _ context.Context, ctx int) (_ error, err error) {}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "F")
+	require.NotNil(t, astFunc)
+
+	scope := NewNameScopeFromFunc(astFile, astFunc)
+	params := NewNameTypeElementsV2(fset, astFunc.Type.Params, scope.MakeNameFunction("arg"), source, "", nil)
+	require.Equal(t, []string{"user1", "user", "ctx1", "ctx"}, []string(params.Names()))
+	results := NewNameTypeElementsV2(fset, astFunc.Type.Results, scope.MakeNameFunction("res"), source, "", nil)
+	require.Equal(t, []string{"err1", "err"}, []string(results.Names()))
+
+	scope = NewNameScope("user").Reserve(astFunc.Type.Params)
+	params = NewNameTypeElementsV2(fset, astFunc.Type.Params, scope.MakeNameFunction("arg"), source, "", nil)
+	require.Equal(t, []string{"user1", "s", "ctx1", "ctx"}, []string(params.Names()))
+}
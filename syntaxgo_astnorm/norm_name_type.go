@@ -9,6 +9,10 @@ package syntaxgo_astnorm
 
 import (
 	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
 	"strings"
 
 	"github.com/yyle88/must"
@@ -19,10 +23,11 @@ import (
 // NameTypeElement represents a single element with a name, type, and associated information.
 // NameTypeElement 代表一个包含名称、类型和相关信息的元素
 type NameTypeElement struct {
-	Name       string   // Field name / 字段名称
-	Kind       string   // Type description (e.g., int, string, A, utils.A, *B, *pkg.B) / 类型描述 (例如: int, string, A, utils.A, *B, *pkg.B)
-	Type       ast.Expr // Go's type representation / Go 的类型表示
-	IsEllipsis bool     // Indicates if the type is a variadic (e.g., ...int, ...string) / 是否是变参类型 (例如: ...int, ...string)
+	Name       string     // Field name / 字段名称
+	Kind       string     // Type description (e.g., int, string, A, utils.A, *B, *pkg.B) / 类型描述 (例如: int, string, A, utils.A, *B, *pkg.B)
+	Type       ast.Expr   // Go's type representation / Go 的类型表示
+	IsEllipsis bool       // Indicates if the type is a variadic (e.g., ...int, ...string) / 是否是变参类型 (例如: ...int, ...string)
+	GoType     types.Type // Resolved type, set by ResolveGoType when type checking is on (variadic becomes a slice) / 解析后的类型，开启类型检查时由 ResolveGoType 设置 (变参为切片)
 }
 
 // NewNameTypeElement creates a new NameTypeElement with parsed source code info and normalized data
@@ -63,6 +68,27 @@ func (element *NameTypeElement) AdjustTypeWithPackage(
 	element.Kind = shortKind
 }
 
+// ResolveGoType sets GoType using the types info of a type-checked bundle, and returns it.
+// ResolveGoType 使用已类型检查的 bundle 的类型信息设置 GoType，并返回它。
+func (element *NameTypeElement) ResolveGoType(info *types.Info) types.Type {
+	if ellipsis, ok := element.Type.(*ast.Ellipsis); ok {
+		if elemType := info.TypeOf(ellipsis.Elt); elemType != nil {
+			element.GoType = types.NewSlice(elemType)
+		}
+	} else {
+		element.GoType = info.TypeOf(element.Type)
+	}
+	return element.GoType
+}
+
+// adjustKindWithPackage qualifies the exported type names in the type text with the package name, such as
+// "map[string]*User" to "map[string]*pkg.User", walking the parsed expression so that names nested in
+// composite, function, channel, map and generic instantiation types are qualified too.
+// Names already qualified, generic type parameters, and names of params, results and fields are kept.
+//
+// adjustKindWithPackage 使用包名限定类型文本中的导出类型名，比如将 "map[string]*User" 变为 "map[string]*pkg.User"，
+// 通过遍历解析后的表达式，嵌套在复合、函数、通道、map 和泛型实例化类型中的名称也会被限定。
+// 已带包名的名称、泛型类型参数以及参数、返回值和字段的名称保持不变。
 func adjustKindWithPackage(shortKind string, packageName string, genericTypeParams map[string]ast.Expr, isVariadic bool) (string, bool) {
 	if isVariadic {
 		must.True(strings.HasPrefix(shortKind, "..."))
@@ -70,31 +96,25 @@ func adjustKindWithPackage(shortKind string, packageName string, genericTypePara
 		shortKind = strings.TrimSpace(shortKind)
 	}
 
-	if strings.Contains(shortKind, ".") {
-		return "", false // contains package name / 已经包含包名
+	typeExpr, err := parser.ParseExpr(shortKind)
+	if err != nil {
+		return "", false // not a type expression / 不是类型表达式
 	}
+	var offsets []int
+	collectQualifiedOffsets(typeExpr, genericTypeParams, &offsets)
+	if len(offsets) == 0 {
+		return "", false // basic-type(int string float64) || not-exportable-type || contains package name
+	}
+	slices.Sort(offsets)
 
-	if s := shortKind[0]; string(s) == "*" {
-		if vcc := shortKind[1]; vcc >= 'A' && vcc <= 'Z' {
-			className := shortKind[1:]
-			if _, ok := genericTypeParams[className]; ok {
-				return "", false // It's a generic type / 是泛型类型
-			}
-			shortKind = "*" + packageName + "." + className
-		} else {
-			return "", false // basic-type(int string float64) || // not-exportable-type
-		}
-	} else {
-		if s := shortKind[0]; s >= 'A' && s <= 'Z' {
-			className := shortKind
-			if _, ok := genericTypeParams[className]; ok {
-				return "", false // It's a generic type / 是泛型类型
-			}
-			shortKind = packageName + "." + className
-		} else {
-			return "", false // basic-type(int string float64) || // not-exportable-type
-		}
+	var ptx = utils.NewPTX()
+	var previous = 0
+	for _, offset := range offsets {
+		ptx.Print(shortKind[previous:offset], packageName, ".")
+		previous = offset
 	}
+	ptx.Print(shortKind[previous:])
+	shortKind = ptx.String()
 
 	if isVariadic {
 		shortKind = "..." + shortKind
@@ -102,6 +122,29 @@ func adjustKindWithPackage(shortKind string, packageName string, genericTypePara
 	return shortKind, true
 }
 
+// collectQualifiedOffsets collects the offsets of the exported type names to qualify in the expression parsed by parser.ParseExpr.
+// collectQualifiedOffsets 收集由 parser.ParseExpr 解析的表达式中需要限定的导出类型名的偏移量。
+func collectQualifiedOffsets(typeExpr ast.Expr, genericTypeParams map[string]ast.Expr, offsets *[]int) {
+	ast.Inspect(typeExpr, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			return false // contains package name / 已经包含包名
+		case *ast.Field:
+			collectQualifiedOffsets(x.Type, genericTypeParams, offsets) // skip the names / 跳过名称
+			return false
+		case *ast.Ident:
+			if !x.IsExported() {
+				return false // basic-type(int string float64) || not-exportable-type
+			}
+			if _, ok := genericTypeParams[x.Name]; ok {
+				return false // It's a generic type / 是泛型类型
+			}
+			*offsets = append(*offsets, int(x.Pos())-1) // positions of parser.ParseExpr start at 1 / parser.ParseExpr 的位置从 1 开始
+		}
+		return true
+	})
+}
+
 // MakeNameFunction generates a name when processing function params and return values.
 // MakeNameFunction 用于为参数或返回值生成名称。
 type MakeNameFunction func(name *ast.Ident, kind string, idx int, anonymousIdx int) string
@@ -118,11 +161,24 @@ func NewNameTypeElements(
 	source []byte, // Source code to extract information / 用于提取信息的源代码
 	packageName string, // Package name when using outside types / 外部类型的包名
 	genericTypeParams map[string]ast.Expr, // Map of generic type params / 泛型类型参数的映射
+) NameTypeElements {
+	return NewNameTypeElementsV2(nil, fieldList, nameFunc, source, packageName, genericTypeParams)
+}
+
+// NewNameTypeElementsV2 creates a list of NameTypeElements based on AST field list, resolving offsets through the FileSet.
+// NewNameTypeElementsV2 根据 AST 字段列表创建 NameTypeElements 的列表，通过 FileSet 计算偏移量。
+func NewNameTypeElementsV2(
+	fset *token.FileSet, // FileSet holding the field positions / 包含字段位置的文件集
+	fieldList *ast.FieldList, // AST field list with function params / AST 字段列表，表示函数参数
+	nameFunc MakeNameFunction, // Function to generate param names / 用于生成参数名称的函数
+	source []byte, // Source code to extract information / 用于提取信息的源代码
+	packageName string, // Package name when using outside types / 外部类型的包名
+	genericTypeParams map[string]ast.Expr, // Map of generic type params / 泛型类型参数的映射
 ) NameTypeElements {
 	if fieldList == nil {
 		return make(NameTypeElements, 0) // No fields, new list / 返回一个空列表
 	}
-	return ExtractNameTypeElements(fieldList.List, nameFunc, source, packageName, genericTypeParams)
+	return ExtractNameTypeElementsV2(fset, fieldList.List, nameFunc, source, packageName, genericTypeParams)
 }
 
 // ExtractNameTypeElements extracts NameTypeElements from the AST fields.
@@ -133,6 +189,19 @@ func ExtractNameTypeElements(
 	source []byte, // Source code / 源代码
 	packageName string, // Package name / 包名
 	genericTypeParams map[string]ast.Expr, // Map of generic type params / 泛型类型参数的映射
+) NameTypeElements {
+	return ExtractNameTypeElementsV2(nil, fields, nameFunc, source, packageName, genericTypeParams)
+}
+
+// ExtractNameTypeElementsV2 extracts NameTypeElements from the AST fields, resolving offsets through the FileSet.
+// ExtractNameTypeElementsV2 从 AST 字段中提取 NameTypeElements，通过 FileSet 计算偏移量。
+func ExtractNameTypeElementsV2(
+	fset *token.FileSet, // FileSet holding the field positions / 包含字段位置的文件集
+	fields []*ast.Field, // List of AST fields / AST 字段列表
+	nameFunc MakeNameFunction, // Function to generate names / 用于生成名称的函数
+	source []byte, // Source code / 源代码
+	packageName string, // Package name / 包名
+	genericTypeParams map[string]ast.Expr, // Map of generic type params / 泛型类型参数的映射
 ) NameTypeElements {
 	var elements = make(NameTypeElements, 0) // New elements list / 创建一个空的元素列表
 	var anonymousCount = 0                   // Count of anonymous fields / 匿名字段计数器
@@ -140,10 +209,10 @@ func ExtractNameTypeElements(
 		var stringType string
 		var isVariadic bool
 		if ellipsis, ok := field.Type.(*ast.Ellipsis); ok {
-			stringType = string(source[ellipsis.Ellipsis-1 : field.Type.End()-1]) // Extract ellipsis type / 提取变参类型
+			stringType = string(syntaxgo_astnode.GetCodeV2(fset, source, ellipsis)) // Extract ellipsis type / 提取变参类型
 			isVariadic = true
 		} else {
-			stringType = strings.TrimSpace(string(syntaxgo_astnode.GetCode(source, field.Type))) // Extract normal type / 提取常规类型
+			stringType = strings.TrimSpace(string(syntaxgo_astnode.GetCodeV2(fset, source, field.Type))) // Extract normal type / 提取常规类型
 		}
 		if len(field.Names) > 0 { // Params have names, but returns often don't / 参数有名称，但返回值通常没有
 			for _, fieldName := range field.Names {
@@ -183,6 +252,25 @@ func (elements NameTypeElements) Kinds() []string {
 	return kinds
 }
 
+// ResolveGoTypes sets GoType of each element using the types info of a type-checked bundle.
+// ResolveGoTypes 使用已类型检查的 bundle 的类型信息设置每个元素的 GoType。
+func (elements NameTypeElements) ResolveGoTypes(info *types.Info) NameTypeElements {
+	for _, element := range elements {
+		element.ResolveGoType(info)
+	}
+	return elements
+}
+
+// GoTypes returns the resolved types of the elements, nil where not resolved.
+// GoTypes 返回元素解析后的类型，未解析的位置为 nil。
+func (elements NameTypeElements) GoTypes() []types.Type {
+	var goTypes = make([]types.Type, 0, len(elements))
+	for _, element := range elements {
+		goTypes = append(goTypes, element.GoType)
+	}
+	return goTypes
+}
+
 // FormatAddressableNames returns the names prefixed with "&" (addressable names).
 // FormatAddressableNames 返回带有 "&" 前缀的名称（可寻址名称）。
 func (elements NameTypeElements) FormatAddressableNames() StatementParts {
@@ -1 +1,98 @@
 package syntaxgo_astnorm
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+	"github.com/yyle88/syntaxgo/syntaxgo_search"
+)
+
+// TestExtractNameTypeElementsV2 tests extracting elements from the second file of a shared FileSet
+// Verifies param types are cut from the right offsets, including variadic params
+//
+// TestExtractNameTypeElementsV2 测试从共享 FileSet 中第二个文件提取元素
+// 验证参数类型从正确的偏移量截取，包括变参
+func TestExtractNameTypeElementsV2(t *testing.T) {
+	fset := token.NewFileSet()
+	rese.P1(syntaxgo_ast.NewAstBundleV2(fset, []byte("package a\n\nfunc A(x int) {}\n")))
+
+	source := []byte("package b\n\nfunc B(name string, args ...any) (User, error) {}\n")
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV2(fset, source))
+	astFile, _ := astBundle.GetBundle()
+
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "B")
+	require.NotNil(t, astFunc)
+
+	params := NewNameTypeElementsV2(fset, astFunc.Type.Params, SimpleMakeNameFunction("arg"), source, "", nil)
+	require.Equal(t, []string{"string", "...any"}, params.Kinds())
+
+	results := GetSimpleResElementsV2(fset, astFunc.Type.Results.List, source)
+	require.Equal(t, []string{"res", "err1"}, []string(results.Names()))
+	require.Equal(t, []string{"User", "error"}, results.Kinds())
+}
+
+// TestNameTypeElements_ResolveGoTypes tests resolving element types through a type-checked bundle
+// Verifies named, imported and variadic param types are resolved
+//
+// TestNameTypeElements_ResolveGoTypes 测试通过已类型检查的 bundle 解析元素类型
+// 验证命名类型、导入类型和变参类型都能被解析
+func TestNameTypeElements_ResolveGoTypes(t *testing.T) {
+	source := []byte(`package example
+
+import "time"
+
+type User struct{}
+
+func Save(user *User, at time.Time, tags ...string) error {
+	return nil
+}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	typesBundle := rese.P1(astBundle.TypeCheck())
+	astFile, _ := astBundle.GetBundle()
+
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "Save")
+	require.NotNil(t, astFunc)
+
+	params := GetSimpleArgElements(astFunc.Type.Params.List, source).ResolveGoTypes(typesBundle.GetInfo())
+	var typeStrings []string
+	for _, goType := range params.GoTypes() {
+		typeStrings = append(typeStrings, goType.String())
+	}
+	require.Equal(t, []string{"*example.User", "time.Time", "[]string"}, typeStrings)
+}
+
+// TestNewNameTypeElementsV2_PackageName tests qualifying the param types with the package name
+// Verifies exported names nested in slice, map, chan, func, array and generic types are qualified, while generic params and qualified names are kept
+//
+// TestNewNameTypeElementsV2_PackageName 测试使用包名限定参数类型
+// 验证嵌套在切片、map、通道、函数、数组和泛型类型中的导出名称会被限定，泛型参数和已限定的名称保持不变
+func TestNewNameTypeElementsV2_PackageName(t *testing.T) {
+	source := []byte(`package example
+
+func Run[T any](a []User, b map[string]*User, c chan Event, d func(user User, opts ...Option) error, e [Size]User, f List[User, T], g *time.Time, h T, i int, j struct{ Name Key }, k ...*User) {}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+
+	astFunc := syntaxgo_search.FindFunctionByName(astFile, "Run")
+	require.NotNil(t, astFunc)
+
+	params := NewNameTypeElementsV2(fset, astFunc.Type.Params, SimpleMakeNameFunction("arg"), source, "pkg", GetFuncGenericTypeParamsMap(astFunc))
+	require.Equal(t, []string{
+		"[]pkg.User",
+		"map[string]*pkg.User",
+		"chan pkg.Event",
+		"func(user pkg.User, opts ...pkg.Option) error",
+		"[pkg.Size]pkg.User",
+		"pkg.List[pkg.User, T]",
+		"*time.Time",
+		"T",
+		"int",
+		"struct{ Name pkg.Key }",
+		"...*pkg.User",
+	}, params.Kinds())
+}
@@ -2,6 +2,7 @@ package syntaxgo_astnorm
 
 import (
 	"go/ast"
+	"go/token"
 	"strconv"
 
 	"github.com/yyle88/tern"
@@ -15,10 +16,24 @@ func NewPrefixedNameTypeElements(
 	sourceCode []byte,
 	pkgName string,
 	genericTypeParams map[string]ast.Expr,
+) NameTypeElements {
+	return NewPrefixedNameTypeElementsV2(nil, fieldList, prefix, sourceCode, pkgName, genericTypeParams)
+}
+
+// NewPrefixedNameTypeElementsV2 creates a NameTypeElements instance with prefixed names, resolving offsets through the FileSet.
+// NewPrefixedNameTypeElementsV2 使用带前缀的命名创建 NameTypeElements 实例，通过 FileSet 计算偏移量。
+func NewPrefixedNameTypeElementsV2(
+	fset *token.FileSet,
+	fieldList *ast.FieldList,
+	prefix string,
+	sourceCode []byte,
+	pkgName string,
+	genericTypeParams map[string]ast.Expr,
 ) NameTypeElements {
 	// Creates a new NameTypeElements instance with the specified field list and naming function.
 	// 使用指定的字段列表和命名函数创建新的 NameTypeElements 实例。
-	return NewNameTypeElements(
+	return NewNameTypeElementsV2(
+		fset,
 		fieldList,
 		MakePrefixedNameFunction(prefix),
 		sourceCode,
@@ -2,6 +2,7 @@ package syntaxgo_astnorm
 
 import (
 	"go/ast"
+	"go/token"
 	"strconv"
 
 	"github.com/yyle88/tern"
@@ -10,7 +11,14 @@ import (
 // GetSimpleResElements extracts NameTypeElements from the given fields with a "res" prefix for names.
 // GetSimpleResElements 从给定字段中提取 NameTypeElements，并为名称添加 "res" 前缀。
 func GetSimpleResElements(fields []*ast.Field, sourceCode []byte) NameTypeElements {
-	return ExtractNameTypeElements(
+	return GetSimpleResElementsV2(nil, fields, sourceCode)
+}
+
+// GetSimpleResElementsV2 extracts NameTypeElements with a "res" prefix for names, resolving offsets through the FileSet.
+// GetSimpleResElementsV2 提取 NameTypeElements 并为名称添加 "res" 前缀，通过 FileSet 计算偏移量。
+func GetSimpleResElementsV2(fset *token.FileSet, fields []*ast.Field, sourceCode []byte) NameTypeElements {
+	return ExtractNameTypeElementsV2(
+		fset,
 		fields,
 		SimpleMakeNameFunction("res"),
 		sourceCode,
@@ -22,7 +30,14 @@ func GetSimpleResElements(fields []*ast.Field, sourceCode []byte) NameTypeElemen
 // GetSimpleArgElements extracts NameTypeElements from the given fields with an "arg" prefix for names.
 // GetSimpleArgElements 从给定字段中提取 NameTypeElements，并为名称添加 "arg" 前缀。
 func GetSimpleArgElements(fields []*ast.Field, sourceCode []byte) NameTypeElements {
-	return ExtractNameTypeElements(
+	return GetSimpleArgElementsV2(nil, fields, sourceCode)
+}
+
+// GetSimpleArgElementsV2 extracts NameTypeElements with a "arg" prefix for names, resolving offsets through the FileSet.
+// GetSimpleArgElementsV2 提取 NameTypeElements 并为名称添加 "arg" 前缀，通过 FileSet 计算偏移量。
+func GetSimpleArgElementsV2(fset *token.FileSet, fields []*ast.Field, sourceCode []byte) NameTypeElements {
+	return ExtractNameTypeElementsV2(
+		fset,
 		fields,
 		SimpleMakeNameFunction("arg"),
 		sourceCode,
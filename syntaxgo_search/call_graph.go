@@ -0,0 +1,761 @@
+package syntaxgo_search
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/internal/utils"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// CallKind is the kind of an edge in the call graph.
+// CallKind 是调用图中边的种类。
+type CallKind string
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	CALL_KIND_FUNCTION CallKind = "FUNCTION" // Call of a function of the package / 调用包内的函数
+	CALL_KIND_METHOD   CallKind = "METHOD"   // Call of a method resolved through its receiver type / 调用通过接收者类型解析的方法
+	CALL_KIND_EXTERNAL CallKind = "EXTERNAL" // Call of a package-qualified function, such as fmt.Println / 调用带包名的函数，比如 fmt.Println
+	CALL_KIND_CLOSURE  CallKind = "CLOSURE"  // Call of a function literal / 调用函数字面量
+	CALL_KIND_DEFINE   CallKind = "DEFINE"   // Function literal defined in the caller, without a call site / 在调用者中定义的函数字面量，没有调用位置
+	CALL_KIND_DYNAMIC  CallKind = "DYNAMIC"  // Call that cannot be resolved syntactically, such as a func value or an interface method / 无法按语法解析的调用，比如函数值或接口方法
+)
+
+// CallNode is a function, method, function literal or external function in the call graph.
+// IDs are "Name" for functions, "Type.Name" for methods, "Parent.funcN" for function literals
+// (nested literals get "Parent.funcN.M", package-level ones "init.funcN"), and "import/path.Name" for external functions.
+//
+// CallNode 是调用图中的函数、方法、函数字面量或外部函数。
+// ID 的格式：函数为 "Name"，方法为 "Type.Name"，函数字面量为 "Parent.funcN"
+// （嵌套的字面量为 "Parent.funcN.M"，包级的为 "init.funcN"），外部函数为 "import/path.Name"。
+type CallNode struct {
+	ID           string         // Unique ID / 唯一 ID
+	Name         string         // Function or method name / 函数或方法名
+	RecvTypeName string         // Receiver type name for methods / 方法的接收者类型名
+	PkgPath      string         // Import path for external functions / 外部函数的导入路径
+	FuncDecl     *ast.FuncDecl  // Function declaration, nil for literals and external functions / 函数声明，函数字面量和外部函数时为 nil
+	FuncLit      *ast.FuncLit   // Function literal, nil otherwise / 函数字面量，其他情况为 nil
+	Position     token.Position // Declaration position, zero for external functions / 声明位置，外部函数时为零值
+}
+
+// IsExternal reports whether the node is a function of another package.
+// IsExternal 判断节点是否为其他包的函数。
+func (node *CallNode) IsExternal() bool {
+	return node.PkgPath != ""
+}
+
+// CallEdge is a call from a caller to a callee.
+// The callee of a dynamic call has no node, its ID is the text of the called expression.
+//
+// CallEdge 是从调用者到被调用者的一次调用。
+// 动态调用的被调用者没有节点，其 ID 是被调用表达式的文本。
+type CallEdge struct {
+	Caller   string         // Caller ID / 调用者 ID
+	Callee   string         // Callee ID / 被调用者 ID
+	Kind     CallKind       // Edge kind / 边的种类
+	Call     *ast.CallExpr  // Call expression, nil for DEFINE edges / 调用表达式，DEFINE 边时为 nil
+	Position token.Position // Call site, or the literal position for DEFINE edges / 调用位置，DEFINE 边时为字面量的位置
+}
+
+// CallGraph is a syntactic call graph of a package, edges are kept in source order.
+// CallGraph 是一个包的语法调用图，边按源码顺序保存。
+type CallGraph struct {
+	nodes   []*CallNode          // Functions and methods, then literals, then external functions / 依次为函数和方法、字面量以及外部函数
+	nodeMap map[string]*CallNode // Nodes by ID / 按 ID 索引的节点
+	edges   []*CallEdge          // Edges in source order / 按源码顺序排列的边
+}
+
+// NewCallGraph builds the call graph of the files, which should belong to the same package.
+// Methods are resolved when the type of the receiver expression is known from its declaration,
+// such as the method receiver, a parameter, `var x T`, `x := &T{}`, `x := new(T)` or `x := NewT()`.
+//
+// NewCallGraph 构建这些文件的调用图，这些文件应属于同一个包。
+// 当接收者表达式的类型可以从其声明得知时会解析方法，
+// 比如方法接收者、参数、`var x T`、`x := &T{}`、`x := new(T)` 或 `x := NewT()`。
+func NewCallGraph(fset *token.FileSet, astFiles []*ast.File) *CallGraph {
+	builder := &callGraphBuilder{
+		fset:             fset,
+		graph:            &CallGraph{nodeMap: map[string]*CallNode{}},
+		litIDs:           map[*ast.FuncLit]string{},
+		counters:         map[string]int{},
+		exprTypeResolver: newExprTypeResolver(astFiles),
+	}
+	for _, astFile := range astFiles {
+		builder.addDeclNodes(astFile)
+	}
+	for _, astFile := range astFiles {
+		builder.addLitNodes(astFile)
+	}
+	var externals []*CallNode
+	for _, astFile := range astFiles {
+		externals = append(externals, builder.addEdges(astFile)...)
+	}
+	builder.graph.nodes = append(builder.graph.nodes, externals...)
+	return builder.graph
+}
+
+// NewCallGraphInPackage builds the call graph of the package.
+// NewCallGraphInPackage 构建包的调用图。
+func NewCallGraphInPackage(pkgBundle *syntaxgo_ast.PackageBundle) *CallGraph {
+	return NewCallGraph(pkgBundle.GetFileSet(), pkgBundle.GetAstFiles())
+}
+
+// GetNodes returns the functions and methods in declaration order, then the function literals, then the external functions.
+// GetNodes 依次返回按声明顺序排列的函数和方法、函数字面量以及外部函数。
+func (graph *CallGraph) GetNodes() []*CallNode {
+	return graph.nodes
+}
+
+// GetNode returns the node with the ID.
+// GetNode 返回具有该 ID 的节点。
+func (graph *CallGraph) GetNode(id string) (*CallNode, bool) {
+	node, ok := graph.nodeMap[id]
+	return node, ok
+}
+
+// GetEdges returns the edges in source order.
+// GetEdges 按源码顺序返回边。
+func (graph *CallGraph) GetEdges() []*CallEdge {
+	return graph.edges
+}
+
+// CalleesOf returns the edges going out of the node.
+// CalleesOf 返回从该节点出发的边。
+func (graph *CallGraph) CalleesOf(id string) (edges []*CallEdge) {
+	for _, edge := range graph.edges {
+		if edge.Caller == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// CallersOf returns the edges coming into the node.
+// CallersOf 返回指向该节点的边。
+func (graph *CallGraph) CallersOf(id string) (edges []*CallEdge) {
+	for _, edge := range graph.edges {
+		if edge.Callee == id {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// ReachableFrom returns the IDs of the nodes reachable from the given nodes, in breadth-first order, the given nodes excluded.
+// Dynamic callees are not followed since they have no nodes.
+//
+// ReachableFrom 按广度优先顺序返回从给定节点可达的节点 ID，不包括给定节点本身。
+// 动态调用的被调用者没有节点，因此不会继续跟随。
+func (graph *CallGraph) ReachableFrom(ids ...string) []string {
+	return graph.walk(ids, func(id string) []string {
+		var next []string
+		for _, edge := range graph.CalleesOf(id) {
+			if _, ok := graph.nodeMap[edge.Callee]; ok {
+				next = append(next, edge.Callee)
+			}
+		}
+		return next
+	})
+}
+
+// TransitiveCallersOf returns the IDs of the nodes which reach the given nodes, in breadth-first order, the given nodes excluded.
+// TransitiveCallersOf 按广度优先顺序返回能到达给定节点的节点 ID，不包括给定节点本身。
+func (graph *CallGraph) TransitiveCallersOf(ids ...string) []string {
+	return graph.walk(ids, func(id string) []string {
+		var next []string
+		for _, edge := range graph.CallersOf(id) {
+			next = append(next, edge.Caller)
+		}
+		return next
+	})
+}
+
+func (graph *CallGraph) walk(ids []string, getNext func(id string) []string) (results []string) {
+	var seen = map[string]bool{}
+	for _, id := range ids {
+		seen[id] = true
+	}
+	var queue = append([]string{}, ids...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range getNext(id) {
+			if !seen[next] {
+				seen[next] = true
+				results = append(results, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return results
+}
+
+// ToDOT renders the graph in the Graphviz DOT language, with one edge per caller and callee pair.
+// External functions are drawn as boxes, dynamic callees as dashed ellipses and DEFINE edges as dotted lines.
+//
+// ToDOT 以 Graphviz DOT 语言渲染调用图，每对调用者和被调用者只画一条边。
+// 外部函数画成方框，动态调用的被调用者画成虚线椭圆，DEFINE 边画成点线。
+func (graph *CallGraph) ToDOT() string {
+	ptx := utils.NewPTX()
+	ptx.Println("digraph calls {")
+	for _, node := range graph.nodes {
+		if node.IsExternal() {
+			ptx.Printf("\t%s [shape=box];\n", strconv.Quote(node.ID))
+		} else {
+			ptx.Printf("\t%s;\n", strconv.Quote(node.ID))
+		}
+	}
+	var seen = map[string]bool{}
+	for _, edge := range graph.edges {
+		if _, ok := graph.nodeMap[edge.Callee]; !ok && !seen[edge.Callee] {
+			seen[edge.Callee] = true
+			ptx.Printf("\t%s [style=dashed];\n", strconv.Quote(edge.Callee))
+		}
+	}
+	var seenPairs = map[[2]string]bool{}
+	for _, edge := range graph.edges {
+		pair := [2]string{edge.Caller, edge.Callee}
+		if seenPairs[pair] {
+			continue
+		}
+		seenPairs[pair] = true
+		switch edge.Kind {
+		case CALL_KIND_DEFINE:
+			ptx.Printf("\t%s -> %s [style=dotted];\n", strconv.Quote(edge.Caller), strconv.Quote(edge.Callee))
+		case CALL_KIND_DYNAMIC:
+			ptx.Printf("\t%s -> %s [style=dashed];\n", strconv.Quote(edge.Caller), strconv.Quote(edge.Callee))
+		default:
+			ptx.Printf("\t%s -> %s;\n", strconv.Quote(edge.Caller), strconv.Quote(edge.Callee))
+		}
+	}
+	ptx.Println("}")
+	return ptx.String()
+}
+
+// callGraphJSON is the JSON form of the graph.
+// callGraphJSON 是调用图的 JSON 形式。
+type callGraphJSON struct {
+	Nodes []*callNodeJSON `json:"nodes"`
+	Edges []*callEdgeJSON `json:"edges"`
+}
+
+type callNodeJSON struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	RecvTypeName string `json:"recvTypeName,omitempty"`
+	PkgPath      string `json:"pkgPath,omitempty"`
+	Filename     string `json:"filename,omitempty"`
+	Line         int    `json:"line,omitempty"`
+}
+
+type callEdgeJSON struct {
+	Caller   string   `json:"caller"`
+	Callee   string   `json:"callee"`
+	Kind     CallKind `json:"kind"`
+	Filename string   `json:"filename,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+}
+
+// ToJSON renders the nodes and edges as indented JSON, with positions as filename, line and column.
+// ToJSON 将节点和边渲染为带缩进的 JSON，位置以文件名、行号和列号表示。
+func (graph *CallGraph) ToJSON() ([]byte, error) {
+	result := &callGraphJSON{Nodes: []*callNodeJSON{}, Edges: []*callEdgeJSON{}}
+	for _, node := range graph.nodes {
+		result.Nodes = append(result.Nodes, &callNodeJSON{
+			ID:           node.ID,
+			Name:         node.Name,
+			RecvTypeName: node.RecvTypeName,
+			PkgPath:      node.PkgPath,
+			Filename:     node.Position.Filename,
+			Line:         node.Position.Line,
+		})
+	}
+	for _, edge := range graph.edges {
+		result.Edges = append(result.Edges, &callEdgeJSON{
+			Caller:   edge.Caller,
+			Callee:   edge.Callee,
+			Kind:     edge.Kind,
+			Filename: edge.Position.Filename,
+			Line:     edge.Position.Line,
+			Column:   edge.Position.Column,
+		})
+	}
+	data, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return data, nil
+}
+
+// callGraphBuilder holds the state used while building a call graph.
+// callGraphBuilder 保存构建调用图时使用的状态。
+type callGraphBuilder struct {
+	fset     *token.FileSet
+	graph    *CallGraph
+	litIDs   map[*ast.FuncLit]string // IDs of the function literals / 函数字面量的 ID
+	counters map[string]int          // Literal counts by parent ID / 按父节点 ID 统计的字面量数量
+	*exprTypeResolver
+}
+
+func (builder *callGraphBuilder) addNode(node *CallNode) {
+	builder.graph.nodes = append(builder.graph.nodes, node)
+	builder.graph.nodeMap[node.ID] = node
+}
+
+// addDeclNodes adds the functions and methods of the file.
+// addDeclNodes 添加文件中的函数和方法。
+func (builder *callGraphBuilder) addDeclNodes(astFile *ast.File) {
+	for _, funcDecl := range FindFunctions(astFile) {
+		node := &CallNode{ID: funcDecl.Name.Name, Name: funcDecl.Name.Name, FuncDecl: funcDecl, Position: builder.fset.Position(funcDecl.Pos())}
+		if receiverInfo, ok := GetFunctionReceiverInfo(funcDecl); ok {
+			node.RecvTypeName = receiverInfo.TypeName
+			node.ID = receiverInfo.TypeName + "." + funcDecl.Name.Name
+		}
+		if funcDecl.Name.Name == "init" || funcDecl.Name.Name == "_" {
+			if _, ok := builder.graph.nodeMap[node.ID]; ok {
+				continue // Several init functions share one node. // 多个 init 函数共用一个节点。
+			}
+		}
+		builder.addNode(node)
+	}
+}
+
+// addLitNodes adds the function literals of the file, named after their enclosing functions.
+// addLitNodes 添加文件中的函数字面量，以其外层函数命名。
+func (builder *callGraphBuilder) addLitNodes(astFile *ast.File) {
+	counters := builder.counters
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		x, ok := node.(*ast.FuncLit)
+		if !ok {
+			return
+		}
+		var id string
+		if funcLit != nil {
+			parentID := builder.litIDs[funcLit]
+			counters[parentID]++
+			id = parentID + "." + strconv.Itoa(counters[parentID])
+		} else {
+			parentID := "init"
+			if funcDecl != nil {
+				parentID = builder.getDeclID(funcDecl)
+			}
+			counters[parentID]++
+			id = parentID + ".func" + strconv.Itoa(counters[parentID])
+		}
+		builder.litIDs[x] = id
+		builder.addNode(&CallNode{ID: id, Name: id, FuncLit: x, Position: builder.fset.Position(x.Pos())})
+	})
+}
+
+func (builder *callGraphBuilder) getDeclID(funcDecl *ast.FuncDecl) string {
+	if receiverInfo, ok := GetFunctionReceiverInfo(funcDecl); ok {
+		return receiverInfo.TypeName + "." + funcDecl.Name.Name
+	}
+	return funcDecl.Name.Name
+}
+
+// addEdges adds the calls of the file and returns the external nodes it creates.
+// addEdges 添加文件中的调用，并返回新建的外部节点。
+func (builder *callGraphBuilder) addEdges(astFile *ast.File) (externals []*CallNode) {
+	var imports = map[string]string{}
+	for _, importSpec := range astFile.Imports {
+		pkgPath, _ := strconv.Unquote(importSpec.Path.Value)
+		name := syntaxgo_ast.GuessPackageName(pkgPath)
+		if importSpec.Name != nil {
+			name = importSpec.Name.Name
+		}
+		imports[name] = pkgPath
+	}
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		var callerID string
+		switch {
+		case funcLit != nil:
+			callerID = builder.litIDs[funcLit]
+		case funcDecl != nil:
+			callerID = builder.getDeclID(funcDecl)
+		default:
+			return // Calls in package-level values have no caller. // 包级变量中的调用没有调用者。
+		}
+		switch x := node.(type) {
+		case *ast.FuncLit:
+			builder.graph.edges = append(builder.graph.edges, &CallEdge{
+				Caller:   callerID,
+				Callee:   builder.litIDs[x],
+				Kind:     CALL_KIND_DEFINE,
+				Position: builder.fset.Position(x.Pos()),
+			})
+		case *ast.CallExpr:
+			calleeID, kind, ok := builder.resolveCall(x, imports)
+			if !ok {
+				return
+			}
+			if kind == CALL_KIND_EXTERNAL {
+				if _, exists := builder.graph.nodeMap[calleeID]; !exists {
+					pkgPath, name := splitExternalID(calleeID)
+					external := &CallNode{ID: calleeID, Name: name, PkgPath: pkgPath}
+					builder.graph.nodeMap[calleeID] = external
+					externals = append(externals, external)
+				}
+			}
+			builder.graph.edges = append(builder.graph.edges, &CallEdge{
+				Caller:   callerID,
+				Callee:   calleeID,
+				Kind:     kind,
+				Call:     x,
+				Position: builder.fset.Position(x.Lparen),
+			})
+		}
+	})
+	return externals
+}
+
+func splitExternalID(id string) (pkgPath string, name string) {
+	idx := strings.LastIndex(id, ".")
+	return id[:idx], id[idx+1:]
+}
+
+// resolveCall returns the callee of the call, false for builtins and conversions.
+// resolveCall 返回调用的被调用者，内置函数和类型转换时返回 false。
+func (builder *callGraphBuilder) resolveCall(callExpr *ast.CallExpr, imports map[string]string) (string, CallKind, bool) {
+	fun := unwrapCallFun(callExpr.Fun)
+	switch x := fun.(type) {
+	case *ast.FuncLit:
+		return builder.litIDs[x], CALL_KIND_CLOSURE, true
+	case *ast.Ident:
+		if x.Obj != nil && x.Obj.Kind == ast.Var {
+			if funcLit, ok := getAssignedFuncLit(x.Obj); ok {
+				return builder.litIDs[funcLit], CALL_KIND_CLOSURE, true
+			}
+			return x.Name, CALL_KIND_DYNAMIC, true
+		}
+		if _, ok := builder.functions[x.Name]; ok {
+			return x.Name, CALL_KIND_FUNCTION, true
+		}
+		if _, ok := builder.resolver.typeSpecs[x.Name]; ok || types.Universe.Lookup(x.Name) != nil {
+			return "", "", false // Conversions and builtins. // 类型转换和内置函数。
+		}
+		return x.Name, CALL_KIND_DYNAMIC, true
+	case *ast.SelectorExpr:
+		if ident, ok := x.X.(*ast.Ident); ok && ident.Obj == nil {
+			if pkgPath, ok := imports[ident.Name]; ok {
+				return pkgPath + "." + x.Sel.Name, CALL_KIND_EXTERNAL, true
+			}
+			if _, ok := builder.resolver.typeSpecs[ident.Name]; ok {
+				if calleeID, ok := builder.resolveMethod(ident.Name, x.Sel.Name); ok {
+					return calleeID, CALL_KIND_METHOD, true // Method expression like T.Method(value). // 类似 T.Method(value) 的方法表达式。
+				}
+			}
+		}
+		if typeName, ok := builder.getExprTypeName(x.X); ok {
+			if calleeID, ok := builder.resolveMethod(typeName, x.Sel.Name); ok {
+				return calleeID, CALL_KIND_METHOD, true
+			}
+		}
+		return types.ExprString(x), CALL_KIND_DYNAMIC, true
+	case *ast.ParenExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.StarExpr:
+		return "", "", false // Conversions like (*T)(x) or []byte(s). // 类似 (*T)(x) 或 []byte(s) 的类型转换。
+	}
+	return types.ExprString(fun), CALL_KIND_DYNAMIC, true
+}
+
+// resolveMethod returns the ID of the method of the type, following promoted methods, false for interface methods.
+// resolveMethod 返回类型的方法的 ID，会跟随提升的方法，接口方法时返回 false。
+func (builder *callGraphBuilder) resolveMethod(typeName string, methodName string) (string, bool) {
+	entry, ok := builder.getMethod(typeName, methodName)
+	if !ok || entry.FuncDecl == nil {
+		return "", false
+	}
+	return entry.RecvTypeName + "." + methodName, true
+}
+
+// exprTypeResolver infers the named types of expressions from their declarations in the package.
+// exprTypeResolver 根据包内的声明推断表达式的命名类型。
+type exprTypeResolver struct {
+	resolver   *MethodSetResolver        // Resolves promoted methods / 解析提升的方法
+	functions  map[string]*ast.FuncDecl  // Functions without receivers by name / 按名称索引的无接收者函数
+	values     map[string]*ast.ValueSpec // Package-level constants and variables by name / 按名称索引的包级常量和变量
+	methodSets map[string]*MethodSet     // Pointer method sets by type name / 按类型名缓存的指针方法集
+}
+
+func newExprTypeResolver(astFiles []*ast.File) *exprTypeResolver {
+	exprResolver := &exprTypeResolver{
+		resolver:   NewMethodSetResolver(astFiles),
+		functions:  map[string]*ast.FuncDecl{},
+		values:     map[string]*ast.ValueSpec{},
+		methodSets: map[string]*MethodSet{},
+	}
+	for _, astFile := range astFiles {
+		functions, _, values := FindClassesAndFunctions(astFile)
+		for _, funcDecl := range functions {
+			if funcDecl.Recv == nil {
+				exprResolver.functions[funcDecl.Name.Name] = funcDecl
+			}
+		}
+		for _, valueSpec := range values {
+			for _, name := range valueSpec.Names {
+				exprResolver.values[name.Name] = valueSpec
+			}
+		}
+	}
+	return exprResolver
+}
+
+// getMethod returns the method of the pointer method set of the type, promoted methods included.
+// getMethod 返回类型的指针方法集中的方法，包括提升的方法。
+func (exprResolver *exprTypeResolver) getMethod(typeName string, methodName string) (*MethodSetEntry, bool) {
+	methodSet, ok := exprResolver.methodSets[typeName]
+	if !ok {
+		var err error
+		if methodSet, err = exprResolver.resolver.MethodSet(typeName, true); err != nil {
+			return nil, false
+		}
+		exprResolver.methodSets[typeName] = methodSet
+	}
+	return methodSet.GetMethod(methodName)
+}
+
+// getExprTypeName returns the named type of an expression, from the declaration of an identifier or the form of a literal.
+// getExprTypeName 根据标识符的声明或字面量的形式，返回表达式的命名类型。
+func (exprResolver *exprTypeResolver) getExprTypeName(expr ast.Expr) (string, bool) {
+	if typeExpr, ok := exprResolver.getExprTypeExpr(expr); ok {
+		return exprResolver.getTypeExprName(typeExpr)
+	}
+	return "", false
+}
+
+// getExprTypeExpr returns the type expression of an expression as written in the package, such as `[]User` of `us` in `var us []User`.
+// Pointers are not tracked, `&x` and `*x` have the type expression of x.
+//
+// getExprTypeExpr 返回表达式在包内书写的类型表达式，比如 `var us []User` 中 `us` 的 `[]User`。
+// 不区分指针，`&x` 和 `*x` 的类型表达式与 x 相同。
+func (exprResolver *exprTypeResolver) getExprTypeExpr(expr ast.Expr) (ast.Expr, bool) {
+	switch x := expr.(type) {
+	case *ast.ParenExpr:
+		return exprResolver.getExprTypeExpr(x.X)
+	case *ast.StarExpr:
+		return exprResolver.getExprTypeExpr(x.X)
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return exprResolver.getExprTypeExpr(x.X)
+		}
+	case *ast.CompositeLit:
+		if x.Type != nil {
+			return x.Type, true
+		}
+	case *ast.IndexExpr:
+		if typeExpr, ok := exprResolver.getExprTypeExpr(x.X); ok {
+			return exprResolver.getElemTypeExpr(typeExpr, false)
+		}
+	case *ast.SelectorExpr:
+		if typeName, ok := exprResolver.getExprTypeName(x.X); ok {
+			if field, ok := exprResolver.lookupField(typeName, x.Sel.Name); ok {
+				return field.Type, true
+			}
+		}
+	case *ast.CallExpr:
+		fun := unwrapCallFun(x.Fun)
+		if ident, ok := fun.(*ast.Ident); ok {
+			if (ident.Name == "new" || ident.Name == "make") && len(x.Args) > 0 {
+				return x.Args[0], true
+			}
+			if funcDecl, ok := exprResolver.functions[ident.Name]; ok && funcDecl.Type.Results != nil && len(funcDecl.Type.Results.List) > 0 {
+				return funcDecl.Type.Results.List[0].Type, true
+			}
+			if _, ok := exprResolver.resolver.typeSpecs[ident.Name]; ok {
+				return ident, true // Conversion like T(x). // 类似 T(x) 的类型转换。
+			}
+		}
+	case *ast.Ident:
+		if x.Obj == nil {
+			if valueSpec, ok := exprResolver.values[x.Name]; ok {
+				return exprResolver.getValueTypeExpr(valueSpec, x.Name) // Declared in another file. // 声明在其他文件中。
+			}
+			return nil, false
+		}
+		switch decl := x.Obj.Decl.(type) {
+		case *ast.Field:
+			return decl.Type, true
+		case *ast.ValueSpec:
+			return exprResolver.getValueTypeExpr(decl, x.Name)
+		case *ast.AssignStmt:
+			if len(decl.Rhs) == 1 {
+				if unaryExpr, ok := decl.Rhs[0].(*ast.UnaryExpr); ok && unaryExpr.Op == token.RANGE {
+					return exprResolver.getRangeTypeExpr(decl.Lhs, unaryExpr.X, x.Name)
+				}
+			}
+			if len(decl.Lhs) != len(decl.Rhs) {
+				return nil, false
+			}
+			for idx, lhs := range decl.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == x.Name {
+					return exprResolver.getExprTypeExpr(decl.Rhs[idx])
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// getValueTypeExpr returns the type expression of the variable declared by the spec, from its type or its value.
+// getValueTypeExpr 根据类型或值，返回该声明中变量的类型表达式。
+func (exprResolver *exprTypeResolver) getValueTypeExpr(valueSpec *ast.ValueSpec, name string) (ast.Expr, bool) {
+	if valueSpec.Type != nil {
+		return valueSpec.Type, true
+	}
+	for idx, ident := range valueSpec.Names {
+		if ident.Name == name && idx < len(valueSpec.Values) && len(valueSpec.Names) == len(valueSpec.Values) {
+			return exprResolver.getExprTypeExpr(valueSpec.Values[idx])
+		}
+	}
+	return nil, false
+}
+
+// getRangeTypeExpr returns the type expression of a key or value variable of a range clause, such as `User` of `v` in `for _, v := range us`.
+// The parser records the clause as an assignment, the ranged operand is wrapped in a unary expression with the RANGE operator.
+//
+// getRangeTypeExpr 返回 range 子句中键或值变量的类型表达式，比如 `for _, v := range us` 中 `v` 的 `User`。
+// 解析器将该子句记录为赋值语句，被遍历的操作数包装在运算符为 RANGE 的一元表达式中。
+func (exprResolver *exprTypeResolver) getRangeTypeExpr(lhsList []ast.Expr, operand ast.Expr, name string) (ast.Expr, bool) {
+	for idx, lhs := range lhsList {
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+			if typeExpr, ok := exprResolver.getExprTypeExpr(operand); ok {
+				return exprResolver.getElemTypeExpr(typeExpr, idx == 0)
+			}
+		}
+	}
+	return nil, false
+}
+
+// getElemTypeExpr returns the element type of a slice, array or pointer to array, or the key or value type of a map.
+// Defined types like `type Users []User` are followed to their underlying type expression.
+//
+// getElemTypeExpr 返回切片、数组或数组指针的元素类型，或者映射的键或值类型。
+// 类似 `type Users []User` 的定义类型会跟随到其底层类型表达式。
+func (exprResolver *exprTypeResolver) getElemTypeExpr(typeExpr ast.Expr, key bool) (ast.Expr, bool) {
+	if ident, ok := typeExpr.(*ast.Ident); ok {
+		if typeSpec, ok := exprResolver.resolver.typeSpecs[exprResolver.resolver.resolveAlias(ident.Name)]; ok {
+			typeExpr = typeSpec.Type
+		}
+	}
+	if starExpr, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = starExpr.X
+	}
+	switch x := typeExpr.(type) {
+	case *ast.ArrayType:
+		if !key {
+			return x.Elt, true
+		}
+	case *ast.MapType:
+		if key {
+			return x.Key, true
+		}
+		return x.Value, true
+	}
+	return nil, false
+}
+
+// getTypeExprName returns the name of a type expression declared in the package, such as "T" of `*T` or `T[int]`.
+// getTypeExprName 返回包内声明的类型表达式的名称，比如 `*T` 或 `T[int]` 中的 "T"。
+func (exprResolver *exprTypeResolver) getTypeExprName(typeExpr ast.Expr) (string, bool) {
+	if ident, ok := unwrapEmbeddedType(typeExpr).(*ast.Ident); ok {
+		if _, ok := exprResolver.resolver.typeSpecs[ident.Name]; ok {
+			return ident.Name, true
+		}
+	}
+	return "", false
+}
+
+// lookupField finds the field of the struct type by name, following embedded fields by depth,
+// false when the field is not found or the name is ambiguous at the shallowest depth having it.
+//
+// lookupField 按名称查找结构体类型的字段，按深度跟随嵌入字段，
+// 找不到字段或在最浅的出现深度上名称有歧义时返回 false。
+func (exprResolver *exprTypeResolver) lookupField(typeName string, fieldName string) (*ast.Field, bool) {
+	var seen = map[string]bool{}
+	var current = []string{typeName}
+	for len(current) > 0 {
+		var next []string
+		var found []*ast.Field
+		for _, name := range current {
+			name = exprResolver.resolver.resolveAlias(name)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			typeSpec, ok := exprResolver.resolver.typeSpecs[name]
+			if !ok {
+				continue
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range structType.Fields.List {
+				if len(field.Names) > 0 {
+					if slices.ContainsFunc(field.Names, func(ident *ast.Ident) bool { return ident.Name == fieldName }) {
+						found = append(found, field)
+					}
+					continue
+				}
+				if embeddedName := getEmbeddedName(field.Type); embeddedName != nil {
+					if embeddedName.Name == fieldName {
+						found = append(found, field)
+					} else if _, ok := getQualifiedName(field.Type); !ok {
+						next = append(next, embeddedName.Name)
+					}
+				}
+			}
+		}
+		if len(found) > 0 {
+			return found[0], len(found) == 1
+		}
+		current = next
+	}
+	return nil, false
+}
+
+// unwrapCallFun removes explicit type arguments from the called expression, such as `Map[int, string]`.
+// unwrapCallFun 去掉被调用表达式上的显式类型实参，比如 `Map[int, string]`。
+func unwrapCallFun(fun ast.Expr) ast.Expr {
+	switch x := fun.(type) {
+	case *ast.IndexExpr:
+		return x.X
+	case *ast.IndexListExpr:
+		return x.X
+	}
+	return fun
+}
+
+// getAssignedFuncLit returns the function literal assigned to a local variable at its declaration, like `run := func() {...}`.
+// getAssignedFuncLit 返回局部变量在声明时被赋值的函数字面量，比如 `run := func() {...}`。
+func getAssignedFuncLit(object *ast.Object) (*ast.FuncLit, bool) {
+	var lhs []ast.Expr
+	var rhs []ast.Expr
+	switch decl := object.Decl.(type) {
+	case *ast.AssignStmt:
+		lhs, rhs = decl.Lhs, decl.Rhs
+	case *ast.ValueSpec:
+		for _, name := range decl.Names {
+			lhs = append(lhs, name)
+		}
+		rhs = decl.Values
+	}
+	if len(lhs) != len(rhs) {
+		return nil, false
+	}
+	for idx, expr := range lhs {
+		if ident, ok := expr.(*ast.Ident); ok && ident.Name == object.Name {
+			funcLit, ok := rhs[idx].(*ast.FuncLit)
+			return funcLit, ok
+		}
+	}
+	return nil, false
+}
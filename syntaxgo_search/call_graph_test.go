@@ -0,0 +1,226 @@
+package syntaxgo_search
+
+import (
+	"encoding/json"
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const callGraphExampleCode = `package example
+
+import (
+	"fmt"
+	str "strings"
+)
+
+type Base struct{}
+
+func (b *Base) Close() {}
+
+type Service struct {
+	Base
+	name string
+}
+
+func NewService(name string) *Service {
+	return &Service{name: clean(name)}
+}
+
+func (s *Service) Run() {
+	s.log()
+	s.Close()
+	fmt.Println(str.ToUpper(s.name))
+}
+
+func (s *Service) log() {}
+
+func clean(name string) string {
+	return str.TrimSpace(name)
+}
+
+func main() {
+	svc := NewService("demo")
+	svc.Run()
+	var other Service
+	other.log()
+	run := func() {
+		defer func() {
+			_ = recover()
+		}()
+	}
+	run()
+	handler := pick()
+	handler()
+	_ = len(string([]byte("x")))
+}
+
+func pick() func() { return nil }
+
+func unused() {}
+`
+
+// TestNewCallGraph tests building the call graph of a file
+// Verifies methods are resolved through receivers, constructors and promoted fields, and literals are named after their parents
+//
+// TestNewCallGraph 测试构建文件的调用图
+// 验证方法能通过接收者、构造函数和提升的字段解析，函数字面量以其外层函数命名
+func TestNewCallGraph(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(callGraphExampleCode)))
+	astFile, fset := astBundle.GetBundle()
+
+	graph := NewCallGraph(fset, []*ast.File{astFile})
+
+	getCallees := func(id string) []string {
+		var callees []string
+		for _, edge := range graph.CalleesOf(id) {
+			callees = append(callees, string(edge.Kind)+":"+edge.Callee)
+		}
+		return callees
+	}
+	require.Equal(t, []string{"METHOD:Service.log", "METHOD:Base.Close", "EXTERNAL:fmt.Println", "EXTERNAL:strings.ToUpper"}, getCallees("Service.Run"))
+	require.Equal(t, []string{
+		"FUNCTION:NewService",
+		"METHOD:Service.Run",
+		"METHOD:Service.log",
+		"DEFINE:main.func1",
+		"CLOSURE:main.func1",
+		"FUNCTION:pick",
+		"DYNAMIC:handler",
+	}, getCallees("main"))
+	require.Equal(t, []string{"CLOSURE:main.func1.1", "DEFINE:main.func1.1"}, getCallees("main.func1"))
+
+	node, ok := graph.GetNode("strings.TrimSpace")
+	require.True(t, ok)
+	require.True(t, node.IsExternal())
+	require.Equal(t, "TrimSpace", node.Name)
+
+	edges := graph.CallersOf("clean")
+	require.Len(t, edges, 1)
+	require.Equal(t, "NewService", edges[0].Caller)
+	require.Equal(t, 18, edges[0].Position.Line)
+
+	require.Equal(t, []string{"NewService", "Service.Run", "Service.log", "main.func1", "pick", "clean", "Base.Close", "fmt.Println", "strings.ToUpper", "main.func1.1", "strings.TrimSpace"}, graph.ReachableFrom("main"))
+	require.Equal(t, []string{"NewService", "main"}, graph.TransitiveCallersOf("clean"))
+	require.Empty(t, graph.TransitiveCallersOf("unused"))
+}
+
+// TestCallGraph_Export tests exporting the call graph to DOT and JSON
+// Verifies duplicate caller and callee pairs are drawn once in DOT
+//
+// TestCallGraph_Export 测试将调用图导出为 DOT 和 JSON
+// 验证 DOT 中重复的调用者和被调用者对只画一次
+func TestCallGraph_Export(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(callGraphExampleCode)))
+	astFile, fset := astBundle.GetBundle()
+
+	graph := NewCallGraph(fset, []*ast.File{astFile})
+
+	dot := graph.ToDOT()
+	t.Log(dot)
+	require.True(t, strings.HasPrefix(dot, "digraph calls {\n"))
+	require.Contains(t, dot, "\t\"fmt.Println\" [shape=box];\n")
+	require.Contains(t, dot, "\t\"handler\" [style=dashed];\n")
+	require.Contains(t, dot, "\t\"main\" -> \"main.func1\" [style=dotted];\n")
+	require.Equal(t, 1, strings.Count(dot, "\t\"main\" -> \"main.func1\""))
+
+	data, err := graph.ToJSON()
+	require.NoError(t, err)
+	var result struct {
+		Nodes []struct {
+			ID      string `json:"id"`
+			PkgPath string `json:"pkgPath"`
+		} `json:"nodes"`
+		Edges []struct {
+			Caller string `json:"caller"`
+			Callee string `json:"callee"`
+			Kind   string `json:"kind"`
+			Line   int    `json:"line"`
+		} `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Equal(t, len(graph.GetNodes()), len(result.Nodes))
+	require.Equal(t, len(graph.GetEdges()), len(result.Edges))
+	require.Equal(t, "NewService", result.Edges[0].Caller)
+	require.Equal(t, "FUNCTION", result.Edges[0].Kind)
+}
+
+// TestNewCallGraphInPackage tests building the call graph of a package of several files
+// Verifies calls across files are found
+//
+// TestNewCallGraphInPackage 测试构建包含多个文件的包的调用图
+// 验证能找到跨文件的调用
+func TestNewCallGraphInPackage(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/store", map[string]string{
+		"store.go": "package store\n\ntype Store struct{}\n\nfunc NewStore() *Store { return &Store{} }\n\nfunc (s *Store) Load() { s.parse() }\n\nfunc (s *Store) parse() {}\n",
+		"run.go":   "package store\n\nfunc Run() {\n\tstore := NewStore()\n\tstore.Load()\n}\n\nfunc Check() { _ = NewStore() }\n",
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	graph := NewCallGraphInPackage(pkgBundle)
+	var callers []string
+	for _, edge := range graph.CallersOf("NewStore") {
+		callers = append(callers, edge.Caller)
+	}
+	t.Log(callers)
+	require.ElementsMatch(t, []string{"Run", "Check"}, callers)
+	require.ElementsMatch(t, []string{"NewStore", "Store.Load", "Store.parse"}, graph.ReachableFrom("Run"))
+}
+
+// TestNewCallGraphInPackage_TypedValues tests resolving method calls on values declared in other files and on promoted fields
+// Verifies package-level values are typed across files and fields are found through embedded structs
+//
+// TestNewCallGraphInPackage_TypedValues 测试解析声明在其他文件中的值以及提升字段上的方法调用
+// 验证包级值能跨文件推断类型，字段能通过嵌入的结构体找到
+func TestNewCallGraphInPackage_TypedValues(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/client", map[string]string{
+		"client.go": `package client
+
+type Conn struct{}
+
+func (c *Conn) Send() {}
+
+type Base struct {
+	conn *Conn
+}
+
+type Other struct {
+	conn *Conn
+}
+
+type Client struct {
+	Base
+}
+
+type Mixed struct {
+	Base
+	Other
+}
+
+var defaultClient = &Client{}
+
+var mixed Mixed
+`,
+		"run.go": `package client
+
+func run() {
+	defaultClient.conn.Send()
+	mixed.conn.Send()
+}
+`,
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	graph := NewCallGraphInPackage(pkgBundle)
+	var callees []string
+	for _, edge := range graph.CalleesOf("run") {
+		callees = append(callees, string(edge.Kind)+":"+edge.Callee)
+	}
+	t.Log(callees)
+	require.Equal(t, []string{"METHOD:Conn.Send", "DYNAMIC:mixed.conn.Send"}, callees)
+}
@@ -2,7 +2,12 @@ package syntaxgo_search
 
 import (
 	"go/ast"
+	"go/doc/comment"
+	"slices"
 	"strings"
+	"unicode"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
 )
 
 // GetFunctionComment extracts the documentation comment of the specified function.
@@ -16,3 +21,229 @@ func GetFunctionComment(funcDecl *ast.FuncDecl) string {
 	}
 	return strings.Join(commentLines, "\n")
 }
+
+// DocLanguage is the language of a doc comment paragraph.
+// DocLanguage 是文档注释段落的语言。
+type DocLanguage string
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	DOC_LANGUAGE_EN DocLanguage = "EN"
+	DOC_LANGUAGE_ZH DocLanguage = "ZH"
+)
+
+// DocComment is a comment group parsed with go/doc/comment into its parts.
+// DocComment 是使用 go/doc/comment 解析为各个部分的注释组。
+type DocComment struct {
+	Group      *ast.CommentGroup         // Raw comment group / 原始注释组
+	Text       string                    // Comment text without markers and directives / 不含注释符号和指令的注释文本
+	Paragraphs []string                  // Text of the paragraphs and list items, line breaks kept / 段落和列表项的文本，保留换行
+	Headings   []string                  // Text of the headings / 标题的文本
+	CodeBlocks []string                  // Text of the indented code blocks / 缩进代码块的文本
+	Deprecated string                    // Text after "Deprecated:", empty when not deprecated / "Deprecated:" 之后的文本，未弃用时为空
+	Directives []*syntaxgo_ast.Directive // Directives such as //go:generate / 指令，比如 //go:generate
+	Doc        *comment.Doc              // Parsed document / 解析后的文档
+}
+
+// ParseDocComment parses the comment group, nil when the group is nil.
+// ParseDocComment 解析注释组，注释组为 nil 时返回 nil。
+func ParseDocComment(group *ast.CommentGroup) *DocComment {
+	if group == nil {
+		return nil
+	}
+	docComment := &DocComment{Group: group, Text: group.Text()}
+	for _, item := range group.List {
+		if name, args, ok := syntaxgo_ast.ParseDirective(item.Text); ok {
+			docComment.Directives = append(docComment.Directives, &syntaxgo_ast.Directive{Name: name, Args: args, Comment: item})
+		}
+	}
+	var parser comment.Parser
+	docComment.Doc = parser.Parse(docComment.Text)
+	var walk func(blocks []comment.Block)
+	walk = func(blocks []comment.Block) {
+		for _, block := range blocks {
+			switch x := block.(type) {
+			case *comment.Paragraph:
+				text := renderCommentText(x.Text)
+				if deprecated, ok := strings.CutPrefix(text, "Deprecated:"); ok && docComment.Deprecated == "" {
+					docComment.Deprecated = strings.TrimSpace(deprecated)
+					continue
+				}
+				docComment.Paragraphs = append(docComment.Paragraphs, text)
+			case *comment.Heading:
+				docComment.Headings = append(docComment.Headings, renderCommentText(x.Text))
+			case *comment.Code:
+				docComment.CodeBlocks = append(docComment.CodeBlocks, x.Text)
+			case *comment.List:
+				for _, listItem := range x.Items {
+					walk(listItem.Content)
+				}
+			}
+		}
+	}
+	walk(docComment.Doc.Content)
+	return docComment
+}
+
+// renderCommentText joins the text of a paragraph or heading, keeping the line breaks.
+// renderCommentText 拼接段落或标题的文本，保留换行。
+func renderCommentText(texts []comment.Text) string {
+	var result strings.Builder
+	for _, text := range texts {
+		switch x := text.(type) {
+		case comment.Plain:
+			result.WriteString(string(x))
+		case comment.Italic:
+			result.WriteString(string(x))
+		case *comment.Link:
+			result.WriteString(renderCommentText(x.Text))
+		case *comment.DocLink:
+			result.WriteString(renderCommentText(x.Text))
+		}
+	}
+	return result.String()
+}
+
+// SplitLanguages separates the paragraphs by language, keeping the order in each language.
+// A line starting with a marker like "EN:", "ZH:", "[EN]" or "[ZH]" takes the marker's language and the marker is removed,
+// other lines holding Chinese characters are ZH and the rest are EN. Adjacent lines of one language form one paragraph.
+// An inline pair like "Method name / 方法名" is split into its two languages.
+//
+// SplitLanguages 按语言拆分段落，并保持每种语言内的顺序。
+// 以 "EN:"、"ZH:"、"[EN]" 或 "[ZH]" 等标记开头的行归为标记所示的语言并去掉标记，
+// 其余包含汉字的行归为 ZH，其他行归为 EN。相邻的同语言行组成一个段落。
+// 类似 "Method name / 方法名" 的行内双语会拆分为两种语言。
+func (docComment *DocComment) SplitLanguages() map[DocLanguage][]string {
+	results := map[DocLanguage][]string{}
+	for _, paragraph := range docComment.Paragraphs {
+		var lastLanguage DocLanguage
+		var lines []string
+		flush := func() {
+			if len(lines) > 0 {
+				results[lastLanguage] = append(results[lastLanguage], strings.Join(lines, "\n"))
+				lines = nil
+			}
+		}
+		for _, line := range strings.Split(paragraph, "\n") {
+			for _, part := range splitDocLine(line) {
+				language, text := detectDocLanguage(part)
+				if language != lastLanguage {
+					flush()
+					lastLanguage = language
+				}
+				lines = append(lines, text)
+			}
+		}
+		flush()
+	}
+	return results
+}
+
+// GetLanguageText returns the paragraphs of the language joined with blank lines.
+// GetLanguageText 返回该语言的段落，以空行连接。
+func (docComment *DocComment) GetLanguageText(language DocLanguage) string {
+	return strings.Join(docComment.SplitLanguages()[language], "\n\n")
+}
+
+// docLanguageMarkers maps the language markers to their languages.
+// docLanguageMarkers 是语言标记到语言的映射。
+var docLanguageMarkers = map[string]DocLanguage{
+	"EN:": DOC_LANGUAGE_EN, "[EN]": DOC_LANGUAGE_EN,
+	"ZH:": DOC_LANGUAGE_ZH, "[ZH]": DOC_LANGUAGE_ZH,
+	"CN:": DOC_LANGUAGE_ZH, "[CN]": DOC_LANGUAGE_ZH,
+}
+
+// splitDocLine splits an inline pair like "Method name / 方法名" into its EN and ZH parts.
+// splitDocLine 将类似 "Method name / 方法名" 的行内双语拆分为 EN 和 ZH 两部分。
+func splitDocLine(line string) []string {
+	if enText, zhText, ok := strings.Cut(line, " / "); ok && !hasHanChar(enText) && hasHanChar(zhText) {
+		return []string{enText, zhText}
+	}
+	return []string{line}
+}
+
+func hasHanChar(text string) bool {
+	for _, c := range text {
+		if unicode.Is(unicode.Han, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDocLanguage returns the language of the line and the line without its marker.
+// detectDocLanguage 返回该行的语言，以及去掉标记后的行。
+func detectDocLanguage(line string) (DocLanguage, string) {
+	for marker, language := range docLanguageMarkers {
+		if len(line) >= len(marker) && strings.EqualFold(line[:len(marker)], marker) {
+			return language, strings.TrimSpace(line[len(marker):])
+		}
+	}
+	if hasHanChar(line) {
+		return DOC_LANGUAGE_ZH, line
+	}
+	return DOC_LANGUAGE_EN, line
+}
+
+// NodeDocs holds the comments attached to a declaration, each is nil when missing.
+// NodeDocs 保存附加在声明上的注释，缺失时各项为 nil。
+type NodeDocs struct {
+	Doc         *DocComment // Doc comment of the node / 节点的文档注释
+	GroupDoc    *DocComment // Doc comment of the parenthesized group holding the spec / 包含该规格的括号分组的文档注释
+	LineComment *DocComment // Trailing line comment / 行尾注释
+}
+
+// GetNodeDocs returns the comments of a declaration in the file, supporting functions, declaration groups,
+// type, const, var and import specs, struct fields and interface methods.
+// The doc of an unparenthesized declaration like `type A int` is the doc of its spec.
+//
+// GetNodeDocs 返回文件中某个声明的注释，支持函数、声明分组、
+// 类型、常量、变量和导入规格，以及结构体字段和接口方法。
+// 类似 `type A int` 这种不带括号的声明，其文档注释就是其规格的文档注释。
+func GetNodeDocs(astFile *ast.File, node ast.Node) *NodeDocs {
+	nodeDocs := &NodeDocs{}
+	switch x := node.(type) {
+	case *ast.FuncDecl:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+	case *ast.GenDecl:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+	case *ast.Field:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+		nodeDocs.LineComment = ParseDocComment(x.Comment)
+	case *ast.TypeSpec:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+		nodeDocs.LineComment = ParseDocComment(x.Comment)
+	case *ast.ValueSpec:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+		nodeDocs.LineComment = ParseDocComment(x.Comment)
+	case *ast.ImportSpec:
+		nodeDocs.Doc = ParseDocComment(x.Doc)
+		nodeDocs.LineComment = ParseDocComment(x.Comment)
+	}
+	if spec, ok := node.(ast.Spec); ok {
+		if genDecl := findSpecGenDecl(astFile, spec); genDecl != nil {
+			if genDecl.Lparen.IsValid() {
+				nodeDocs.GroupDoc = ParseDocComment(genDecl.Doc)
+			} else if nodeDocs.Doc == nil {
+				nodeDocs.Doc = ParseDocComment(genDecl.Doc)
+			}
+		}
+	}
+	if nodeDocs.Doc != nil {
+		for _, directive := range nodeDocs.Doc.Directives {
+			directive.Node = node
+		}
+	}
+	return nodeDocs
+}
+
+// findSpecGenDecl returns the top-level declaration holding the spec, nil when not found.
+// findSpecGenDecl 返回包含该规格的顶层声明，找不到时返回 nil。
+func findSpecGenDecl(astFile *ast.File, spec ast.Spec) *ast.GenDecl {
+	for _, decl := range astFile.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && slices.Contains(genDecl.Specs, spec) {
+			return genDecl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const documentationExampleCode = `package example
+
+// Config holds the settings.
+// Config 保存配置。
+//
+// Example:
+//
+//	cfg := NewConfig()
+//
+// Deprecated: use Options instead.
+//
+//go:generate stringer -type=Config
+type Config struct {
+	// Name is the name.
+	// Name 是名称。
+	Name string // required
+
+	Port int // the port / 端口
+}
+
+// Store reads the data.
+type Store interface {
+	// Get returns the value.
+	Get(key string) string
+}
+
+// Limits of the service.
+const (
+	// MaxSize is the max size.
+	MaxSize = 10 // bytes
+	MinSize = 1
+)
+
+// Version is declared alone.
+var Version = "v1"
+`
+
+// TestParseDocComment tests parsing a doc comment into paragraphs, code blocks, deprecation and directives
+// Verifies bilingual paragraphs are split by language
+//
+// TestParseDocComment 测试将文档注释解析为段落、代码块、弃用说明和指令
+// 验证中英双语段落会按语言拆分
+func TestParseDocComment(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(documentationExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	genDecl := astFile.Decls[0].(*ast.GenDecl)
+	docComment := ParseDocComment(genDecl.Doc)
+	t.Log(docComment.Paragraphs)
+	require.Equal(t, []string{"Config holds the settings.\nConfig 保存配置。", "Example:"}, docComment.Paragraphs)
+	require.Equal(t, []string{"cfg := NewConfig()\n"}, docComment.CodeBlocks)
+	require.Equal(t, "use Options instead.", docComment.Deprecated)
+	require.Len(t, docComment.Directives, 1)
+	require.Equal(t, "go:generate", docComment.Directives[0].Name)
+	require.Equal(t, "stringer -type=Config", docComment.Directives[0].Args)
+	require.NotContains(t, docComment.Text, "go:generate")
+
+	languages := docComment.SplitLanguages()
+	require.Equal(t, []string{"Config holds the settings.", "Example:"}, languages[DOC_LANGUAGE_EN])
+	require.Equal(t, []string{"Config 保存配置。"}, languages[DOC_LANGUAGE_ZH])
+	require.Equal(t, "Config holds the settings.\n\nExample:", docComment.GetLanguageText(DOC_LANGUAGE_EN))
+
+	require.Nil(t, ParseDocComment(nil))
+}
+
+// TestDocComment_SplitLanguages_Markers tests splitting paragraphs by language markers
+// Verifies the markers are removed and override the script detection
+//
+// TestDocComment_SplitLanguages_Markers 测试按语言标记拆分段落
+// 验证标记会被去掉，并且优先于按文字的判断
+func TestDocComment_SplitLanguages_Markers(t *testing.T) {
+	docComment := ParseDocComment(&ast.CommentGroup{List: []*ast.Comment{
+		{Text: "// EN: Run starts the job."},
+		{Text: "// [ZH] Run starts the job (启动任务)."},
+		{Text: "// zh: 第二行"},
+	}})
+	languages := docComment.SplitLanguages()
+	require.Equal(t, []string{"Run starts the job."}, languages[DOC_LANGUAGE_EN])
+	require.Equal(t, []string{"Run starts the job (启动任务).\n第二行"}, languages[DOC_LANGUAGE_ZH])
+}
+
+// TestGetNodeDocs tests reading the comments of each declaration kind
+// Verifies group docs, line comments, struct fields and interface methods
+//
+// TestGetNodeDocs 测试读取各种声明的注释
+// 验证分组文档、行尾注释、结构体字段和接口方法
+func TestGetNodeDocs(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(documentationExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	typeSpecs := FindTypes(astFile)
+	nodeDocs := GetNodeDocs(astFile, typeSpecs[0])
+	require.Equal(t, "use Options instead.", nodeDocs.Doc.Deprecated)
+	require.Equal(t, typeSpecs[0], nodeDocs.Doc.Directives[0].Node)
+	require.Nil(t, nodeDocs.GroupDoc)
+
+	fields := typeSpecs[0].Type.(*ast.StructType).Fields.List
+	nodeDocs = GetNodeDocs(astFile, fields[0])
+	require.Equal(t, "Name 是名称。", nodeDocs.Doc.GetLanguageText(DOC_LANGUAGE_ZH))
+	require.Equal(t, "required\n", nodeDocs.LineComment.Text)
+	nodeDocs = GetNodeDocs(astFile, fields[1])
+	require.Nil(t, nodeDocs.Doc)
+	require.Equal(t, "端口", nodeDocs.LineComment.GetLanguageText(DOC_LANGUAGE_ZH))
+	require.Equal(t, "the port", nodeDocs.LineComment.GetLanguageText(DOC_LANGUAGE_EN))
+
+	method := typeSpecs[1].Type.(*ast.InterfaceType).Methods.List[0]
+	require.Equal(t, []string{"Get returns the value."}, GetNodeDocs(astFile, method).Doc.Paragraphs)
+
+	constDecl := astFile.Decls[2].(*ast.GenDecl)
+	nodeDocs = GetNodeDocs(astFile, constDecl.Specs[0])
+	require.Equal(t, []string{"MaxSize is the max size."}, nodeDocs.Doc.Paragraphs)
+	require.Equal(t, []string{"Limits of the service."}, nodeDocs.GroupDoc.Paragraphs)
+	require.Equal(t, []string{"bytes"}, nodeDocs.LineComment.Paragraphs)
+	nodeDocs = GetNodeDocs(astFile, constDecl.Specs[1])
+	require.Nil(t, nodeDocs.Doc)
+	require.NotNil(t, nodeDocs.GroupDoc)
+
+	varDecl := astFile.Decls[3].(*ast.GenDecl)
+	nodeDocs = GetNodeDocs(astFile, varDecl.Specs[0])
+	require.Equal(t, []string{"Version is declared alone."}, nodeDocs.Doc.Paragraphs)
+	require.Nil(t, nodeDocs.GroupDoc)
+}
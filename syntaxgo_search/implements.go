@@ -0,0 +1,419 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// ImplementsOptions controls how interface implementations are found.
+// ImplementsOptions 控制如何查找接口的实现。
+type ImplementsOptions struct {
+	typeCheck     bool // Compare signatures with go/types instead of the syntax / 使用 go/types 而非语法比较签名
+	includeModule bool // Also search the packages of the module imported by the package / 同时搜索该包导入的本模块内的包
+}
+
+// NewImplementsOptions creates options comparing method signatures syntactically.
+// NewImplementsOptions 创建按语法比较方法签名的选项。
+func NewImplementsOptions() *ImplementsOptions {
+	return &ImplementsOptions{}
+}
+
+// SetTypeCheck sets whether to type-check the package and compare signatures exactly.
+// SetTypeCheck 设置是否对包进行类型检查并精确比较签名。
+func (opts *ImplementsOptions) SetTypeCheck(typeCheck bool) *ImplementsOptions {
+	opts.typeCheck = typeCheck
+	return opts
+}
+
+// SetIncludeModule sets whether to also search the packages of the module imported by the package, this needs type checking.
+// SetIncludeModule 设置是否同时搜索该包导入的本模块内的包，这需要类型检查。
+func (opts *ImplementsOptions) SetIncludeModule(includeModule bool) *ImplementsOptions {
+	opts.includeModule = includeModule
+	return opts
+}
+
+// MethodMismatch is an interface method that a type is missing or declares with another signature.
+// MethodMismatch 是类型缺少的接口方法，或者类型以不同签名声明的接口方法。
+type MethodMismatch struct {
+	Name     string // Method name / 方法名
+	Expected string // Signature required by the interface / 接口要求的签名
+	Actual   string // Signature declared by the type, empty when the method is missing / 类型声明的签名，缺少该方法时为空
+}
+
+// Implementation is the relation between a type and an interface.
+// A near miss has Implements false and lists the methods to fix in Mismatches.
+//
+// Implementation 是类型与接口之间的关系。
+// 差一点实现时 Implements 为 false，并在 Mismatches 中列出需要修正的方法。
+type Implementation struct {
+	InterfaceName string            // Interface name, qualified when declared in another package / 接口名，声明在其他包时带包名
+	TypeName      string            // Type name, qualified when declared in another package / 类型名，声明在其他包时带包名
+	Implements    bool              // Whether *T implements the interface / *T 是否实现了该接口
+	IsPointer     bool              // Whether only *T implements the interface, not T / 是否仅 *T 实现了该接口，而 T 没有
+	Mismatches    []*MethodMismatch // Missing or mismatched methods / 缺少或不匹配的方法
+}
+
+// Implementations finds the types of the package implementing the interface, with the near misses,
+// which are types declaring at least one method of the interface.
+// Signatures are compared syntactically by default, so types written in different ways do not match,
+// such as an alias and its target, and methods of embedded types from other packages are unknown.
+//
+// Implementations 查找包中实现该接口的类型，以及差一点实现的类型，即至少声明了接口中一个方法的类型。
+// 默认按语法比较签名，因此写法不同的类型不会匹配，比如别名与其目标类型，
+// 且无法得知来自其他包的嵌入类型的方法。
+func Implementations(pkgBundle *syntaxgo_ast.PackageBundle, interfaceName string, opts *ImplementsOptions) ([]*Implementation, error) {
+	if opts.typeCheck {
+		return findImplementationsWithTypes(pkgBundle, opts, func(checker *implementsChecker) ([]*Implementation, error) {
+			iface, ok := checker.lookupInterface(interfaceName)
+			if !ok {
+				return nil, erero.Errorf("interface %s is not found", interfaceName)
+			}
+			var results []*Implementation
+			for _, named := range checker.named {
+				if _, ok := named.Underlying().(*types.Interface); ok {
+					continue
+				}
+				if result, ok := checker.compare(named, iface); ok {
+					results = append(results, result)
+				}
+			}
+			return results, nil
+		})
+	}
+	if opts.includeModule {
+		return nil, erero.New("including the packages of the module needs type checking")
+	}
+	resolver := NewMethodSetResolverInPackage(pkgBundle)
+	ifaceSet, err := resolver.lookupInterfaceSet(interfaceName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var results []*Implementation
+	for _, typeName := range resolver.getConcreteTypeNames() {
+		if result, ok := resolver.compare(typeName, ifaceSet); ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// ImplementedInterfaces finds the interfaces of the package implemented by the type, with the near misses.
+// Empty interfaces are skipped, since every type implements them.
+//
+// ImplementedInterfaces 查找包中被该类型实现的接口，以及差一点实现的接口。
+// 会跳过空接口，因为所有类型都实现了空接口。
+func ImplementedInterfaces(pkgBundle *syntaxgo_ast.PackageBundle, typeName string, opts *ImplementsOptions) ([]*Implementation, error) {
+	if opts.typeCheck {
+		return findImplementationsWithTypes(pkgBundle, opts, func(checker *implementsChecker) ([]*Implementation, error) {
+			named, ok := checker.lookupNamed(typeName)
+			if !ok {
+				return nil, erero.Errorf("type %s is not found", typeName)
+			}
+			var results []*Implementation
+			for _, candidate := range checker.named {
+				iface, ok := candidate.Underlying().(*types.Interface)
+				if !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() || candidate == named {
+					continue
+				}
+				if result, ok := checker.compare(named, candidate); ok {
+					results = append(results, result)
+				}
+			}
+			return results, nil
+		})
+	}
+	if opts.includeModule {
+		return nil, erero.New("including the packages of the module needs type checking")
+	}
+	resolver := NewMethodSetResolverInPackage(pkgBundle)
+	if _, ok := resolver.typeSpecs[typeName]; !ok {
+		return nil, erero.Errorf("type %s is not found", typeName)
+	}
+	var results []*Implementation
+	for _, name := range resolver.getInterfaceNames() {
+		if name == typeName {
+			continue
+		}
+		ifaceSet, err := resolver.lookupInterfaceSet(name)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if len(ifaceSet.Methods) == 0 {
+			continue
+		}
+		if result, ok := resolver.compare(typeName, ifaceSet); ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// lookupInterfaceSet returns the method set of the interface declared in the files.
+// lookupInterfaceSet 返回文件中声明的接口的方法集。
+func (resolver *MethodSetResolver) lookupInterfaceSet(interfaceName string) (*MethodSet, error) {
+	typeSpec, ok := resolver.typeSpecs[resolver.resolveAlias(interfaceName)]
+	if !ok {
+		return nil, erero.Errorf("interface %s is not found", interfaceName)
+	}
+	if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok {
+		return nil, erero.Errorf("type %s is not an interface", interfaceName)
+	}
+	return resolver.MethodSet(typeSpec.Name.Name, false)
+}
+
+// getConcreteTypeNames returns the sorted names of the declared types, skipping interfaces and aliases.
+// getConcreteTypeNames 返回已声明类型的名称（已排序），跳过接口和别名。
+func (resolver *MethodSetResolver) getConcreteTypeNames() []string {
+	var names []string
+	for name, typeSpec := range resolver.typeSpecs {
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); !ok && !typeSpec.Assign.IsValid() {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// getInterfaceNames returns the sorted names of the declared interfaces.
+// getInterfaceNames 返回已声明接口的名称（已排序）。
+func (resolver *MethodSetResolver) getInterfaceNames() []string {
+	var names []string
+	for name, typeSpec := range resolver.typeSpecs {
+		if _, ok := typeSpec.Type.(*ast.InterfaceType); ok && !typeSpec.Assign.IsValid() {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// compare checks the type against the interface method set syntactically, false when no method name matches.
+// compare 按语法将类型与接口方法集进行比较，没有任何方法名匹配时返回 false。
+func (resolver *MethodSetResolver) compare(typeName string, ifaceSet *MethodSet) (*Implementation, bool) {
+	valueSet, err := resolver.MethodSet(typeName, false)
+	if err != nil {
+		return nil, false
+	}
+	pointerSet, err := resolver.MethodSet(typeName, true)
+	if err != nil {
+		return nil, false
+	}
+	result := &Implementation{InterfaceName: ifaceSet.TypeName, TypeName: typeName}
+	var matched bool
+	var valueOnly = true
+	for _, method := range ifaceSet.Methods {
+		expected := renderFuncTypeSignature(method.GetFuncType())
+		entry, ok := pointerSet.GetMethod(method.Name)
+		if !ok {
+			result.Mismatches = append(result.Mismatches, &MethodMismatch{Name: method.Name, Expected: expected})
+			continue
+		}
+		matched = true
+		if actual := renderFuncTypeSignature(entry.GetFuncType()); actual != expected {
+			result.Mismatches = append(result.Mismatches, &MethodMismatch{Name: method.Name, Expected: expected, Actual: actual})
+			continue
+		}
+		if _, ok := valueSet.GetMethod(method.Name); !ok {
+			valueOnly = false
+		}
+	}
+	if !matched && len(ifaceSet.Methods) > 0 {
+		return nil, false
+	}
+	result.Implements = len(result.Mismatches) == 0
+	result.IsPointer = result.Implements && !valueOnly
+	return result, true
+}
+
+// renderFuncTypeSignature renders the signature without parameter names, such as "func(int64) (*User, error)".
+// renderFuncTypeSignature 渲染不带参数名的签名，比如 "func(int64) (*User, error)"。
+func renderFuncTypeSignature(funcType *ast.FuncType) string {
+	if funcType == nil {
+		return ""
+	}
+	renderList := func(fieldList *ast.FieldList) []string {
+		var items []string
+		if fieldList == nil {
+			return items
+		}
+		for _, field := range fieldList.List {
+			typeText := types.ExprString(field.Type)
+			for range max(1, len(field.Names)) {
+				items = append(items, typeText)
+			}
+		}
+		return items
+	}
+	return joinSignature(renderList(funcType.Params), renderList(funcType.Results))
+}
+
+// joinSignature joins the parameter and result types into a signature text.
+// joinSignature 将参数类型和结果类型拼接为签名文本。
+func joinSignature(params []string, results []string) string {
+	signature := "func(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + results[0]
+	default:
+		return signature + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// implementsChecker compares named types with interfaces using go/types.
+// implementsChecker 使用 go/types 比较命名类型与接口。
+type implementsChecker struct {
+	pkg   *types.Package // Package being searched / 被搜索的包
+	named []*types.Named // Named types of the package and the included packages, sorted / 包及其包含的包中的命名类型，已排序
+}
+
+// findImplementationsWithTypes type-checks the package when needed, collects the named types and runs the search.
+// findImplementationsWithTypes 在需要时对包进行类型检查，收集命名类型并执行搜索。
+func findImplementationsWithTypes(pkgBundle *syntaxgo_ast.PackageBundle, opts *ImplementsOptions, run func(checker *implementsChecker) ([]*Implementation, error)) ([]*Implementation, error) {
+	typesBundle := pkgBundle.GetTypesBundle()
+	if typesBundle == nil {
+		var err error
+		if typesBundle, err = pkgBundle.TypeCheck(); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	checker := &implementsChecker{pkg: typesBundle.GetPackage()}
+	var packages = []*types.Package{checker.pkg}
+	if opts.includeModule {
+		importer := syntaxgo_ast.NewOfflineImporter(pkgBundle.GetFileSet(), pkgBundle.GetRoot())
+		if modulePath, ok := importer.GetModulePath(); ok {
+			packages = append(packages, getModuleImports(checker.pkg, modulePath)...)
+		}
+	}
+	for _, pkg := range packages {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || typeName.IsAlias() {
+				continue
+			}
+			if named, ok := typeName.Type().(*types.Named); ok && named.TypeParams().Len() == 0 {
+				checker.named = append(checker.named, named)
+			}
+		}
+	}
+	return run(checker)
+}
+
+// getModuleImports returns the packages of the module imported by the package, directly or indirectly, sorted by path.
+// getModuleImports 返回该包直接或间接导入的本模块内的包，按路径排序。
+func getModuleImports(pkg *types.Package, modulePath string) []*types.Package {
+	var results []*types.Package
+	var seen = map[string]bool{pkg.Path(): true}
+	var walk func(pkg *types.Package)
+	walk = func(pkg *types.Package) {
+		for _, imported := range pkg.Imports() {
+			if seen[imported.Path()] {
+				continue
+			}
+			seen[imported.Path()] = true
+			if imported.Path() == modulePath || strings.HasPrefix(imported.Path(), modulePath+"/") {
+				results = append(results, imported)
+				walk(imported)
+			}
+		}
+	}
+	walk(pkg)
+	slices.SortFunc(results, func(a, b *types.Package) int {
+		return strings.Compare(a.Path(), b.Path())
+	})
+	return results
+}
+
+// lookupNamed finds a named type by its name, qualified by the package name when declared in another package.
+// lookupNamed 根据名称查找命名类型，声明在其他包时使用包名限定。
+func (checker *implementsChecker) lookupNamed(name string) (*types.Named, bool) {
+	for _, named := range checker.named {
+		if checker.getName(named) == name {
+			return named, true
+		}
+	}
+	return nil, false
+}
+
+// lookupInterface finds a named interface by its name, qualified by the package name when declared in another package.
+// lookupInterface 根据名称查找命名接口，声明在其他包时使用包名限定。
+func (checker *implementsChecker) lookupInterface(name string) (*types.Named, bool) {
+	named, ok := checker.lookupNamed(name)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := named.Underlying().(*types.Interface); !ok {
+		return nil, false
+	}
+	return named, true
+}
+
+// getName returns the name of the type, qualified by the package name when declared in another package.
+// getName 返回类型的名称，声明在其他包时使用包名限定。
+func (checker *implementsChecker) getName(named *types.Named) string {
+	return types.TypeString(named, checker.qualifier)
+}
+
+func (checker *implementsChecker) qualifier(pkg *types.Package) string {
+	if pkg == checker.pkg {
+		return ""
+	}
+	return pkg.Name()
+}
+
+// compare checks the type against the interface exactly, false when no method name matches.
+// compare 精确地将类型与接口进行比较，没有任何方法名匹配时返回 false。
+func (checker *implementsChecker) compare(named *types.Named, ifaceNamed *types.Named) (*Implementation, bool) {
+	iface := ifaceNamed.Underlying().(*types.Interface)
+	pointer := types.NewPointer(named)
+	result := &Implementation{InterfaceName: checker.getName(ifaceNamed), TypeName: checker.getName(named)}
+	var matched bool
+	for idx := range iface.NumMethods() {
+		method := iface.Method(idx)
+		expected := checker.renderSignature(method.Type().(*types.Signature))
+		object, _, _ := types.LookupFieldOrMethod(pointer, false, method.Pkg(), method.Name())
+		switch x := object.(type) {
+		case *types.Func:
+			matched = true
+			if !types.Identical(x.Type(), method.Type()) {
+				result.Mismatches = append(result.Mismatches, &MethodMismatch{Name: method.Name(), Expected: expected, Actual: checker.renderSignature(x.Type().(*types.Signature))})
+			}
+		case *types.Var:
+			matched = true
+			result.Mismatches = append(result.Mismatches, &MethodMismatch{Name: method.Name(), Expected: expected, Actual: "field " + types.TypeString(x.Type(), checker.qualifier)})
+		default:
+			result.Mismatches = append(result.Mismatches, &MethodMismatch{Name: method.Name(), Expected: expected})
+		}
+	}
+	if !matched && iface.NumMethods() > 0 {
+		return nil, false
+	}
+	result.Implements = types.Implements(pointer, iface)
+	result.IsPointer = result.Implements && !types.Implements(named, iface)
+	return result, true
+}
+
+// renderSignature renders the signature without parameter names, in the same form as the syntactic comparison.
+// renderSignature 渲染不带参数名的签名，与按语法比较时的形式相同。
+func (checker *implementsChecker) renderSignature(signature *types.Signature) string {
+	renderTuple := func(tuple *types.Tuple, variadic bool) []string {
+		var items []string
+		for idx := range tuple.Len() {
+			typ := tuple.At(idx).Type()
+			if variadic && idx == tuple.Len()-1 {
+				items = append(items, "..."+types.TypeString(typ.(*types.Slice).Elem(), checker.qualifier))
+				continue
+			}
+			items = append(items, types.TypeString(typ, checker.qualifier))
+		}
+		return items
+	}
+	return joinSignature(renderTuple(signature.Params(), signature.Variadic()), renderTuple(signature.Results(), false))
+}
@@ -0,0 +1,186 @@
+package syntaxgo_search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const implementsServiceCode = `package service
+
+import "example.com/demo/store"
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+type UserService interface {
+	Get(id int64) (*User, error)
+	Delete(id int64) error
+}
+
+type Closer interface {
+	Close() error
+}
+
+type MemoryService struct {
+	store.Cache
+}
+
+func (s *MemoryService) Get(id int64) (*User, error) { return nil, nil }
+
+func (s *MemoryService) Delete(id int64) error { return nil }
+
+type LegacyService struct{}
+
+func (s LegacyService) Get(id int) (*User, error) { return nil, nil }
+
+type ReadOnlyService struct {
+	Delete bool
+}
+
+func (s ReadOnlyService) Get(userID int64) (*User, error) { return nil, nil }
+
+type CachedService struct {
+	*MemoryService
+}
+
+var _ = store.Cache{}
+`
+
+const implementsStoreCode = `package store
+
+type Cache struct{}
+
+func (c *Cache) Close() error { return nil }
+
+type Flusher interface {
+	Flush() error
+	Close() error
+}
+`
+
+// newImplementsPackageBundle writes a small module and parses its service package.
+// newImplementsPackageBundle 写入一个小模块并解析其中的 service 包。
+func newImplementsPackageBundle(t *testing.T) *syntaxgo_ast.PackageBundle {
+	root := tests.NewTempModule(t, "example.com/demo", map[string]string{
+		"service/service.go": implementsServiceCode,
+		"store/store.go":     implementsStoreCode,
+	})
+	return rese.P1(syntaxgo_ast.NewPackageBundleV1(filepath.Join(root, "service")))
+}
+
+// TestImplementations tests finding the types implementing an interface syntactically
+// Verifies pointer-only implementations, mismatched signatures, missing methods and fields named like methods
+//
+// TestImplementations 测试按语法查找实现接口的类型
+// 验证仅指针实现、签名不匹配、缺少方法以及与方法同名的字段
+func TestImplementations(t *testing.T) {
+	pkgBundle := newImplementsPackageBundle(t)
+
+	results, err := Implementations(pkgBundle, "UserService", NewImplementsOptions())
+	require.NoError(t, err)
+	byName := map[string]*Implementation{}
+	for _, result := range results {
+		t.Log(result.TypeName, result.Implements, result.IsPointer, len(result.Mismatches))
+		byName[result.TypeName] = result
+	}
+	require.Len(t, results, 4)
+
+	require.True(t, byName["MemoryService"].Implements)
+	require.True(t, byName["MemoryService"].IsPointer)
+	require.True(t, byName["CachedService"].Implements)
+	require.False(t, byName["CachedService"].IsPointer)
+
+	legacy := byName["LegacyService"]
+	require.False(t, legacy.Implements)
+	require.Len(t, legacy.Mismatches, 2)
+	require.Equal(t, "Delete", legacy.Mismatches[0].Name)
+	require.Empty(t, legacy.Mismatches[0].Actual)
+	require.Equal(t, "Get", legacy.Mismatches[1].Name)
+	require.Equal(t, "func(int64) (*User, error)", legacy.Mismatches[1].Expected)
+	require.Equal(t, "func(int) (*User, error)", legacy.Mismatches[1].Actual)
+
+	readOnly := byName["ReadOnlyService"]
+	require.False(t, readOnly.Implements)
+	require.Len(t, readOnly.Mismatches, 1)
+	require.Equal(t, "Delete", readOnly.Mismatches[0].Name)
+
+	_, err = Implementations(pkgBundle, "User", NewImplementsOptions())
+	require.Error(t, err)
+	_, err = Implementations(pkgBundle, "UserService", NewImplementsOptions().SetIncludeModule(true))
+	require.Error(t, err)
+}
+
+// TestImplementations_TypeCheck tests finding implementations with type checking
+// Verifies methods promoted from an embedded type of another package are found
+//
+// TestImplementations_TypeCheck 测试使用类型检查查找实现
+// 验证能找到从其他包的嵌入类型提升而来的方法
+func TestImplementations_TypeCheck(t *testing.T) {
+	pkgBundle := newImplementsPackageBundle(t)
+
+	results, err := Implementations(pkgBundle, "Closer", NewImplementsOptions().SetTypeCheck(true))
+	require.NoError(t, err)
+	var names []string
+	for _, result := range results {
+		require.True(t, result.Implements)
+		require.Equal(t, result.TypeName == "MemoryService", result.IsPointer)
+		names = append(names, result.TypeName)
+	}
+	require.Equal(t, []string{"CachedService", "MemoryService"}, names)
+
+	results, err = Implementations(pkgBundle, "Closer", NewImplementsOptions().SetTypeCheck(true).SetIncludeModule(true))
+	require.NoError(t, err)
+	names = nil
+	for _, result := range results {
+		names = append(names, result.TypeName)
+	}
+	require.Equal(t, []string{"CachedService", "MemoryService", "store.Cache"}, names)
+
+	results, err = Implementations(pkgBundle, "UserService", NewImplementsOptions().SetTypeCheck(true))
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	for _, result := range results {
+		if result.TypeName == "ReadOnlyService" {
+			require.Equal(t, "field bool", result.Mismatches[0].Actual)
+		}
+		if result.TypeName == "LegacyService" {
+			require.Equal(t, "Get", result.Mismatches[1].Name)
+			require.Equal(t, "func(int) (*User, error)", result.Mismatches[1].Actual)
+		}
+	}
+}
+
+// TestImplementedInterfaces tests finding the interfaces implemented by a type
+// Verifies interfaces of imported module packages are reported as near misses when type-checked
+//
+// TestImplementedInterfaces 测试查找类型实现的接口
+// 验证进行类型检查时，被导入的本模块包中的接口会作为差一点实现的接口报告
+func TestImplementedInterfaces(t *testing.T) {
+	pkgBundle := newImplementsPackageBundle(t)
+
+	results, err := ImplementedInterfaces(pkgBundle, "MemoryService", NewImplementsOptions())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "UserService", results[0].InterfaceName)
+	require.True(t, results[0].Implements)
+
+	results, err = ImplementedInterfaces(pkgBundle, "MemoryService", NewImplementsOptions().SetTypeCheck(true).SetIncludeModule(true))
+	require.NoError(t, err)
+	var names []string
+	for _, result := range results {
+		names = append(names, result.InterfaceName)
+	}
+	require.Equal(t, []string{"Closer", "UserService", "store.Flusher"}, names)
+	require.True(t, results[0].Implements)
+	require.False(t, results[2].Implements)
+	require.Len(t, results[2].Mismatches, 1)
+	require.Equal(t, "Flush", results[2].Mismatches[0].Name)
+	require.Equal(t, "func() error", results[2].Mismatches[0].Expected)
+}
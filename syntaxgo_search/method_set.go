@@ -0,0 +1,295 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// MethodSetEntry is one method in a method set, declared on the type itself or promoted through embedded fields.
+// MethodSetEntry 是方法集中的一个方法，声明在类型自身上或通过嵌入字段提升而来。
+type MethodSetEntry struct {
+	Name            string        // Method name / 方法名
+	FuncDecl        *ast.FuncDecl // Method declaration, nil for interface methods / 方法声明，接口方法时为 nil
+	InterfaceMethod *ast.Field    // Interface method, nil for declared methods / 接口方法，声明的方法时为 nil
+	RecvTypeName    string        // Type declaring the method / 声明该方法的类型
+	IsPointerRecv   bool          // Whether the method has a pointer receiver / 方法是否为指针接收者
+	Path            []string      // Embedded field names the method is promoted through, empty when declared on the type / 方法提升经过的嵌入字段名，声明在类型自身上时为空
+}
+
+// GetFuncType returns the signature of the method, for both declared and interface methods.
+// GetFuncType 返回方法的签名，适用于声明的方法和接口方法。
+func (entry *MethodSetEntry) GetFuncType() *ast.FuncType {
+	if entry.FuncDecl != nil {
+		return entry.FuncDecl.Type
+	}
+	funcType, _ := entry.InterfaceMethod.Type.(*ast.FuncType)
+	return funcType
+}
+
+// MethodSet is the method set of a type T or *T.
+// MethodSet 是类型 T 或 *T 的方法集。
+type MethodSet struct {
+	TypeName   string            // Type name / 类型名
+	IsPointer  bool              // Whether this is the method set of *T / 是否为 *T 的方法集
+	Methods    []*MethodSetEntry // Methods sorted by name / 按名称排序的方法
+	Ambiguous  []string          // Selectors found more than once at the shallowest depth, left out of Methods / 在最浅深度出现多次的选择器，不计入 Methods
+	Unresolved []string          // Embedded types declared outside the package, their methods are unknown / 声明在包外的嵌入类型，其方法未知
+}
+
+// GetMethod returns the method with the name, false when it is not in the method set.
+// GetMethod 返回具有该名称的方法，不在方法集中时返回 false。
+func (methodSet *MethodSet) GetMethod(name string) (*MethodSetEntry, bool) {
+	for _, entry := range methodSet.Methods {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// GetMethodNames returns the names of the methods in the method set.
+// GetMethodNames 返回方法集中的方法名称。
+func (methodSet *MethodSet) GetMethodNames() []string {
+	var names = make([]string, 0, len(methodSet.Methods))
+	for _, entry := range methodSet.Methods {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// MethodSetResolver computes method sets from the type and method declarations of a set of files, usually one package.
+// MethodSetResolver 根据一组文件（通常是一个包）中的类型和方法声明计算方法集。
+type MethodSetResolver struct {
+	typeSpecs map[string]*ast.TypeSpec   // Type declarations by name / 按名称索引的类型声明
+	methods   map[string][]*ast.FuncDecl // Method declarations by receiver type name / 按接收者类型名索引的方法声明
+}
+
+// NewMethodSetResolver creates a resolver over the files, which should belong to the same package.
+// NewMethodSetResolver 基于这些文件创建解析器，这些文件应属于同一个包。
+func NewMethodSetResolver(astFiles []*ast.File) *MethodSetResolver {
+	resolver := &MethodSetResolver{
+		typeSpecs: map[string]*ast.TypeSpec{},
+		methods:   map[string][]*ast.FuncDecl{},
+	}
+	for _, astFile := range astFiles {
+		for _, typeSpec := range FindTypes(astFile) {
+			resolver.typeSpecs[typeSpec.Name.Name] = typeSpec
+		}
+		for _, funcDecl := range FindFunctions(astFile) {
+			if receiverInfo, ok := GetFunctionReceiverInfo(funcDecl); ok {
+				resolver.methods[receiverInfo.TypeName] = append(resolver.methods[receiverInfo.TypeName], funcDecl)
+			}
+		}
+	}
+	return resolver
+}
+
+// NewMethodSetResolverInPackage creates a resolver over every file of the package.
+// NewMethodSetResolverInPackage 基于包内的所有文件创建解析器。
+func NewMethodSetResolverInPackage(pkgBundle *syntaxgo_ast.PackageBundle) *MethodSetResolver {
+	return NewMethodSetResolver(pkgBundle.GetAstFiles())
+}
+
+// methodSetCandidate is a type reached at some depth of the embedding walk.
+// methodSetCandidate 是嵌入遍历在某一深度到达的类型。
+type methodSetCandidate struct {
+	typeName string   // Type name / 类型名
+	path     []string // Embedded field names leading to the type / 到达该类型经过的嵌入字段名
+	indirect bool     // Whether the value is addressable, so pointer methods are callable / 值是否可寻址，即指针方法是否可调用
+}
+
+// MethodSet computes the method set of the type, or of the pointer type when pointer is true.
+// Methods of embedded fields are promoted by depth: a shallower method or field hides deeper ones,
+// and a name found more than once at the same depth is ambiguous, like the Go selector rules.
+// Pointer receiver methods are included when pointer is true or the path goes through an embedded pointer.
+//
+// MethodSet 计算类型的方法集，pointer 为 true 时计算指针类型的方法集。
+// 嵌入字段的方法按深度提升：较浅的方法或字段会隐藏较深的同名者，
+// 同一深度出现多次的名称是有歧义的，与 Go 的选择器规则一致。
+// 当 pointer 为 true 或路径经过嵌入的指针时，包含指针接收者的方法。
+func (resolver *MethodSetResolver) MethodSet(typeName string, pointer bool) (*MethodSet, error) {
+	rootSpec, ok := resolver.typeSpecs[typeName]
+	if !ok {
+		return nil, erero.Errorf("type %s is not declared in the files", typeName)
+	}
+	_, isInterface := rootSpec.Type.(*ast.InterfaceType)
+	methodSet := &MethodSet{TypeName: typeName, IsPointer: pointer}
+
+	var hidden = map[string]bool{} // Names resolved at a shallower depth. // 在较浅深度已解析的名称。
+	var seen = map[string]bool{}
+	var current = []*methodSetCandidate{{typeName: typeName, indirect: pointer}}
+	for len(current) > 0 {
+		var next []*methodSetCandidate
+		var levelMethods = map[string][]*MethodSetEntry{}
+		var levelFields = map[string]int{}
+		var levelNames []string
+		addName := func(name string) {
+			if !slices.Contains(levelNames, name) {
+				levelNames = append(levelNames, name)
+			}
+		}
+		for _, candidate := range current {
+			name := resolver.resolveAlias(candidate.typeName)
+			if seen[name] {
+				continue
+			}
+			typeSpec, ok := resolver.typeSpecs[name]
+			if !ok {
+				methodSet.Unresolved = append(methodSet.Unresolved, name)
+				continue
+			}
+			for _, funcDecl := range resolver.methods[name] {
+				receiverInfo, _ := GetFunctionReceiverInfo(funcDecl)
+				levelMethods[funcDecl.Name.Name] = append(levelMethods[funcDecl.Name.Name], &MethodSetEntry{
+					Name:          funcDecl.Name.Name,
+					FuncDecl:      funcDecl,
+					RecvTypeName:  name,
+					IsPointerRecv: receiverInfo.IsPointer,
+					Path:          candidate.path,
+				})
+				addName(funcDecl.Name.Name)
+			}
+			switch x := typeSpec.Type.(type) {
+			case *ast.StructType:
+				for _, field := range x.Fields.List {
+					for _, fieldName := range field.Names {
+						levelFields[fieldName.Name]++
+						addName(fieldName.Name)
+					}
+					if len(field.Names) > 0 {
+						continue
+					}
+					embeddedName := getEmbeddedName(field.Type)
+					if embeddedName == nil {
+						continue
+					}
+					levelFields[embeddedName.Name]++
+					addName(embeddedName.Name)
+					if qualifiedName, ok := getQualifiedName(field.Type); ok {
+						methodSet.Unresolved = append(methodSet.Unresolved, qualifiedName)
+						continue
+					}
+					_, isStar := field.Type.(*ast.StarExpr)
+					next = append(next, &methodSetCandidate{
+						typeName: embeddedName.Name,
+						path:     append(slices.Clone(candidate.path), embeddedName.Name),
+						indirect: candidate.indirect || isStar,
+					})
+				}
+			case *ast.InterfaceType:
+				for _, field := range x.Methods.List {
+					if _, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+						levelMethods[field.Names[0].Name] = append(levelMethods[field.Names[0].Name], &MethodSetEntry{
+							Name:            field.Names[0].Name,
+							InterfaceMethod: field,
+							RecvTypeName:    name,
+							Path:            candidate.path,
+						})
+						addName(field.Names[0].Name)
+						continue
+					}
+					if embeddedName := getEmbeddedName(field.Type); embeddedName != nil {
+						if qualifiedName, ok := getQualifiedName(field.Type); ok {
+							methodSet.Unresolved = append(methodSet.Unresolved, qualifiedName)
+							continue
+						}
+						next = append(next, &methodSetCandidate{
+							typeName: embeddedName.Name,
+							path:     append(slices.Clone(candidate.path), embeddedName.Name),
+							indirect: true,
+						})
+					}
+				}
+			}
+		}
+		for _, candidate := range current {
+			seen[resolver.resolveAlias(candidate.typeName)] = true
+		}
+
+		for _, name := range levelNames {
+			if hidden[name] {
+				continue
+			}
+			hidden[name] = true
+			entries := levelMethods[name]
+			if len(entries)+levelFields[name] > 1 && !isInterface {
+				methodSet.Ambiguous = append(methodSet.Ambiguous, name)
+				continue
+			}
+			if len(entries) == 0 {
+				continue // A field hides deeper methods with the same name. // 字段会隐藏更深处的同名方法。
+			}
+			entry := entries[0]
+			if entry.IsPointerRecv && !isIndirect(current, entry) {
+				continue
+			}
+			methodSet.Methods = append(methodSet.Methods, entry)
+		}
+		current = next
+	}
+	slices.SortFunc(methodSet.Methods, func(a, b *MethodSetEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	slices.Sort(methodSet.Ambiguous)
+	return methodSet, nil
+}
+
+// resolveAlias follows type aliases declared in the files, such as `type A = B`.
+// resolveAlias 跟随文件中声明的类型别名，比如 `type A = B`。
+func (resolver *MethodSetResolver) resolveAlias(typeName string) string {
+	for range len(resolver.typeSpecs) {
+		typeSpec, ok := resolver.typeSpecs[typeName]
+		if !ok || !typeSpec.Assign.IsValid() {
+			break
+		}
+		target := getEmbeddedName(typeSpec.Type)
+		if target == nil {
+			break
+		}
+		typeName = target.Name
+	}
+	return typeName
+}
+
+// isIndirect reports whether the candidate the entry was found through is addressable.
+// isIndirect 判断找到该方法的候选类型是否可寻址。
+func isIndirect(candidates []*methodSetCandidate, entry *MethodSetEntry) bool {
+	for _, candidate := range candidates {
+		if slices.Equal(candidate.path, entry.Path) {
+			return candidate.indirect
+		}
+	}
+	return false
+}
+
+// unwrapEmbeddedType removes the pointer and the type arguments of an embedded type, such as `*pkg.Box[T]` to `pkg.Box`.
+// unwrapEmbeddedType 去掉嵌入类型的指针和类型实参，比如将 `*pkg.Box[T]` 变为 `pkg.Box`。
+func unwrapEmbeddedType(typeExpr ast.Expr) ast.Expr {
+	for {
+		switch x := typeExpr.(type) {
+		case *ast.StarExpr:
+			typeExpr = x.X
+		case *ast.IndexExpr:
+			typeExpr = x.X
+		case *ast.IndexListExpr:
+			typeExpr = x.X
+		default:
+			return typeExpr
+		}
+	}
+}
+
+// getQualifiedName returns the qualified name of a type declared in another package, such as "gorm.Model".
+// getQualifiedName 返回声明在其他包中的类型的限定名，比如 "gorm.Model"。
+func getQualifiedName(typeExpr ast.Expr) (string, bool) {
+	if selectorExpr, ok := unwrapEmbeddedType(typeExpr).(*ast.SelectorExpr); ok {
+		if ident, ok := selectorExpr.X.(*ast.Ident); ok {
+			return ident.Name + "." + selectorExpr.Sel.Name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,173 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const methodSetExampleCode = `package example
+
+type Base struct{}
+
+func (b Base) Name() string { return "" }
+
+func (b *Base) SetName(name string) {}
+
+type Logger struct{}
+
+func (l *Logger) Log(msg string) {}
+
+func (l Logger) Name() string { return "" }
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type ReadCloser interface {
+	Reader
+	Close() error
+}
+
+type Service struct {
+	Base
+	*Logger
+	ReadCloser
+	gorm.Model
+}
+
+func (s *Service) Run() {}
+
+type Wrapper struct {
+	Service
+	Log string
+}
+
+type Box[T any] struct {
+	value T
+}
+
+func (b *Box[T]) Get() T { return b.value }
+
+type IntBox = Box[int]
+
+type Holder struct {
+	IntBox
+}
+`
+
+// TestMethodSetResolver_MethodSet tests the value and pointer method sets with promoted methods
+// Verifies pointer methods are promoted through embedded pointers, and the same name at one depth is ambiguous
+//
+// TestMethodSetResolver_MethodSet 测试包含提升方法的值方法集和指针方法集
+// 验证指针方法会通过嵌入的指针提升，同一深度的同名方法有歧义
+func TestMethodSetResolver_MethodSet(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(methodSetExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+	resolver := NewMethodSetResolver([]*ast.File{astFile})
+
+	methodSet, err := resolver.MethodSet("Service", false)
+	require.NoError(t, err)
+	t.Log(methodSet.GetMethodNames())
+	require.Equal(t, []string{"Close", "Log", "Read"}, methodSet.GetMethodNames())
+	require.Equal(t, []string{"Name"}, methodSet.Ambiguous)
+	require.Equal(t, []string{"gorm.Model"}, methodSet.Unresolved)
+
+	entry, ok := methodSet.GetMethod("Read")
+	require.True(t, ok)
+	require.Equal(t, []string{"ReadCloser", "Reader"}, entry.Path)
+	require.Equal(t, "Reader", entry.RecvTypeName)
+	require.NotNil(t, entry.InterfaceMethod)
+	require.Len(t, entry.GetFuncType().Params.List, 1)
+
+	entry, ok = methodSet.GetMethod("Log")
+	require.True(t, ok)
+	require.True(t, entry.IsPointerRecv)
+	require.Equal(t, []string{"Logger"}, entry.Path)
+
+	methodSet, err = resolver.MethodSet("Service", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Close", "Log", "Read", "Run", "SetName"}, methodSet.GetMethodNames())
+
+	entry, ok = methodSet.GetMethod("SetName")
+	require.True(t, ok)
+	require.Equal(t, []string{"Base"}, entry.Path)
+	require.Equal(t, "SetName", entry.FuncDecl.Name.Name)
+}
+
+// TestMethodSetResolver_Hidden tests a field hiding a deeper promoted method
+// Verifies the field "Log" of Wrapper hides the method Log of the embedded Logger
+//
+// TestMethodSetResolver_Hidden 测试字段隐藏更深处的提升方法
+// 验证 Wrapper 的字段 "Log" 隐藏了嵌入的 Logger 的 Log 方法
+func TestMethodSetResolver_Hidden(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(methodSetExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+	resolver := NewMethodSetResolver([]*ast.File{astFile})
+
+	methodSet, err := resolver.MethodSet("Wrapper", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Close", "Read", "Run", "SetName"}, methodSet.GetMethodNames())
+	require.Equal(t, []string{"Name"}, methodSet.Ambiguous)
+
+	entry, ok := methodSet.GetMethod("Read")
+	require.True(t, ok)
+	require.Equal(t, []string{"Service", "ReadCloser", "Reader"}, entry.Path)
+}
+
+// TestMethodSetResolver_Interface tests the method set of an interface with embedded interfaces
+// Verifies generic receivers and type aliases are followed, and unknown types are rejected
+//
+// TestMethodSetResolver_Interface 测试带嵌入接口的接口的方法集
+// 验证会跟随泛型接收者和类型别名，未知类型会被拒绝
+func TestMethodSetResolver_Interface(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(methodSetExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+	resolver := NewMethodSetResolver([]*ast.File{astFile})
+
+	methodSet, err := resolver.MethodSet("ReadCloser", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Close", "Read"}, methodSet.GetMethodNames())
+
+	methodSet, err = resolver.MethodSet("Holder", false)
+	require.NoError(t, err)
+	require.Empty(t, methodSet.Methods)
+
+	methodSet, err = resolver.MethodSet("Holder", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Get"}, methodSet.GetMethodNames())
+	require.Equal(t, "Box", methodSet.Methods[0].RecvTypeName)
+
+	_, err = resolver.MethodSet("Unknown", false)
+	require.Error(t, err)
+}
+
+// TestNewMethodSetResolverInPackage tests resolving methods declared across the files of a package
+// Verifies methods promoted from an embedded type declared in another file
+//
+// TestNewMethodSetResolverInPackage 测试解析声明在包内多个文件中的方法
+// 验证从声明在另一个文件中的嵌入类型提升的方法
+func TestNewMethodSetResolverInPackage(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/shop", map[string]string{
+		"base.go":  "package shop\n\ntype Base struct{}\n\nfunc (b Base) ID() int64 { return 0 }\n\nfunc (b *Base) SetID(id int64) {}\n",
+		"order.go": "package shop\n\ntype Order struct {\n\tBase\n}\n\nfunc (o *Order) Pay() error { return nil }\n",
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+	resolver := NewMethodSetResolverInPackage(pkgBundle)
+
+	methodSet, err := resolver.MethodSet("Order", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ID"}, methodSet.GetMethodNames())
+
+	methodSet, err = resolver.MethodSet("Order", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ID", "Pay", "SetID"}, methodSet.GetMethodNames())
+
+	entry, ok := methodSet.GetMethod("SetID")
+	require.True(t, ok)
+	require.Equal(t, []string{"Base"}, entry.Path)
+}
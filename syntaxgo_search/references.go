@@ -0,0 +1,345 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// ReferencesOptions controls how references are found.
+// ReferencesOptions 控制如何查找引用。
+type ReferencesOptions struct {
+	typeCheck bool // Resolve the uses with go/types instead of the syntax / 使用 go/types 而非语法解析引用
+}
+
+// NewReferencesOptions creates options resolving the uses syntactically.
+// NewReferencesOptions 创建按语法解析引用的选项。
+func NewReferencesOptions() *ReferencesOptions {
+	return &ReferencesOptions{}
+}
+
+// SetTypeCheck sets whether to type-check the package and resolve the uses with types.Info.Uses.
+// SetTypeCheck 设置是否对包进行类型检查，并通过 types.Info.Uses 解析引用。
+func (opts *ReferencesOptions) SetTypeCheck(typeCheck bool) *ReferencesOptions {
+	opts.typeCheck = typeCheck
+	return opts
+}
+
+// Reference is a use of a declared name.
+// Reference 是对已声明名称的一次使用。
+type Reference struct {
+	Ident    *ast.Ident     // Identifier of the use / 使用处的标识符
+	File     *ast.File      // File containing the use / 包含该使用的文件
+	Position token.Position // Position of the use / 使用的位置
+}
+
+// FindReferences finds the uses of a declaration in the package, in file and source order, the declaration excluded.
+// The declaration is a *ast.TypeSpec, a *ast.FuncDecl of a function or a method, a *ast.Field of a struct field
+// or an interface method, a *ast.ValueSpec of a constant or a variable, or the *ast.Ident declaring the name.
+// Fields and value specs declaring several names need the *ast.Ident.
+//
+// Names are resolved by scope, so locals shadowing the declaration are not reported.
+// Syntactically, fields and methods are found when the type of the selector expression is known from its declaration,
+// like in NewCallGraph, and elided types of nested composite literals are not followed.
+// With type checking the uses come from types.Info.Uses and are exact.
+//
+// FindReferences 查找包中对某个声明的使用，按文件和源码顺序返回，不包括声明本身。
+// 声明可以是 *ast.TypeSpec，函数或方法的 *ast.FuncDecl，结构体字段或接口方法的 *ast.Field，
+// 常量或变量的 *ast.ValueSpec，或者声明该名称的 *ast.Ident。
+// 声明了多个名称的字段和变量声明需要传入 *ast.Ident。
+//
+// 名称按作用域解析，因此遮蔽该声明的局部变量不会被报告。
+// 按语法查找时，当选择器表达式的类型可以从其声明得知时才能找到字段和方法，与 NewCallGraph 相同，
+// 且不会跟随嵌套复合字面量中省略的类型。
+// 使用类型检查时，引用来自 types.Info.Uses，结果是精确的。
+func FindReferences(pkgBundle *syntaxgo_ast.PackageBundle, decl ast.Node, opts *ReferencesOptions) ([]*Reference, error) {
+	declIdent, err := getDeclIdent(decl)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if opts.typeCheck {
+		return findReferencesWithTypes(pkgBundle, declIdent)
+	}
+	finder, err := newReferenceFinder(pkgBundle.GetAstFiles(), declIdent)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var results []*Reference
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		for _, ident := range finder.findInFile(astFile) {
+			results = append(results, &Reference{Ident: ident, File: astFile, Position: pkgBundle.GetFileSet().Position(ident.Pos())})
+		}
+	}
+	return results, nil
+}
+
+// getDeclIdent returns the identifier declaring the name of the declaration.
+// getDeclIdent 返回声明中声明名称的标识符。
+func getDeclIdent(decl ast.Node) (*ast.Ident, error) {
+	switch x := decl.(type) {
+	case *ast.Ident:
+		return x, nil
+	case *ast.TypeSpec:
+		return x.Name, nil
+	case *ast.FuncDecl:
+		return x.Name, nil
+	case *ast.Field:
+		if len(x.Names) != 1 {
+			return nil, erero.Errorf("field declares %d names, pass the identifier", len(x.Names))
+		}
+		return x.Names[0], nil
+	case *ast.ValueSpec:
+		if len(x.Names) != 1 {
+			return nil, erero.Errorf("value spec declares %d names, pass the identifier", len(x.Names))
+		}
+		return x.Names[0], nil
+	}
+	return nil, erero.Errorf("node %T is not a declaration", decl)
+}
+
+// findReferencesWithTypes type-checks the package when needed and collects the identifiers using the declared object.
+// findReferencesWithTypes 在需要时对包进行类型检查，并收集使用该声明对象的标识符。
+func findReferencesWithTypes(pkgBundle *syntaxgo_ast.PackageBundle, declIdent *ast.Ident) ([]*Reference, error) {
+	typesBundle := pkgBundle.GetTypesBundle()
+	if typesBundle == nil {
+		var err error
+		if typesBundle, err = pkgBundle.TypeCheck(); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	info := typesBundle.GetInfo()
+	target := info.Defs[declIdent]
+	if target == nil {
+		return nil, erero.Errorf("%s does not declare an object in the package", declIdent.Name)
+	}
+	var results []*Reference
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		ast.Inspect(astFile, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok {
+				if object := info.Uses[ident]; object != nil && getOriginObject(object) == target {
+					results = append(results, &Reference{Ident: ident, File: astFile, Position: pkgBundle.GetFileSet().Position(ident.Pos())})
+				}
+			}
+			return true
+		})
+	}
+	return results, nil
+}
+
+// getOriginObject returns the generic field or method that an instantiated one comes from.
+// getOriginObject 返回实例化后的字段或方法所来自的泛型字段或方法。
+func getOriginObject(object types.Object) types.Object {
+	switch x := object.(type) {
+	case *types.Func:
+		return x.Origin()
+	case *types.Var:
+		return x.Origin()
+	}
+	return object
+}
+
+// referenceFinder resolves identifiers to a declaration without type checking.
+// Plain names are resolved with the scopes of go/parser, names in other files with the unresolved identifiers of the file,
+// and fields and methods with the inferred types of the selector expressions.
+//
+// referenceFinder 在不进行类型检查的情况下将标识符解析到声明。
+// 普通名称通过 go/parser 的作用域解析，其他文件中的名称通过文件中未解析的标识符判断，
+// 字段和方法则通过推断选择器表达式的类型解析。
+type referenceFinder struct {
+	*exprTypeResolver
+	declIdent    *ast.Ident
+	object       *ast.Object   // Parser object of the name, nil for fields and methods / 名称的解析器对象，字段和方法时为 nil
+	packageLevel bool          // Whether the name is declared in the package scope / 名称是否声明在包作用域中
+	methodDecl   *ast.FuncDecl // Method declaration / 方法声明
+	member       *ast.Field    // Struct field or interface method / 结构体字段或接口方法
+	isField      bool          // Whether the member is a struct field / 成员是否为结构体字段
+}
+
+// newReferenceFinder locates the declaration of the identifier in the files.
+// newReferenceFinder 在这些文件中定位该标识符的声明。
+func newReferenceFinder(astFiles []*ast.File, declIdent *ast.Ident) (*referenceFinder, error) {
+	finder := &referenceFinder{exprTypeResolver: newExprTypeResolver(astFiles), declIdent: declIdent}
+	for _, astFile := range astFiles {
+		for _, funcDecl := range FindFunctions(astFile) {
+			if funcDecl.Name == declIdent && funcDecl.Recv != nil {
+				finder.methodDecl = funcDecl
+				return finder, nil
+			}
+		}
+		for _, typeSpec := range FindTypes(astFile) {
+			var fields []*ast.Field
+			structType, isStruct := typeSpec.Type.(*ast.StructType)
+			if isStruct {
+				fields = structType.Fields.List
+			} else if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				fields = interfaceType.Methods.List
+			}
+			for _, field := range fields {
+				for _, name := range field.Names {
+					if name == declIdent {
+						finder.member = field
+						finder.isField = isStruct
+						return finder, nil
+					}
+				}
+			}
+		}
+		if declIdent.Obj != nil && astFile.Scope.Lookup(declIdent.Name) == declIdent.Obj {
+			finder.object = declIdent.Obj
+			finder.packageLevel = true
+			return finder, nil
+		}
+	}
+	if declIdent.Obj == nil {
+		return nil, erero.Errorf("%s is not declared in the files", declIdent.Name)
+	}
+	if field, ok := declIdent.Obj.Decl.(*ast.Field); ok && !finder.isLocalField(astFiles, field) {
+		return nil, erero.Errorf("%s is a field of an anonymous type", declIdent.Name)
+	}
+	finder.object = declIdent.Obj
+	return finder, nil
+}
+
+// isLocalField reports whether the field is a parameter, a result or a receiver, where the parser resolves its uses.
+// isLocalField 判断字段是否为参数、结果或接收者，这些字段的使用会被解析器解析。
+func (finder *referenceFinder) isLocalField(astFiles []*ast.File, field *ast.Field) bool {
+	var found bool
+	for _, astFile := range astFiles {
+		ast.Inspect(astFile, func(node ast.Node) bool {
+			var fieldLists []*ast.FieldList
+			switch x := node.(type) {
+			case *ast.FuncDecl:
+				fieldLists = append(fieldLists, x.Recv)
+			case *ast.FuncType:
+				fieldLists = append(fieldLists, x.TypeParams, x.Params, x.Results)
+			}
+			for _, fieldList := range fieldLists {
+				if fieldList != nil && !found {
+					for _, item := range fieldList.List {
+						found = found || item == field
+					}
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// findInFile returns the identifiers of the file referring to the declaration, in source order.
+// findInFile 按源码顺序返回文件中引用该声明的标识符。
+func (finder *referenceFinder) findInFile(astFile *ast.File) (results []*ast.Ident) {
+	var unresolved = map[*ast.Ident]bool{}
+	for _, ident := range astFile.Unresolved {
+		unresolved[ident] = true
+	}
+	var fieldKeys = map[*ast.Ident]bool{} // Keys of struct literals, which name fields. // 结构体字面量的键，表示字段名。
+	name := finder.declIdent.Name
+	ast.Inspect(astFile, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			if x.Sel.Name == name && finder.matchSelector(x) {
+				results = append(results, x.Sel)
+			}
+		case *ast.CompositeLit:
+			typeName, isStruct := finder.getLiteralStructName(x)
+			for _, elt := range x.Elts {
+				keyValue, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := keyValue.Key.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if isStruct {
+					fieldKeys[key] = true
+					if finder.isField && key.Name == name && typeName != "" {
+						if field, ok := finder.lookupField(typeName, name); ok && field == finder.member {
+							results = append(results, key)
+						}
+					}
+				} else if finder.packageLevel && key.Obj == nil && key.Name == name {
+					results = append(results, key) // Keys are not recorded as unresolved. // 键不会被记录为未解析的标识符。
+				}
+			}
+		case *ast.Ident:
+			if x == finder.declIdent || fieldKeys[x] || finder.object == nil {
+				return true
+			}
+			if x.Obj == finder.object || (finder.packageLevel && x.Obj == nil && unresolved[x] && x.Name == name) {
+				results = append(results, x)
+			}
+		}
+		return true
+	})
+	return results
+}
+
+// matchSelector reports whether the selector expression selects the field or the method.
+// matchSelector 判断选择器表达式是否选择了该字段或方法。
+func (finder *referenceFinder) matchSelector(selectorExpr *ast.SelectorExpr) bool {
+	if finder.methodDecl == nil && finder.member == nil {
+		return false
+	}
+	typeName, ok := finder.getExprTypeName(selectorExpr.X)
+	if !ok {
+		if typeName, ok = finder.getMethodExprTypeName(selectorExpr.X); !ok {
+			return false
+		}
+	}
+	if finder.isField {
+		field, ok := finder.lookupField(typeName, selectorExpr.Sel.Name)
+		return ok && field == finder.member
+	}
+	entry, ok := finder.getMethod(typeName, selectorExpr.Sel.Name)
+	if !ok {
+		return false
+	}
+	if finder.methodDecl != nil {
+		return entry.FuncDecl == finder.methodDecl
+	}
+	return entry.InterfaceMethod == finder.member
+}
+
+// getMethodExprTypeName returns the type of a method expression, such as "T" of `T.M` or `(*T).M`.
+// getMethodExprTypeName 返回方法表达式的类型，比如 `T.M` 或 `(*T).M` 中的 "T"。
+func (finder *referenceFinder) getMethodExprTypeName(expr ast.Expr) (string, bool) {
+	expr = ast.Unparen(expr)
+	if starExpr, ok := expr.(*ast.StarExpr); ok {
+		expr = starExpr.X
+	}
+	ident, ok := unwrapCallFun(expr).(*ast.Ident)
+	if !ok || (ident.Obj != nil && ident.Obj.Kind != ast.Typ) {
+		return "", false
+	}
+	return finder.getTypeExprName(ident)
+}
+
+// getLiteralStructName returns the struct type name of the composite literal, and whether its keys name fields.
+// Keys name fields unless the literal is a map, slice or array, the name is empty when the struct type is unknown.
+//
+// getLiteralStructName 返回复合字面量的结构体类型名，以及其键是否表示字段名。
+// 除非字面量是 map、切片或数组，否则键都表示字段名，结构体类型未知时名称为空。
+func (finder *referenceFinder) getLiteralStructName(compositeLit *ast.CompositeLit) (string, bool) {
+	switch compositeLit.Type.(type) {
+	case *ast.MapType, *ast.ArrayType:
+		return "", false
+	}
+	typeName, ok := finder.getTypeExprName(compositeLit.Type)
+	if !ok {
+		return "", true
+	}
+	typeSpec, ok := finder.resolver.typeSpecs[finder.resolver.resolveAlias(typeName)]
+	if !ok {
+		return "", true
+	}
+	switch typeSpec.Type.(type) {
+	case *ast.MapType, *ast.ArrayType:
+		return "", false
+	}
+	return typeName, true
+}
@@ -0,0 +1,179 @@
+package syntaxgo_search
+
+import (
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const referencesUserCode = `package demo
+
+type Base struct {
+	ID int
+}
+
+type User struct {
+	Base
+	Name string
+}
+
+func (u *User) Greet() string {
+	return "hi " + u.Name
+}
+
+const Limit = 10
+
+var defaultUser = NewUser("x")
+
+func NewUser(name string) *User {
+	return &User{Name: name}
+}
+`
+
+const referencesRunCode = `package demo
+
+type Greeter interface {
+	Greet() string
+}
+
+func run(g Greeter) {
+	user := NewUser("a")
+	_ = user.Greet()
+	_ = user.ID
+	_ = g.Greet()
+	greet := (*User).Greet
+	_ = greet
+	for i := 0; i < Limit; i++ {
+	}
+	{
+		Limit := 3
+		_ = Limit
+	}
+	_ = defaultUser.Name
+	_ = map[int]string{Limit: "x"}
+	_ = User{Name: "b"}
+	us := []User{}
+	_ = us[0].Name
+	m := map[string]User{}
+	_ = m["a"].Name
+	for _, v := range us {
+		_ = v.Name
+	}
+}
+`
+
+// newReferencesPackage writes a package of two files into a temp module and loads it.
+// newReferencesPackage 将包含两个文件的包写入临时模块并加载。
+func newReferencesPackage(t *testing.T) *syntaxgo_ast.PackageBundle {
+	root := tests.NewTempModule(t, "example.com/demo", map[string]string{
+		"run.go":  referencesRunCode,
+		"user.go": referencesUserCode,
+	})
+	return rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+}
+
+// formatReferences formats the references as "file:line:column".
+// formatReferences 将引用格式化为 "file:line:column"。
+func formatReferences(references []*Reference) []string {
+	var results []string
+	for _, reference := range references {
+		results = append(results, fmt.Sprintf("%s:%d:%d", filepath.Base(reference.Position.Filename), reference.Position.Line, reference.Position.Column))
+	}
+	return results
+}
+
+// TestFindReferences tests finding the uses of each declaration kind across files
+// Verifies the syntactic results match the type-checked ones, and shadowed locals are skipped
+//
+// TestFindReferences 测试跨文件查找各类声明的使用
+// 验证按语法查找的结果与类型检查的结果一致，并跳过遮蔽的局部变量
+func TestFindReferences(t *testing.T) {
+	pkgBundle := newReferencesPackage(t)
+	astFiles := pkgBundle.GetAstFiles()
+	runFile, userFile := astFiles[0], astFiles[1]
+
+	typeSpecs := FindTypes(userFile)
+	baseFields := typeSpecs[0].Type.(*ast.StructType).Fields.List
+	userFields := typeSpecs[1].Type.(*ast.StructType).Fields.List
+	greeter := FindTypes(runFile)[0]
+	_, _, values := FindClassesAndFunctions(userFile)
+	greet, ok := FindFunctionByReceiverAndName(userFile, "User", "Greet")
+	require.True(t, ok)
+
+	testCases := []struct {
+		name     string
+		decl     ast.Node
+		expected []string
+	}{
+		{"type", typeSpecs[1], []string{"run.go:12:13", "run.go:22:6", "run.go:23:10", "run.go:25:18", "user.go:12:10", "user.go:20:28", "user.go:21:10"}},
+		{"function", FindFunctionByName(userFile, "NewUser"), []string{"run.go:8:10", "user.go:18:19"}},
+		{"method", greet, []string{"run.go:9:11", "run.go:12:19"}},
+		{"interface method", greeter.Type.(*ast.InterfaceType).Methods.List[0], []string{"run.go:11:8"}},
+		{"field", userFields[1], []string{"run.go:20:18", "run.go:22:11", "run.go:24:12", "run.go:26:13", "run.go:28:9", "user.go:13:19", "user.go:21:15"}},
+		{"promoted field", baseFields[0], []string{"run.go:10:11"}},
+		{"const", values[0], []string{"run.go:14:18", "run.go:21:21"}},
+		{"var", values[1], []string{"run.go:20:6"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			references, err := FindReferences(pkgBundle, testCase.decl, NewReferencesOptions())
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, formatReferences(references))
+
+			references, err = FindReferences(pkgBundle, testCase.decl, NewReferencesOptions().SetTypeCheck(true))
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, formatReferences(references))
+		})
+	}
+}
+
+// TestFindReferences_Local tests finding the uses of a local variable and a parameter
+// Verifies the uses stay inside the scope of the declaration
+//
+// TestFindReferences_Local 测试查找局部变量和参数的使用
+// 验证找到的使用都在声明的作用域内
+func TestFindReferences_Local(t *testing.T) {
+	pkgBundle := newReferencesPackage(t)
+	runFunc := FindFunctionByName(pkgBundle.GetAstFiles()[0], "run")
+
+	param := runFunc.Type.Params.List[0]
+	references := rese.V1(FindReferences(pkgBundle, param, NewReferencesOptions()))
+	require.Equal(t, []string{"run.go:11:6"}, formatReferences(references))
+
+	user := runFunc.Body.List[0].(*ast.AssignStmt).Lhs[0]
+	references = rese.V1(FindReferences(pkgBundle, user, NewReferencesOptions().SetTypeCheck(true)))
+	require.Equal(t, []string{"run.go:9:6", "run.go:10:6"}, formatReferences(references))
+
+	_, err := FindReferences(pkgBundle, runFunc.Body, NewReferencesOptions())
+	require.Error(t, err)
+}
+
+// TestFindReferencesInPackage tests finding the uses of a function in a package loaded with its test files
+// Verifies uses in test files are found only when they are loaded
+//
+// TestFindReferencesInPackage 测试在加载了测试文件的包中查找函数的使用
+// 验证只有加载测试文件时才能找到其中的使用
+func TestFindReferencesInPackage(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/demo", map[string]string{
+		"run.go":      referencesRunCode,
+		"user.go":     referencesUserCode,
+		"run_test.go": "package demo\n\nimport \"testing\"\n\nfunc TestNewUser(t *testing.T) {\n\t_ = NewUser(\"t\")\n}\n",
+	})
+
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+	decl := FindFunctionByName(pkgBundle.GetAstFiles()[1], "NewUser")
+	references := rese.V1(FindReferences(pkgBundle, decl, NewReferencesOptions()))
+	require.Equal(t, []string{"run.go:8:10", "user.go:18:19"}, formatReferences(references))
+
+	pkgBundle = rese.P1(syntaxgo_ast.NewPackageBundleV2(root, syntaxgo_ast.NewPackageBundleOptions().SetIncludeTests(true)))
+	decl = FindFunctionByName(pkgBundle.GetAstFiles()[2], "NewUser")
+	references = rese.V1(FindReferences(pkgBundle, decl, NewReferencesOptions()))
+	t.Log(formatReferences(references))
+	require.Equal(t, []string{"run.go:8:10", "run_test.go:6:6", "user.go:18:19"}, formatReferences(references))
+}
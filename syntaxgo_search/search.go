@@ -2,7 +2,7 @@ package syntaxgo_search
 
 import (
 	"go/ast"
-	"reflect"
+	"go/token"
 
 	"github.com/yyle88/syntaxgo/internal/utils"
 	"github.com/yyle88/syntaxgo/syntaxgo_astnode"
@@ -445,6 +445,17 @@ func ExtractFunctionsByReceiverName(functions []*ast.FuncDecl, receiverName stri
 // GetFunctionReceiverNameAndType gets the receiver name and type of the given function declaration.
 // GetFunctionReceiverNameAndType 获取给定函数声明的接收者名称和类型
 func GetFunctionReceiverNameAndType(astFunc *ast.FuncDecl, source []byte) (receiverName string, receiverType string) {
+	return GetFunctionReceiverNameAndTypeV2(nil, astFunc, source)
+}
+
+// GetFunctionReceiverNameAndTypeV2 gets the receiver name and type of the function, resolving offsets through the FileSet.
+// For a generic receiver such as `*Box[T]` the type is the base type name "Box", use GetFunctionReceiverInfo to get the type parameters.
+// Parenthesized receivers such as `(*T)` give the type name "T" too.
+//
+// GetFunctionReceiverNameAndTypeV2 获取函数的接收者名称和类型，通过 FileSet 计算偏移量。
+// 对于 `*Box[T]` 这样的泛型接收者，类型为基础类型名 "Box"，类型参数请使用 GetFunctionReceiverInfo 获取。
+// 带括号的接收者（比如 `(*T)`）同样得到类型名 "T"。
+func GetFunctionReceiverNameAndTypeV2(fset *token.FileSet, astFunc *ast.FuncDecl, source []byte) (receiverName string, receiverType string) {
 	// Check if the function has a receiver
 	// 检查函数是否具有接收者
 	if astFunc.Recv != nil {
@@ -455,18 +466,19 @@ func GetFunctionReceiverNameAndType(astFunc *ast.FuncDecl, source []byte) (recei
 			receiverName = names[0].Name
 		}
 		nodeRecvType := astFunc.Recv.List[0].Type
-		// Check the type of the receiver
-		// 检查接收者的类型
+		// Unwrap the parentheses, the pointer and the type parameters of the receiver type
+		// 去掉接收者类型的括号、指针和类型参数
+		nodeRecvType = ast.Unparen(nodeRecvType)
+		if starExpr, ok := nodeRecvType.(*ast.StarExpr); ok {
+			nodeRecvType = ast.Unparen(starExpr.X)
+		}
 		switch node := nodeRecvType.(type) {
-		case *ast.Ident:
-			receiverType = string(syntaxgo_astnode.GetCode(source, node))
-		case *ast.StarExpr:
-			receiverType = string(syntaxgo_astnode.GetCode(source, node.X))
-		default:
-			// Log and panic if the receiver type is unknown
-			// 如果接收者类型未知，则记录并触发panic
-			zaplog.LOG.Panic("unknown", zap.Any("recv_type", reflect.TypeOf(nodeRecvType)), zap.String("node", string(syntaxgo_astnode.GetCode(source, nodeRecvType))))
+		case *ast.IndexExpr:
+			nodeRecvType = node.X
+		case *ast.IndexListExpr:
+			nodeRecvType = node.X
 		}
+		receiverType = string(syntaxgo_astnode.GetCodeV2(fset, source, nodeRecvType))
 	}
 	// Return receiver name and type
 	// 返回接收者名称和类型
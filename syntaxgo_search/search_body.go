@@ -0,0 +1,185 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// LocalNode pairs a node found inside a function body with the functions enclosing it.
+// FuncDecl is nil for nodes in package-level function literals, such as `var handler = func() {...}`.
+//
+// LocalNode 将在函数体内找到的节点与包含它的函数配对。
+// 对于包级函数字面量中的节点，比如 `var handler = func() {...}`，FuncDecl 为 nil。
+type LocalNode[NODE ast.Node] struct {
+	Node     NODE          // Node found in the body / 在函数体内找到的节点
+	FuncDecl *ast.FuncDecl // Enclosing function declaration / 外层的函数声明
+	FuncLit  *ast.FuncLit  // Innermost enclosing function literal, nil when not inside one / 最内层的外层函数字面量，不在其中时为 nil
+}
+
+// FindFuncLits finds the function literals in the function, nested ones included.
+// FuncLit of each result is the literal enclosing it, nil for literals written directly in the function.
+//
+// FindFuncLits 查找函数中的函数字面量，包括嵌套的字面量。
+// 每个结果的 FuncLit 是包含它的字面量，直接写在函数中的字面量为 nil。
+func FindFuncLits(funcDecl *ast.FuncDecl) (results []*LocalNode[*ast.FuncLit]) {
+	inspectLocalNodes(funcDecl, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		if x, ok := node.(*ast.FuncLit); ok {
+			results = append(results, &LocalNode[*ast.FuncLit]{Node: x, FuncDecl: funcDecl, FuncLit: funcLit})
+		}
+	})
+	return results
+}
+
+// FindFuncLitsInFile finds the function literals in the file, including those in package-level values.
+// FindFuncLitsInFile 查找文件中的函数字面量，包括包级变量中的字面量。
+func FindFuncLitsInFile(astFile *ast.File) (results []*LocalNode[*ast.FuncLit]) {
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		if x, ok := node.(*ast.FuncLit); ok {
+			results = append(results, &LocalNode[*ast.FuncLit]{Node: x, FuncDecl: funcDecl, FuncLit: funcLit})
+		}
+	})
+	return results
+}
+
+// FindLocalTypes finds the type declarations inside function bodies in the file.
+// FindLocalTypes 查找文件中函数体内的类型声明。
+func FindLocalTypes(astFile *ast.File) (results []*LocalNode[*ast.TypeSpec]) {
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		if x, ok := node.(*ast.TypeSpec); ok && (funcDecl != nil || funcLit != nil) {
+			results = append(results, &LocalNode[*ast.TypeSpec]{Node: x, FuncDecl: funcDecl, FuncLit: funcLit})
+		}
+	})
+	return results
+}
+
+// FindLocalStructTypeByName finds a struct type declared inside the function by its name.
+// FindLocalStructTypeByName 根据名称查找在函数内声明的结构体类型。
+func FindLocalStructTypeByName(funcDecl *ast.FuncDecl, structName string) (*LocalNode[*ast.StructType], bool) {
+	var result *LocalNode[*ast.StructType]
+	inspectLocalNodes(funcDecl, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		if x, ok := node.(*ast.TypeSpec); ok && result == nil && x.Name.Name == structName {
+			if structType, ok := x.Type.(*ast.StructType); ok {
+				result = &LocalNode[*ast.StructType]{Node: structType, FuncDecl: funcDecl, FuncLit: funcLit}
+			}
+		}
+	})
+	return result, result != nil
+}
+
+// FindLocalVars finds the `var` declarations inside function bodies in the file, short variable declarations excluded.
+// FindLocalVars 查找文件中函数体内的 `var` 声明，不包括短变量声明。
+func FindLocalVars(astFile *ast.File) []*LocalNode[*ast.ValueSpec] {
+	return findLocalValues(astFile, token.VAR)
+}
+
+// FindLocalConsts finds the `const` declarations inside function bodies in the file.
+// FindLocalConsts 查找文件中函数体内的 `const` 声明。
+func FindLocalConsts(astFile *ast.File) []*LocalNode[*ast.ValueSpec] {
+	return findLocalValues(astFile, token.CONST)
+}
+
+func findLocalValues(astFile *ast.File, tok token.Token) (results []*LocalNode[*ast.ValueSpec]) {
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		x, ok := node.(*ast.ValueSpec)
+		if !ok || (funcDecl == nil && funcLit == nil) {
+			return
+		}
+		if genDecl, ok := parent.(*ast.GenDecl); ok && genDecl.Tok == tok {
+			results = append(results, &LocalNode[*ast.ValueSpec]{Node: x, FuncDecl: funcDecl, FuncLit: funcLit})
+		}
+	})
+	return results
+}
+
+// AnonymousStruct is an anonymous struct type used in the type of a composite literal,
+// such as the struct of the test cases in `tests := []struct{...}{...}`.
+//
+// AnonymousStruct 是复合字面量类型中使用的匿名结构体类型，
+// 比如 `tests := []struct{...}{...}` 中测试用例的结构体。
+type AnonymousStruct struct {
+	StructType   *ast.StructType   // Anonymous struct type / 匿名结构体类型
+	CompositeLit *ast.CompositeLit // Composite literal whose type holds the struct / 类型中包含该结构体的复合字面量
+	FuncDecl     *ast.FuncDecl     // Enclosing function declaration, nil at package level / 外层的函数声明，在包级时为 nil
+	FuncLit      *ast.FuncLit      // Innermost enclosing function literal, nil when not inside one / 最内层的外层函数字面量，不在其中时为 nil
+}
+
+// FindAnonymousStructs finds the anonymous struct types of the composite literals in the file, package-level literals included.
+// Nested struct types inside an anonymous struct are part of it and are not listed separately.
+//
+// FindAnonymousStructs 查找文件中复合字面量的匿名结构体类型，包括包级的字面量。
+// 匿名结构体内部嵌套的结构体类型属于它本身，不会单独列出。
+func FindAnonymousStructs(astFile *ast.File) (results []*AnonymousStruct) {
+	inspectLocalNodes(astFile, func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit) {
+		compositeLit, ok := node.(*ast.CompositeLit)
+		if !ok || compositeLit.Type == nil {
+			return
+		}
+		ast.Inspect(compositeLit.Type, func(node ast.Node) bool {
+			if structType, ok := node.(*ast.StructType); ok {
+				results = append(results, &AnonymousStruct{
+					StructType:   structType,
+					CompositeLit: compositeLit,
+					FuncDecl:     funcDecl,
+					FuncLit:      funcLit,
+				})
+				return false
+			}
+			return true
+		})
+	})
+	return results
+}
+
+// FindLocalTypesInPackage finds the type declarations inside function bodies across the files of the package.
+// FindLocalTypesInPackage 查找包内所有文件中函数体内的类型声明。
+func FindLocalTypesInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (results []*LocalNode[*ast.TypeSpec]) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		results = append(results, FindLocalTypes(astFile)...)
+	}
+	return results
+}
+
+// FindAnonymousStructsInPackage finds the anonymous struct types of the composite literals across the files of the package.
+// FindAnonymousStructsInPackage 查找包内所有文件中复合字面量的匿名结构体类型。
+func FindAnonymousStructsInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (results []*AnonymousStruct) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		results = append(results, FindAnonymousStructs(astFile)...)
+	}
+	return results
+}
+
+// inspectLocalNodes walks the tree and calls visit with each node, its parent and the innermost enclosing functions.
+// inspectLocalNodes 遍历语法树，对每个节点调用 visit，并传入其父节点和最内层的外层函数。
+func inspectLocalNodes(root ast.Node, visit func(node ast.Node, parent ast.Node, funcDecl *ast.FuncDecl, funcLit *ast.FuncLit)) {
+	var stack []ast.Node
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		var parent ast.Node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		var funcDecl *ast.FuncDecl
+		var funcLit *ast.FuncLit
+		for idx := len(stack) - 1; idx >= 0; idx-- {
+			switch x := stack[idx].(type) {
+			case *ast.FuncDecl:
+				funcDecl = x
+			case *ast.FuncLit:
+				if funcLit == nil {
+					funcLit = x
+				}
+			}
+			if funcDecl != nil {
+				break
+			}
+		}
+		visit(node, parent, funcDecl, funcLit)
+		stack = append(stack, node)
+		return true
+	})
+}
@@ -0,0 +1,151 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/runpath"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const bodyExampleCode = `package example
+
+var handler = func() {
+	type event struct{ Name string }
+}
+
+var defaults = []struct {
+	Key   string
+	Inner struct{ Value int }
+}{
+	{Key: "a"},
+}
+
+func TestSum(t *testing.T) {
+	type testCase struct {
+		name string
+		args []int
+		want int
+	}
+	const limit = 10
+	var total int
+	tests := []struct {
+		name string
+		want int
+	}{
+		{name: "empty", want: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			check := func(got int) {
+				var message string
+				_ = message
+			}
+			check(total)
+		})
+	}
+	_ = map[string]*struct{ OK bool }{}
+}
+`
+
+// TestFindFuncLits tests finding function literals with their enclosing functions
+// Verifies nested literals point to the literal around them
+//
+// TestFindFuncLits 测试查找函数字面量及其外层函数
+// 验证嵌套的字面量指向包含它的字面量
+func TestFindFuncLits(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(bodyExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	funcDecl := FindFunctionByName(astFile, "TestSum")
+	funcLits := FindFuncLits(funcDecl)
+	require.Len(t, funcLits, 2)
+	require.Equal(t, funcDecl, funcLits[0].FuncDecl)
+	require.Nil(t, funcLits[0].FuncLit)
+	require.Equal(t, funcLits[0].Node, funcLits[1].FuncLit)
+
+	funcLits = FindFuncLitsInFile(astFile)
+	require.Len(t, funcLits, 3)
+	require.Nil(t, funcLits[0].FuncDecl)
+}
+
+// TestFindLocalTypes tests finding types, vars and consts declared inside function bodies
+// Verifies package-level declarations are not listed
+//
+// TestFindLocalTypes 测试查找函数体内声明的类型、变量和常量
+// 验证不会列出包级的声明
+func TestFindLocalTypes(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(bodyExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	localTypes := FindLocalTypes(astFile)
+	require.Len(t, localTypes, 2)
+	require.Equal(t, "event", localTypes[0].Node.Name.Name)
+	require.Nil(t, localTypes[0].FuncDecl)
+	require.NotNil(t, localTypes[0].FuncLit)
+	require.Equal(t, "testCase", localTypes[1].Node.Name.Name)
+	require.Equal(t, "TestSum", localTypes[1].FuncDecl.Name.Name)
+
+	structType, ok := FindLocalStructTypeByName(FindFunctionByName(astFile, "TestSum"), "testCase")
+	require.True(t, ok)
+	require.Len(t, structType.Node.Fields.List, 3)
+	_, ok = FindLocalStructTypeByName(FindFunctionByName(astFile, "TestSum"), "event")
+	require.False(t, ok)
+
+	localVars := FindLocalVars(astFile)
+	require.Len(t, localVars, 2)
+	require.Equal(t, "total", localVars[0].Node.Names[0].Name)
+	require.Equal(t, "message", localVars[1].Node.Names[0].Name)
+	require.NotNil(t, localVars[1].FuncLit)
+
+	localConsts := FindLocalConsts(astFile)
+	require.Len(t, localConsts, 1)
+	require.Equal(t, "limit", localConsts[0].Node.Names[0].Name)
+}
+
+// TestFindAnonymousStructs tests finding anonymous struct types of composite literals
+// Verifies table-driven test cases are found, and nested structs are not listed separately
+//
+// TestFindAnonymousStructs 测试查找复合字面量的匿名结构体类型
+// 验证能找到表格驱动测试的用例，且嵌套的结构体不会单独列出
+func TestFindAnonymousStructs(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(bodyExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	anonymousStructs := FindAnonymousStructs(astFile)
+	require.Len(t, anonymousStructs, 3)
+	require.Nil(t, anonymousStructs[0].FuncDecl)
+	require.Len(t, anonymousStructs[0].StructType.Fields.List, 2)
+	require.Equal(t, "TestSum", anonymousStructs[1].FuncDecl.Name.Name)
+	require.Equal(t, "name", anonymousStructs[1].StructType.Fields.List[0].Names[0].Name)
+	require.IsType(t, &ast.MapType{}, anonymousStructs[2].CompositeLit.Type)
+}
+
+// TestFindLocalTypesInPackage tests finding local types across the files of a package
+// Verifies the local types of the test files are found when tests are included
+//
+// TestFindLocalTypesInPackage 测试在包内所有文件中查找局部类型
+// 验证包含测试文件时能找到测试文件中的局部类型
+func TestFindLocalTypesInPackage(t *testing.T) {
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV2(runpath.PARENT.Path(), syntaxgo_ast.NewPackageBundleOptions().SetIncludeTests(true)))
+
+	type probe struct{ Name string }
+	var names []string
+	for _, localType := range FindLocalTypesInPackage(pkgBundle) {
+		if localType.FuncDecl != nil && localType.FuncDecl.Name.Name == "TestFindLocalTypesInPackage" {
+			names = append(names, localType.Node.Name.Name)
+		}
+	}
+	require.Equal(t, []string{"probe"}, names)
+
+	var count int
+	for _, anonymousStruct := range FindAnonymousStructsInPackage(pkgBundle) {
+		if anonymousStruct.FuncDecl != nil && anonymousStruct.FuncDecl.Name.Name == "TestFindLocalTypesInPackage" {
+			count++
+		}
+	}
+	require.Equal(t, 1, count)
+	require.NotEmpty(t, []struct{ Value probe }{{Value: probe{Name: "x"}}})
+}
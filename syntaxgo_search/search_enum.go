@@ -0,0 +1,409 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strings"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// EnumType is a defined type over a basic type, together with the constants declared with the type.
+// EnumType 是基于基本类型定义的类型，以及使用该类型声明的常量。
+type EnumType struct {
+	Name       string        // Type name / 类型名
+	Underlying string        // Underlying basic type, such as "string" or "int" / 底层基本类型，比如 "string" 或 "int"
+	TypeSpec   *ast.TypeSpec // Type declaration / 类型声明
+	Members    []*EnumMember // Constants in declaration order, "_" entries excluded / 按声明顺序排列的常量，不包括 "_"
+}
+
+// EnumMember is a constant of an enum type.
+// EnumMember 是枚举类型的一个常量。
+type EnumMember struct {
+	Name      string         // Constant name / 常量名
+	Value     constant.Value // Evaluated value, of kind constant.Unknown when it cannot be evaluated / 求值结果，无法求值时其种类为 constant.Unknown
+	Doc       string         // Doc comment text / 文档注释文本
+	Comment   string         // Trailing comment text / 行尾注释文本
+	ValueSpec *ast.ValueSpec // Constant declaration / 常量声明
+}
+
+// GetString returns the value as a string, false when it is not a string constant.
+// GetString 以字符串返回值，不是字符串常量时返回 false。
+func (member *EnumMember) GetString() (string, bool) {
+	if member.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(member.Value), true
+}
+
+// GetInt64 returns the value as an int64, false when it is not an integer constant fitting int64.
+// GetInt64 以 int64 返回值，不是可放入 int64 的整数常量时返回 false。
+func (member *EnumMember) GetInt64() (int64, bool) {
+	if member.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	return constant.Int64Val(member.Value)
+}
+
+// FindEnums finds the enum types in the file, which are types like `type Kind int` with constants of the type.
+// Values are evaluated through go/constant, with iota, implicit repetition and references to other constants of the file.
+//
+// FindEnums 查找文件中的枚举类型，即类似 `type Kind int` 且有该类型常量的类型。
+// 值通过 go/constant 求值，支持 iota、隐式重复以及对文件中其他常量的引用。
+func FindEnums(astFile *ast.File) []*EnumType {
+	return findEnums([]*ast.File{astFile})
+}
+
+// FindEnumsInPackage finds the enum types across the files of the package, constants may be declared in any file.
+// FindEnumsInPackage 查找包内所有文件中的枚举类型，常量可以声明在任意文件中。
+func FindEnumsInPackage(pkgBundle *syntaxgo_ast.PackageBundle) []*EnumType {
+	return findEnums(pkgBundle.GetAstFiles())
+}
+
+// FindEnumByName finds an enum type by its name in the file.
+// FindEnumByName 根据名称查找文件中的枚举类型。
+func FindEnumByName(astFile *ast.File, enumName string) (*EnumType, bool) {
+	for _, enumType := range FindEnums(astFile) {
+		if enumType.Name == enumName {
+			return enumType, true
+		}
+	}
+	return nil, false
+}
+
+// enumBasicTypes are the underlying types of enums.
+// enumBasicTypes 是枚举的底层类型。
+var enumBasicTypes = map[string]bool{
+	"string": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"byte": true, "rune": true, "float32": true, "float64": true,
+}
+
+func findEnums(astFiles []*ast.File) []*EnumType {
+	evaluator := newConstEvaluator(astFiles)
+
+	var enumTypes []*EnumType
+	var enumMap = map[string]*EnumType{}
+	for _, astFile := range astFiles {
+		for _, typeSpec := range FindTypes(astFile) {
+			ident, ok := typeSpec.Type.(*ast.Ident)
+			if !ok || typeSpec.Assign.IsValid() || typeSpec.TypeParams != nil || !enumBasicTypes[ident.Name] {
+				continue
+			}
+			enumType := &EnumType{Name: typeSpec.Name.Name, Underlying: ident.Name, TypeSpec: typeSpec}
+			enumTypes = append(enumTypes, enumType)
+			enumMap[enumType.Name] = enumType
+		}
+	}
+	for _, def := range evaluator.order {
+		enumType, ok := enumMap[def.typeName]
+		if !ok || def.name == "_" {
+			continue
+		}
+		enumType.Members = append(enumType.Members, &EnumMember{
+			Name:      def.name,
+			Value:     convertEnumValue(evaluator.eval(def), enumType.Underlying),
+			Doc:       strings.TrimSpace(def.doc.Text()),
+			Comment:   strings.TrimSpace(def.valueSpec.Comment.Text()),
+			ValueSpec: def.valueSpec,
+		})
+	}
+	var results []*EnumType
+	for _, enumType := range enumTypes {
+		if len(enumType.Members) > 0 {
+			results = append(results, enumType)
+		}
+	}
+	return results
+}
+
+// convertEnumValue converts the untyped value to the kind of the underlying type.
+// convertEnumValue 将无类型的值转换为底层类型对应的种类。
+func convertEnumValue(value constant.Value, underlying string) constant.Value {
+	switch {
+	case value.Kind() == constant.Unknown:
+		return value
+	case underlying == "string":
+		if value.Kind() != constant.String {
+			return constant.MakeUnknown()
+		}
+		return value
+	case strings.HasPrefix(underlying, "float"):
+		return constant.ToFloat(value)
+	default:
+		return constant.ToInt(value)
+	}
+}
+
+// constDef is one name of a const declaration, with the expression it takes after implicit repetition.
+// constDef 是常量声明中的一个名称，以及隐式重复后它所使用的表达式。
+type constDef struct {
+	name      string            // Constant name / 常量名
+	expr      ast.Expr          // Value expression, nil when missing / 值表达式，缺失时为 nil
+	iota      int               // Index of the spec in the const block / 规格在常量块中的序号
+	typeExpr  ast.Expr          // Declared type after implicit repetition, nil when missing / 隐式重复后声明的类型，缺失时为 nil
+	typeName  string            // Declared type name, or the type of a conversion like Kind(iota) or of a typed constant like A+1 / 声明的类型名，或类似 Kind(iota) 的转换、类似 A+1 的有类型常量的类型
+	doc       *ast.CommentGroup // Doc comment / 文档注释
+	valueSpec *ast.ValueSpec    // Constant declaration / 常量声明
+}
+
+// constEvaluator evaluates package-level constants on demand, remembering the results.
+// constEvaluator 按需对包级常量求值，并记住结果。
+type constEvaluator struct {
+	order       []*constDef               // Definitions in declaration order / 按声明顺序排列的定义
+	defs        map[string]*constDef      // Definitions by name / 按名称索引的定义
+	values      map[string]constant.Value // Evaluated values by name / 按名称索引的求值结果
+	underlyings map[string]string         // Underlying basic types by defined type name / 按定义类型名索引的底层基本类型
+}
+
+func newConstEvaluator(astFiles []*ast.File) *constEvaluator {
+	evaluator := &constEvaluator{defs: map[string]*constDef{}, values: map[string]constant.Value{}, underlyings: map[string]string{}}
+	for _, astFile := range astFiles {
+		for _, typeSpec := range FindTypes(astFile) {
+			if ident, ok := typeSpec.Type.(*ast.Ident); ok && enumBasicTypes[ident.Name] && typeSpec.TypeParams == nil {
+				evaluator.underlyings[typeSpec.Name.Name] = ident.Name
+			}
+		}
+		for _, decl := range astFile.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			var lastType ast.Expr
+			var lastValues []ast.Expr
+			for idx, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+					lastType, lastValues = valueSpec.Type, valueSpec.Values // Implicit repetition reuses the last non-empty spec. // 隐式重复沿用上一个非空规格。
+				}
+				doc := valueSpec.Doc
+				if doc == nil && !genDecl.Lparen.IsValid() {
+					doc = genDecl.Doc
+				}
+				for vdx, name := range valueSpec.Names {
+					def := &constDef{name: name.Name, iota: idx, typeExpr: lastType, doc: doc, valueSpec: valueSpec}
+					if vdx < len(lastValues) {
+						def.expr = lastValues[vdx]
+					}
+					def.typeName = getConstTypeName(lastType, def.expr)
+					evaluator.order = append(evaluator.order, def)
+					if name.Name != "_" {
+						evaluator.defs[name.Name] = def
+					}
+				}
+			}
+		}
+	}
+	for _, def := range evaluator.order {
+		if def.typeName == "" && def.typeExpr == nil && def.expr != nil {
+			def.typeName = evaluator.inferTypeName(def.expr, map[*constDef]bool{def: true})
+		}
+	}
+	return evaluator
+}
+
+// getConstTypeName returns the declared type name, or the type of a conversion like Kind(iota) when no type is declared.
+// getConstTypeName 返回声明的类型名，未声明类型时返回类似 Kind(iota) 的转换中的类型。
+func getConstTypeName(typeExpr ast.Expr, valueExpr ast.Expr) string {
+	if ident, ok := typeExpr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	if typeExpr == nil {
+		if callExpr, ok := valueExpr.(*ast.CallExpr); ok && len(callExpr.Args) == 1 {
+			if ident, ok := callExpr.Fun.(*ast.Ident); ok {
+				return ident.Name
+			}
+		}
+	}
+	return ""
+}
+
+// inferTypeName infers the type of an untyped-looking expression from the typed constants and conversions in it,
+// such as Kind for `A + 1` where A is a Kind, and empty for comparisons or when nothing in it is typed.
+//
+// inferTypeName 根据表达式中的有类型常量和类型转换推断其类型，
+// 比如 A 为 Kind 时 `A + 1` 得到 Kind，比较运算或其中没有有类型的部分时返回空。
+func (evaluator *constEvaluator) inferTypeName(expr ast.Expr, visiting map[*constDef]bool) string {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		def, ok := evaluator.defs[x.Name]
+		if !ok || visiting[def] {
+			return ""
+		}
+		if def.typeName != "" || def.typeExpr != nil || def.expr == nil {
+			return def.typeName
+		}
+		visiting[def] = true
+		return evaluator.inferTypeName(def.expr, visiting)
+	case *ast.ParenExpr:
+		return evaluator.inferTypeName(x.X, visiting)
+	case *ast.UnaryExpr:
+		return evaluator.inferTypeName(x.X, visiting)
+	case *ast.CallExpr:
+		if ident, ok := x.Fun.(*ast.Ident); ok && len(x.Args) == 1 && (evaluator.underlyings[ident.Name] != "" || enumBasicTypes[ident.Name]) {
+			return ident.Name
+		}
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return ""
+		case token.SHL, token.SHR:
+			return evaluator.inferTypeName(x.X, visiting)
+		}
+		if typeName := evaluator.inferTypeName(x.X, visiting); typeName != "" {
+			return typeName
+		}
+		return evaluator.inferTypeName(x.Y, visiting)
+	}
+	return ""
+}
+
+// getUnsignedPrec returns the bit size of an unsigned type for the "^" operator, 0 for other types.
+// getUnsignedPrec 返回无符号类型的位数供 "^" 运算使用，其他类型返回 0。
+func (evaluator *constEvaluator) getUnsignedPrec(typeName string) uint {
+	if underlying, ok := evaluator.underlyings[typeName]; ok {
+		typeName = underlying
+	}
+	switch typeName {
+	case "uint8", "byte":
+		return 8
+	case "uint16":
+		return 16
+	case "uint32":
+		return 32
+	case "uint", "uint64", "uintptr":
+		return 64
+	}
+	return 0
+}
+
+// eval evaluates the definition, the result is unknown when it cannot be evaluated or refers to itself.
+// eval 对定义求值，无法求值或引用自身时结果为未知。
+func (evaluator *constEvaluator) eval(def *constDef) constant.Value {
+	if value, ok := evaluator.values[def.name]; ok && evaluator.defs[def.name] == def {
+		return value
+	}
+	if def.name != "_" {
+		evaluator.values[def.name] = constant.MakeUnknown() // Guards against cycles. // 防止循环引用。
+	}
+	value := constant.MakeUnknown()
+	if def.expr != nil {
+		value = evaluator.evalExpr(def.expr, def.iota)
+	}
+	if def.name != "_" {
+		evaluator.values[def.name] = value
+	}
+	return value
+}
+
+// evalExpr evaluates a constant expression with the iota of its spec.
+// evalExpr 使用其规格的 iota 对常量表达式求值。
+func (evaluator *constEvaluator) evalExpr(expr ast.Expr, iota int) constant.Value {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(x.Value, x.Kind, 0)
+	case *ast.Ident:
+		switch x.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true":
+			return constant.MakeBool(true)
+		case "false":
+			return constant.MakeBool(false)
+		}
+		if def, ok := evaluator.defs[x.Name]; ok {
+			return evaluator.eval(def)
+		}
+	case *ast.ParenExpr:
+		return evaluator.evalExpr(x.X, iota)
+	case *ast.UnaryExpr:
+		value := evaluator.evalExpr(x.X, iota)
+		switch {
+		case x.Op == token.NOT && value.Kind() == constant.Bool:
+			return constant.UnaryOp(x.Op, value, 0)
+		case x.Op == token.XOR && value.Kind() == constant.Int:
+			return constant.UnaryOp(x.Op, value, evaluator.getUnsignedPrec(evaluator.inferTypeName(x.X, map[*constDef]bool{})))
+		case (x.Op == token.ADD || x.Op == token.SUB) && isNumericConst(value):
+			return constant.UnaryOp(x.Op, value, 0)
+		}
+	case *ast.BinaryExpr:
+		return evaluator.evalBinaryExpr(x, iota)
+	case *ast.CallExpr:
+		if len(x.Args) != 1 {
+			return constant.MakeUnknown()
+		}
+		value := evaluator.evalExpr(x.Args[0], iota)
+		if ident, ok := x.Fun.(*ast.Ident); ok && ident.Name == "len" && value.Kind() == constant.String {
+			return constant.MakeInt64(int64(len(constant.StringVal(value))))
+		}
+		return value // Conversions such as Kind(iota) keep the value. // 类似 Kind(iota) 的转换保持值不变。
+	}
+	return constant.MakeUnknown()
+}
+
+func (evaluator *constEvaluator) evalBinaryExpr(expr *ast.BinaryExpr, iota int) constant.Value {
+	left := evaluator.evalExpr(expr.X, iota)
+	right := evaluator.evalExpr(expr.Y, iota)
+	if !canEvalBinaryOp(left, expr.Op, right) {
+		return constant.MakeUnknown()
+	}
+	switch expr.Op {
+	case token.SHL, token.SHR:
+		count, ok := constant.Uint64Val(constant.ToInt(right))
+		if !ok {
+			return constant.MakeUnknown()
+		}
+		return constant.Shift(constant.ToInt(left), expr.Op, uint(count))
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return constant.MakeBool(constant.Compare(left, expr.Op, right))
+	case token.QUO:
+		if right.Kind() == constant.Int && constant.Sign(right) == 0 {
+			return constant.MakeUnknown()
+		}
+		if left.Kind() == constant.Int && right.Kind() == constant.Int {
+			return constant.BinaryOp(left, token.QUO_ASSIGN, right) // Integer division. // 整数除法。
+		}
+	case token.REM:
+		if constant.Sign(right) == 0 {
+			return constant.MakeUnknown()
+		}
+	}
+	return constant.BinaryOp(left, expr.Op, right)
+}
+
+// canEvalBinaryOp reports whether go/constant accepts the operands, it panics on mismatched kinds.
+// canEvalBinaryOp 判断 go/constant 是否接受这些操作数，种类不匹配时它会 panic。
+func canEvalBinaryOp(left constant.Value, op token.Token, right constant.Value) bool {
+	switch op {
+	case token.SHL, token.SHR:
+		return isIntegralConst(left) && isIntegralConst(right)
+	case token.EQL, token.NEQ:
+		return (isNumericConst(left) && isNumericConst(right)) || (left.Kind() == right.Kind() && left.Kind() != constant.Unknown)
+	case token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return (isNumericConst(left) && isNumericConst(right)) || (left.Kind() == constant.String && right.Kind() == constant.String)
+	case token.ADD:
+		return (isNumericConst(left) && isNumericConst(right)) || (left.Kind() == constant.String && right.Kind() == constant.String)
+	case token.SUB, token.MUL, token.QUO:
+		return isNumericConst(left) && isNumericConst(right)
+	case token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+		return left.Kind() == constant.Int && right.Kind() == constant.Int
+	case token.LAND, token.LOR:
+		return left.Kind() == constant.Bool && right.Kind() == constant.Bool
+	}
+	return false
+}
+
+func isNumericConst(value constant.Value) bool {
+	switch value.Kind() {
+	case constant.Int, constant.Float, constant.Complex:
+		return true
+	}
+	return false
+}
+
+// isIntegralConst reports whether the value is an integer, or a float with an integer value like 1e3.
+// isIntegralConst 判断值是否为整数，或者是具有整数值的浮点数，比如 1e3。
+func isIntegralConst(value constant.Value) bool {
+	return constant.ToInt(value).Kind() == constant.Int
+}
@@ -0,0 +1,188 @@
+package syntaxgo_search
+
+import (
+	"go/constant"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/runpath"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const enumExampleCode = `package example
+
+// Level is the log level.
+type Level int
+
+const (
+	// Debug shows everything.
+	Debug Level = iota + 1 // lowest
+	Info
+	_
+	Error // highest
+)
+
+type Size uint64
+
+const (
+	_       = iota
+	KB Size = 1 << (10 * iota)
+	MB
+	GB
+)
+
+type Color string
+
+const (
+	Red   Color = "red"
+	Green Color = "gr" + "een"
+)
+
+// Blue is declared alone.
+const Blue = Color("blue")
+
+const base = 100
+
+type Code int
+
+const (
+	OK       Code = base + iota*2
+	NotFound      = Code(base * 4 / 3)
+	Bad      Code = len("abc") - missing
+)
+
+type Ratio float64
+
+const Half Ratio = 1 / 2.0
+
+type Plain int
+`
+
+// TestFindEnums tests finding enum types with their evaluated constants
+// Verifies iota, skipped "_" entries, implicit repetition, conversions and references to other constants
+//
+// TestFindEnums 测试查找枚举类型及其求值后的常量
+// 验证 iota、跳过的 "_"、隐式重复、类型转换以及对其他常量的引用
+func TestFindEnums(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(enumExampleCode)))
+	astFile, _ := astBundle.GetBundle()
+
+	enumTypes := FindEnums(astFile)
+	var names []string
+	for _, enumType := range enumTypes {
+		names = append(names, enumType.Name)
+	}
+	require.Equal(t, []string{"Level", "Size", "Color", "Code", "Ratio"}, names)
+
+	getValues := func(enumType *EnumType) map[string]string {
+		values := map[string]string{}
+		for _, member := range enumType.Members {
+			values[member.Name] = member.Value.ExactString()
+		}
+		return values
+	}
+
+	level := enumTypes[0]
+	require.Equal(t, "int", level.Underlying)
+	require.Equal(t, map[string]string{"Debug": "1", "Info": "2", "Error": "4"}, getValues(level))
+	require.Equal(t, "Debug shows everything.", level.Members[0].Doc)
+	require.Equal(t, "lowest", level.Members[0].Comment)
+	require.Equal(t, "highest", level.Members[2].Comment)
+
+	size := enumTypes[1]
+	require.Equal(t, map[string]string{"KB": "1024", "MB": "1048576", "GB": "1073741824"}, getValues(size))
+	value, ok := size.Members[2].GetInt64()
+	require.True(t, ok)
+	require.Equal(t, int64(1<<30), value)
+
+	color := enumTypes[2]
+	require.Len(t, color.Members, 3)
+	text, ok := color.Members[1].GetString()
+	require.True(t, ok)
+	require.Equal(t, "green", text)
+	require.Equal(t, "Blue", color.Members[2].Name)
+	require.Equal(t, "Blue is declared alone.", color.Members[2].Doc)
+
+	code := enumTypes[3]
+	require.Equal(t, "100", code.Members[0].Value.ExactString())
+	require.Equal(t, "133", code.Members[1].Value.ExactString())
+	require.Equal(t, constant.Unknown, code.Members[2].Value.Kind())
+	_, ok = code.Members[2].GetInt64()
+	require.False(t, ok)
+
+	ratio := enumTypes[4]
+	require.Equal(t, constant.Float, ratio.Members[0].Value.Kind())
+	require.Equal(t, "1/2", ratio.Members[0].Value.ExactString())
+
+	_, ok = FindEnumByName(astFile, "Plain")
+	require.False(t, ok)
+}
+
+// TestFindEnums_TypedOperands tests constants typed through the enum members they refer to
+// Verifies `B = A + 1` and `C = A` join the enum, and "^" on an unsigned enum keeps its bit size
+//
+// TestFindEnums_TypedOperands 测试通过引用的枚举成员获得类型的常量
+// 验证 `B = A + 1` 和 `C = A` 属于该枚举，无符号枚举上的 "^" 保持其位数
+func TestFindEnums_TypedOperands(t *testing.T) {
+	const code = `package example
+
+type Kind int
+
+const A Kind = iota
+
+const (
+	B = A + 1
+	C = A
+	D = B << 2
+	E = A == B
+)
+
+type Flag uint8
+
+const (
+	None Flag = 0
+	All       = ^Flag(0)
+	Rest      = ^None &^ 1
+)
+`
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(code)))
+	astFile, _ := astBundle.GetBundle()
+
+	kind, ok := FindEnumByName(astFile, "Kind")
+	require.True(t, ok)
+	var names, values []string
+	for _, member := range kind.Members {
+		names = append(names, member.Name)
+		values = append(values, member.Value.ExactString())
+	}
+	require.Equal(t, []string{"A", "B", "C", "D"}, names)
+	require.Equal(t, []string{"0", "1", "0", "4"}, values)
+
+	flag, ok := FindEnumByName(astFile, "Flag")
+	require.True(t, ok)
+	require.Len(t, flag.Members, 3)
+	require.Equal(t, "255", flag.Members[1].Value.ExactString())
+	require.Equal(t, "254", flag.Members[2].Value.ExactString())
+}
+
+// TestFindEnumsInPackage tests finding enums in the syntaxgo_tag package
+// Verifies the string enums declared there are found
+//
+// TestFindEnumsInPackage 测试在 syntaxgo_tag 包中查找枚举
+// 验证能找到其中声明的字符串枚举
+func TestFindEnumsInPackage(t *testing.T) {
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(runpath.PARENT.Join("..", "syntaxgo_tag")))
+
+	values := map[string][]string{}
+	for _, enumType := range FindEnumsInPackage(pkgBundle) {
+		for _, member := range enumType.Members {
+			text, ok := member.GetString()
+			require.True(t, ok)
+			values[enumType.Name] = append(values[enumType.Name], text)
+		}
+	}
+	t.Log(values)
+	require.Equal(t, []string{"EXCLUDE_WHITESPACE_PREFIX", "INCLUDE_WHITESPACE_PREFIX"}, values["ExtractTagFieldAction"])
+	require.Equal(t, []string{"TOP", "END"}, values["InsertLocation"])
+}
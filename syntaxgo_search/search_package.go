@@ -0,0 +1,163 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// FindClassesAndFunctionsInPackage finds all functions, types, and values across the files of the package.
+// FindClassesAndFunctionsInPackage 查找包内所有文件中的函数、类型和变量。
+func FindClassesAndFunctionsInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (functions []*ast.FuncDecl, types []*ast.TypeSpec, values []*ast.ValueSpec) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		subFunctions, subTypes, subValues := FindClassesAndFunctions(astFile)
+		functions = append(functions, subFunctions...)
+		types = append(types, subTypes...)
+		values = append(values, subValues...)
+	}
+	return functions, types, values
+}
+
+// FindTypesInPackage finds all type declarations across the files of the package.
+// FindTypesInPackage 查找包内所有文件中的类型声明。
+func FindTypesInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (types []*ast.TypeSpec) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		types = append(types, FindTypes(astFile)...)
+	}
+	return types
+}
+
+// FindArrayTypeByNameInPackage finds an array type by its name across the files of the package.
+// FindArrayTypeByNameInPackage 在包内所有文件中根据名称查找数组类型。
+func FindArrayTypeByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, arrayName string) *ast.ArrayType {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if arrayType := FindArrayTypeByName(astFile, arrayName); arrayType != nil {
+			return arrayType
+		}
+	}
+	return nil
+}
+
+// FindStructTypeByNameInPackage finds a struct type by its name across the files of the package.
+// FindStructTypeByNameInPackage 在包内所有文件中根据名称查找结构体类型。
+func FindStructTypeByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, structName string) (structContent *ast.StructType, found bool) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if structContent, found = FindStructTypeByName(astFile, structName); found {
+			return structContent, true
+		}
+	}
+	return nil, false
+}
+
+// MapStructTypesByNameInPackage maps struct names to struct contents across the files of the package.
+// MapStructTypesByNameInPackage 返回包内所有文件中结构体名称到结构体内容的映射。
+func MapStructTypesByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle) map[string]*ast.StructType {
+	structTypes := map[string]*ast.StructType{}
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		for name, structType := range MapStructTypesByName(astFile) {
+			structTypes[name] = structType
+		}
+	}
+	return structTypes
+}
+
+// FindStructDeclarationByNameInPackage finds a struct declaration by its name across the files of the package.
+// FindStructDeclarationByNameInPackage 在包内所有文件中根据名称查找结构体声明。
+func FindStructDeclarationByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, structName string) (structDeclaration *ast.GenDecl, found bool) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if structDeclaration, found = FindStructDeclarationByName(astFile, structName); found {
+			return structDeclaration, true
+		}
+	}
+	return nil, false
+}
+
+// MapStructDeclarationsByNameInPackage maps struct names to struct declarations across the files of the package.
+// MapStructDeclarationsByNameInPackage 返回包内所有文件中结构体名称到结构体声明的映射。
+func MapStructDeclarationsByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle) map[string]*ast.GenDecl {
+	structDeclarations := map[string]*ast.GenDecl{}
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		for name, structDeclaration := range MapStructDeclarationsByName(astFile) {
+			structDeclarations[name] = structDeclaration
+		}
+	}
+	return structDeclarations
+}
+
+// FindInterfaceTypesInPackage maps interface names to interface contents across the files of the package.
+// FindInterfaceTypesInPackage 返回包内所有文件中接口名称到接口内容的映射。
+func FindInterfaceTypesInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (interfaceTypesMap map[string]*ast.InterfaceType) {
+	interfaceTypesMap = map[string]*ast.InterfaceType{}
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		for name, interfaceType := range FindInterfaceTypes(astFile) {
+			interfaceTypesMap[name] = interfaceType
+		}
+	}
+	return interfaceTypesMap
+}
+
+// FindFunctionsInPackage finds all function declarations across the files of the package.
+// FindFunctionsInPackage 查找包内所有文件中的函数声明。
+func FindFunctionsInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (functions []*ast.FuncDecl) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		functions = append(functions, FindFunctions(astFile)...)
+	}
+	return functions
+}
+
+// FindFunctionByNameInPackage finds a function by its name across the files of the package.
+// FindFunctionByNameInPackage 在包内所有文件中根据名称查找函数。
+func FindFunctionByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, functionName string) (function *ast.FuncDecl) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if function = FindFunctionByName(astFile, functionName); function != nil {
+			return function
+		}
+	}
+	return nil
+}
+
+// FindMainFunctionInPackage finds the main function across the files of the package.
+// FindMainFunctionInPackage 在包内所有文件中查找 main 函数。
+func FindMainFunctionInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (mainFunction *ast.FuncDecl) {
+	return FindFunctionByNameInPackage(pkgBundle, "main")
+}
+
+// ExtractFunctionsInPackage extracts all functions across the files of the package.
+// ExtractFunctionsInPackage 从包内所有文件中提取所有函数。
+func ExtractFunctionsInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (functions []*ast.FuncDecl) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		functions = append(functions, ExtractFunctions(astFile)...)
+	}
+	return functions
+}
+
+// FindFunctionByNameWithCheckInPackage finds a function (not a method) by name across the files of the package.
+// FindFunctionByNameWithCheckInPackage 在包内所有文件中查找指定名称的函数（非方法），并检查是否存在。
+func FindFunctionByNameWithCheckInPackage(pkgBundle *syntaxgo_ast.PackageBundle, functionName string) (result *ast.FuncDecl, found bool) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if result, found = FindFunctionByNameWithCheck(astFile, functionName); found {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// FindFunctionsByReceiverNameInPackage finds all methods with the receiver name across the files of the package.
+// FindFunctionsByReceiverNameInPackage 查找包内所有文件中具有指定接收者名称的所有方法。
+func FindFunctionsByReceiverNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, receiverName string, onlyExport bool) (matchingFunctions []*ast.FuncDecl) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		matchingFunctions = append(matchingFunctions, FindFunctionsByReceiverName(astFile, receiverName, onlyExport)...)
+	}
+	return matchingFunctions
+}
+
+// FindFunctionByReceiverAndNameInPackage finds a method by receiver name and function name across the files of the package.
+// FindFunctionByReceiverAndNameInPackage 在包内所有文件中根据接收者名称和函数名称查找方法。
+func FindFunctionByReceiverAndNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, receiverName string, functionName string) (result *ast.FuncDecl, found bool) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if result, found = FindFunctionByReceiverAndName(astFile, receiverName, functionName); found {
+			return result, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package syntaxgo_search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/runpath"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// TestFindFunctionsInPackage tests finding functions declared in sibling files
+// Verifies functions from search.go and utils.go are both found
+//
+// TestFindFunctionsInPackage 测试查找兄弟文件中声明的函数
+// 验证 search.go 和 utils.go 中的函数都能被找到
+func TestFindFunctionsInPackage(t *testing.T) {
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(runpath.PARENT.Path()))
+
+	var names []string
+	for _, astFunc := range FindFunctionsInPackage(pkgBundle) {
+		names = append(names, astFunc.Name.Name)
+	}
+	t.Log(names)
+	require.Contains(t, names, "FindFunctionByName")
+	require.Contains(t, names, "IsFunctionReceiverName")
+	require.Contains(t, names, "GetFunctionComment")
+}
+
+// TestFindStructTypeByNameInPackage tests finding a struct declared in a test file
+// Verifies the struct is found only when test files are loaded
+//
+// TestFindStructTypeByNameInPackage 测试查找测试文件中声明的结构体
+// 验证只有加载测试文件时才能找到该结构体
+func TestFindStructTypeByNameInPackage(t *testing.T) {
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(runpath.PARENT.Path()))
+	_, found := FindStructTypeByNameInPackage(pkgBundle, "Example")
+	require.False(t, found)
+
+	pkgBundle = rese.P1(syntaxgo_ast.NewPackageBundleV2(runpath.PARENT.Path(), syntaxgo_ast.NewPackageBundleOptions().SetIncludeTests(true)))
+	structType, found := FindStructTypeByNameInPackage(pkgBundle, "Example")
+	require.True(t, found)
+	require.NotNil(t, structType)
+
+	require.Contains(t, MapStructTypesByNameInPackage(pkgBundle), "Example")
+	require.NotNil(t, FindArrayTypeByNameInPackage(pkgBundle, "Examples"))
+}
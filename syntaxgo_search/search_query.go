@@ -0,0 +1,603 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// QueryMatch is a declaration matched by a Query, such as a struct, a field or a function.
+// QueryMatch 是 Query 匹配到的声明，比如结构体、字段或函数。
+type QueryMatch struct {
+	Kind     string         // Element kind, such as "struct", "field" or "func" / 元素类型，比如 "struct"、"field" 或 "func"
+	Name     string         // Declared name, an embedded field uses its type name / 声明的名称，嵌入字段使用其类型名
+	Node     ast.Node       // *ast.TypeSpec, *ast.Field, *ast.FuncDecl or *ast.ValueSpec / 对应的 AST 节点
+	Parent   *QueryMatch    // Enclosing element, nil at file level / 外层元素，文件级别时为 nil
+	Position token.Position // Position of the name / 名称所在的位置
+}
+
+// QueryNodes returns the nodes of the matches having the node type, such as QueryNodes[*ast.FuncDecl](matches).
+// QueryNodes 返回匹配结果中属于该节点类型的节点，比如 QueryNodes[*ast.FuncDecl](matches)。
+func QueryNodes[NODE ast.Node](matches []*QueryMatch) []NODE {
+	var nodes []NODE
+	for _, match := range matches {
+		if node, ok := match.Node.(NODE); ok {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Query is a compiled selector, such as `struct[name~="^User"] > field[tag.gorm.column]` or `func[recv="*Service"][exported]`.
+//
+// A selector is a chain of steps joined by ">" (direct child) or spaces (any descendant).
+// A step is an element kind, or "*" for any kind, followed by attribute filters in brackets.
+// Element kinds are type, struct, interface, array, map, alias, field, method, func, var and const.
+// Attribute filters are [key], [key=value], [key!=value], [key~=regexp], [key^=prefix], [key$=suffix] and [key*=substring],
+// and a leading "!" negates the filter, such as [!exported].
+// Attribute keys are name, exported, recv, type, embedded, generic, doc, tag, tag.<key> and tag.<key>.<option>.
+//
+// Query 是编译后的选择器，比如 `struct[name~="^User"] > field[tag.gorm.column]` 或 `func[recv="*Service"][exported]`。
+//
+// 选择器是由 ">"（直接子元素）或空格（任意后代元素）连接的一串步骤。
+// 每个步骤是元素类型（"*" 表示任意类型），后面跟着方括号中的属性过滤条件。
+// 元素类型有 type、struct、interface、array、map、alias、field、method、func、var 和 const。
+// 属性过滤条件有 [key]、[key=value]、[key!=value]、[key~=regexp]、[key^=prefix]、[key$=suffix] 和 [key*=substring]，
+// 前导的 "!" 表示取反，比如 [!exported]。
+// 属性键有 name、exported、recv、type、embedded、generic、doc、tag、tag.<key> 和 tag.<key>.<option>。
+type Query struct {
+	text  string       // Selector text / 选择器文本
+	steps []*queryStep // Steps from outer to inner / 从外到内的步骤
+}
+
+// queryStep is one compound selector and the relation to the previous step.
+// queryStep 是一个复合选择器及其与上一个步骤的关系。
+type queryStep struct {
+	kind    string         // Element kind, empty matches any kind / 元素类型，为空时匹配任意类型
+	filters []*queryFilter // Attribute filters / 属性过滤条件
+	isChild bool           // Whether the element must be a direct child of the previous step / 是否必须是上一步骤的直接子元素
+}
+
+// queryFilter is one attribute filter in brackets.
+// queryFilter 是方括号中的一个属性过滤条件。
+type queryFilter struct {
+	negate bool           // Whether the result is negated / 是否对结果取反
+	key    string         // Attribute key / 属性键
+	op     string         // Operator, empty checks presence only / 运算符，为空时只检查是否存在
+	value  string         // Operand / 操作数
+	regex  *regexp.Regexp // Compiled operand of "~=" / "~=" 的编译后操作数
+}
+
+var queryKinds = []string{"type", "struct", "interface", "array", "map", "alias", "field", "method", "func", "var", "const"}
+
+var queryKeys = []string{"name", "exported", "recv", "type", "embedded", "generic", "doc", "tag"}
+
+// CompileQuery compiles the selector text into a Query, returns an error on syntax errors and unknown kinds or keys.
+// CompileQuery 将选择器文本编译为 Query，遇到语法错误、未知的类型或键时返回错误。
+func CompileQuery(text string) (*Query, error) {
+	parser := &queryParser{text: text}
+	steps, err := parser.parse()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &Query{text: text, steps: steps}, nil
+}
+
+// String returns the selector text.
+// String 返回选择器文本。
+func (query *Query) String() string {
+	return query.text
+}
+
+// FindInFile returns the elements of the file matching the query, in source order.
+// FindInFile 返回文件中与查询匹配的元素，按源代码顺序排列。
+func (query *Query) FindInFile(fset *token.FileSet, astFile *ast.File) []*QueryMatch {
+	var results []*QueryMatch
+	for _, element := range newQueryElements(fset, astFile) {
+		results = query.collect(element, results)
+	}
+	return results
+}
+
+// FindInPackage returns the elements of every file of the package matching the query.
+// FindInPackage 返回包内所有文件中与查询匹配的元素。
+func (query *Query) FindInPackage(pkgBundle *syntaxgo_ast.PackageBundle) []*QueryMatch {
+	var results []*QueryMatch
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		results = append(results, query.FindInFile(pkgBundle.GetFileSet(), astFile)...)
+	}
+	return results
+}
+
+// QueryFile compiles the selector text and returns the matching elements of the file.
+// QueryFile 编译选择器文本并返回文件中匹配的元素。
+func QueryFile(fset *token.FileSet, astFile *ast.File, text string) ([]*QueryMatch, error) {
+	query, err := CompileQuery(text)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return query.FindInFile(fset, astFile), nil
+}
+
+// QueryPackage compiles the selector text and returns the matching elements of the package.
+// QueryPackage 编译选择器文本并返回包中匹配的元素。
+func QueryPackage(pkgBundle *syntaxgo_ast.PackageBundle, text string) ([]*QueryMatch, error) {
+	query, err := CompileQuery(text)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return query.FindInPackage(pkgBundle), nil
+}
+
+func (query *Query) collect(element *queryElement, results []*QueryMatch) []*QueryMatch {
+	if query.matchStep(element, len(query.steps)-1) {
+		results = append(results, element.match)
+	}
+	for _, child := range element.children {
+		results = query.collect(child, results)
+	}
+	return results
+}
+
+// matchStep checks the element matches the step and its ancestors match the steps before it.
+// matchStep 检查元素与该步骤匹配，且其祖先元素与之前的步骤匹配。
+func (query *Query) matchStep(element *queryElement, index int) bool {
+	step := query.steps[index]
+	if !step.match(element) {
+		return false
+	}
+	if index == 0 {
+		return true
+	}
+	for parent := element.parent; parent != nil; parent = parent.parent {
+		if query.matchStep(parent, index-1) {
+			return true
+		}
+		if step.isChild {
+			return false
+		}
+	}
+	return false
+}
+
+func (step *queryStep) match(element *queryElement) bool {
+	if step.kind != "" && !slices.Contains(element.kinds, step.kind) {
+		return false
+	}
+	for _, filter := range step.filters {
+		if filter.match(element) == filter.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (filter *queryFilter) match(element *queryElement) bool {
+	value, ok := element.getAttr(filter.key)
+	switch filter.op {
+	case "":
+		return ok
+	case "=":
+		return ok && value == filter.value
+	case "!=":
+		return !ok || value != filter.value
+	case "~=":
+		return ok && filter.regex.MatchString(value)
+	case "^=":
+		return ok && strings.HasPrefix(value, filter.value)
+	case "$=":
+		return ok && strings.HasSuffix(value, filter.value)
+	case "*=":
+		return ok && strings.Contains(value, filter.value)
+	}
+	return false
+}
+
+// queryElement is a declaration in the tree the query walks: file-level declarations, their fields and methods.
+// queryElement 是查询遍历的树中的声明：文件级别的声明及其字段和方法。
+type queryElement struct {
+	match    *QueryMatch       // Result returned on a match / 匹配时返回的结果
+	kinds    []string          // Kinds the element matches, such as ["type", "struct"] / 元素匹配的类型，比如 ["type", "struct"]
+	doc      *ast.CommentGroup // Doc comment, the GenDecl doc for a single unparenthesized spec / 文档注释，单个无括号规格使用 GenDecl 的文档注释
+	parent   *queryElement     // Enclosing element / 外层元素
+	children []*queryElement   // Fields or methods / 字段或方法
+}
+
+func newQueryElements(fset *token.FileSet, astFile *ast.File) []*queryElement {
+	var elements []*queryElement
+	for _, decl := range astFile.Decls {
+		switch x := decl.(type) {
+		case *ast.FuncDecl:
+			element := newQueryElement(fset, nil, x, x.Name, "func")
+			element.doc = x.Doc
+			elements = append(elements, element)
+		case *ast.GenDecl:
+			for _, spec := range x.Specs {
+				var doc = getSpecDoc(spec)
+				if doc == nil && !x.Lparen.IsValid() {
+					doc = x.Doc
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					kinds := []string{"type"}
+					switch spec.Type.(type) {
+					case *ast.StructType:
+						kinds = append(kinds, "struct")
+					case *ast.InterfaceType:
+						kinds = append(kinds, "interface")
+					case *ast.ArrayType:
+						kinds = append(kinds, "array")
+					case *ast.MapType:
+						kinds = append(kinds, "map")
+					}
+					if spec.Assign.IsValid() {
+						kinds = append(kinds, "alias")
+					}
+					element := newQueryElement(fset, nil, spec, spec.Name, kinds...)
+					element.doc = doc
+					element.children = newQueryChildren(fset, element, spec.Type)
+					elements = append(elements, element)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						element := newQueryElement(fset, nil, spec, name, x.Tok.String())
+						element.doc = doc
+						elements = append(elements, element)
+					}
+				}
+			}
+		}
+	}
+	return elements
+}
+
+// newQueryChildren creates the field elements of a struct and the method elements of an interface, nested anonymous structs included.
+// newQueryChildren 创建结构体的字段元素和接口的方法元素，包括嵌套的匿名结构体。
+func newQueryChildren(fset *token.FileSet, parent *queryElement, typeExpr ast.Expr) []*queryElement {
+	var children []*queryElement
+	switch x := unwrapQueryType(typeExpr).(type) {
+	case *ast.StructType:
+		for _, field := range x.Fields.List {
+			if len(field.Names) == 0 {
+				if name := getEmbeddedName(field.Type); name != nil {
+					element := newQueryElement(fset, parent, field, name, "field")
+					element.doc = field.Doc
+					children = append(children, element)
+				}
+				continue
+			}
+			for _, name := range field.Names {
+				element := newQueryElement(fset, parent, field, name, "field")
+				element.doc = field.Doc
+				element.children = newQueryChildren(fset, element, field.Type)
+				children = append(children, element)
+			}
+		}
+	case *ast.InterfaceType:
+		for _, field := range x.Methods.List {
+			if _, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+				element := newQueryElement(fset, parent, field, field.Names[0], "method")
+				element.doc = field.Doc
+				children = append(children, element)
+			}
+		}
+	}
+	return children
+}
+
+func newQueryElement(fset *token.FileSet, parent *queryElement, node ast.Node, name *ast.Ident, kinds ...string) *queryElement {
+	element := &queryElement{
+		match: &QueryMatch{
+			Kind:     kinds[len(kinds)-1],
+			Name:     name.Name,
+			Node:     node,
+			Position: fset.Position(name.Pos()),
+		},
+		kinds:  kinds,
+		parent: parent,
+	}
+	if parent != nil {
+		element.match.Parent = parent.match
+	}
+	return element
+}
+
+func unwrapQueryType(typeExpr ast.Expr) ast.Expr {
+	for {
+		switch x := typeExpr.(type) {
+		case *ast.StarExpr:
+			typeExpr = x.X
+		case *ast.ArrayType:
+			typeExpr = x.Elt
+		case *ast.ParenExpr:
+			typeExpr = x.X
+		default:
+			return typeExpr
+		}
+	}
+}
+
+// getEmbeddedName returns the type name of an embedded field, such as "Model" of `*gorm.Model`.
+// getEmbeddedName 返回嵌入字段的类型名，比如 `*gorm.Model` 中的 "Model"。
+func getEmbeddedName(typeExpr ast.Expr) *ast.Ident {
+	switch x := typeExpr.(type) {
+	case *ast.Ident:
+		return x
+	case *ast.StarExpr:
+		return getEmbeddedName(x.X)
+	case *ast.SelectorExpr:
+		return x.Sel
+	case *ast.IndexExpr:
+		return getEmbeddedName(x.X)
+	case *ast.IndexListExpr:
+		return getEmbeddedName(x.X)
+	}
+	return nil
+}
+
+// getAttr returns the attribute value of the element, false when the element does not have the attribute.
+// getAttr 返回元素的属性值，元素没有该属性时返回 false。
+func (element *queryElement) getAttr(key string) (string, bool) {
+	name := element.match.Name
+	switch key {
+	case "name":
+		return name, true
+	case "exported":
+		return "", token.IsExported(name)
+	case "doc":
+		return getDocText(element.doc)
+	}
+	switch x := element.match.Node.(type) {
+	case *ast.FuncDecl:
+		switch key {
+		case "recv":
+			receiverInfo, ok := GetFunctionReceiverInfo(x)
+			if !ok {
+				return "", false
+			}
+			if receiverInfo.IsPointer {
+				return "*" + receiverInfo.TypeName, true
+			}
+			return receiverInfo.TypeName, true
+		case "type":
+			return types.ExprString(x.Type), true
+		case "generic":
+			return "", x.Type.TypeParams != nil && len(x.Type.TypeParams.List) > 0
+		}
+	case *ast.TypeSpec:
+		switch key {
+		case "type":
+			return types.ExprString(x.Type), true
+		case "generic":
+			return "", x.TypeParams != nil && len(x.TypeParams.List) > 0
+		}
+	case *ast.ValueSpec:
+		switch key {
+		case "type":
+			if x.Type == nil {
+				return "", false
+			}
+			return types.ExprString(x.Type), true
+		}
+	case *ast.Field:
+		switch key {
+		case "type":
+			return types.ExprString(x.Type), true
+		case "embedded":
+			return "", len(x.Names) == 0
+		}
+		if key == "tag" || strings.HasPrefix(key, "tag.") {
+			return getFieldTagAttr(x, key)
+		}
+	}
+	return "", false
+}
+
+func getSpecDoc(spec ast.Spec) *ast.CommentGroup {
+	switch x := spec.(type) {
+	case *ast.TypeSpec:
+		return x.Doc
+	case *ast.ValueSpec:
+		return x.Doc
+	}
+	return nil
+}
+
+func getDocText(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	return doc.Text(), true
+}
+
+// getFieldTagAttr returns the whole tag for "tag", a tag value for "tag.<key>", and an option value for "tag.<key>.<option>".
+// Options are split by ";" or "," and written as "option:value" or "option", covering gorm and json styles.
+//
+// getFieldTagAttr 对 "tag" 返回整个标签，对 "tag.<key>" 返回标签值，对 "tag.<key>.<option>" 返回选项值。
+// 选项以 ";" 或 "," 分隔，写作 "option:value" 或 "option"，覆盖 gorm 和 json 两种风格。
+func getFieldTagAttr(field *ast.Field, key string) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	if key == "tag" {
+		return tag, true
+	}
+	tagKey, option, hasOption := strings.Cut(strings.TrimPrefix(key, "tag."), ".")
+	value, ok := reflect.StructTag(tag).Lookup(tagKey)
+	if !ok || !hasOption {
+		return value, ok
+	}
+	for _, part := range strings.FieldsFunc(value, func(c rune) bool { return c == ';' || c == ',' }) {
+		name, optionValue, _ := strings.Cut(part, ":")
+		if strings.TrimSpace(name) == option {
+			return strings.TrimSpace(optionValue), true
+		}
+	}
+	return "", false
+}
+
+// queryParser parses selector text into steps.
+// queryParser 将选择器文本解析为步骤。
+type queryParser struct {
+	text string // Selector text / 选择器文本
+	pos  int    // Current offset / 当前偏移量
+}
+
+func (p *queryParser) parse() ([]*queryStep, error) {
+	var steps []*queryStep
+	p.skipSpaces()
+	for p.pos < len(p.text) {
+		var isChild bool
+		if len(steps) > 0 {
+			if p.peek() == '>' {
+				p.pos++
+				p.skipSpaces()
+				isChild = true
+			}
+		}
+		step, err := p.parseStep()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		step.isChild = isChild
+		steps = append(steps, step)
+		p.skipSpaces()
+	}
+	if len(steps) == 0 {
+		return nil, erero.New("empty query")
+	}
+	return steps, nil
+}
+
+func (p *queryParser) parseStep() (*queryStep, error) {
+	step := &queryStep{}
+	if p.peek() == '*' {
+		p.pos++
+	} else if word := p.readWord(); word != "" {
+		if !slices.Contains(queryKinds, word) {
+			return nil, erero.Errorf("unknown kind %q at offset %d of %q", word, p.pos-len(word), p.text)
+		}
+		step.kind = word
+	} else if p.peek() != '[' {
+		return nil, erero.Errorf("expected a kind or '[' at offset %d of %q", p.pos, p.text)
+	}
+	for p.peek() == '[' {
+		filter, err := p.parseFilter()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		step.filters = append(step.filters, filter)
+	}
+	return step, nil
+}
+
+func (p *queryParser) parseFilter() (*queryFilter, error) {
+	p.pos++ // Skip '['. // 跳过 '['。
+	p.skipSpaces()
+	filter := &queryFilter{}
+	if p.peek() == '!' {
+		filter.negate = true
+		p.pos++
+		p.skipSpaces()
+	}
+	filter.key = p.readWord()
+	if rootKey, _, _ := strings.Cut(filter.key, "."); !slices.Contains(queryKeys, filter.key) && !(rootKey == "tag" && filter.key != "tag.") {
+		return nil, erero.Errorf("unknown key %q at offset %d of %q", filter.key, p.pos-len(filter.key), p.text)
+	}
+	p.skipSpaces()
+	for _, op := range []string{"!=", "~=", "^=", "$=", "*=", "="} {
+		if strings.HasPrefix(p.text[p.pos:], op) {
+			filter.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if filter.op != "" {
+		p.skipSpaces()
+		value, err := p.readValue()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		filter.value = value
+		if filter.op == "~=" {
+			regex, err := regexp.Compile(value)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			filter.regex = regex
+		}
+		p.skipSpaces()
+	}
+	if p.peek() != ']' {
+		return nil, erero.Errorf("expected ']' at offset %d of %q", p.pos, p.text)
+	}
+	p.pos++
+	return filter, nil
+}
+
+func (p *queryParser) readValue() (string, error) {
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		end := p.pos + 1
+		for end < len(p.text) && p.text[end] != quote {
+			if p.text[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.text) {
+			return "", erero.Errorf("unterminated string at offset %d of %q", p.pos, p.text)
+		}
+		raw := p.text[p.pos : end+1]
+		p.pos = end + 1
+		if quote == '\'' {
+			raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+		}
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		return value, nil
+	}
+	start := p.pos
+	for p.pos < len(p.text) && p.text[p.pos] != ']' && p.text[p.pos] != ' ' {
+		p.pos++
+	}
+	return p.text[start:p.pos], nil
+}
+
+func (p *queryParser) readWord() string {
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if c == '_' || c == '.' || c == '-' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t' || p.text[p.pos] == '\n') {
+		p.pos++
+	}
+}
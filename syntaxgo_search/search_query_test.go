@@ -0,0 +1,145 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const queryExampleCode = `package example
+
+// UserModel is stored in the users table.
+type UserModel struct {
+	gorm.Model
+	ID      int64  ` + "`gorm:\"column:id;primaryKey\" json:\"id\"`" + `
+	Name    string ` + "`gorm:\"column:name\" json:\"name,omitempty\"`" + `
+	Profile struct {
+		Avatar string ` + "`gorm:\"column:avatar\"`" + `
+	}
+	secret string
+}
+
+type UserList []*UserModel
+
+type Order struct {
+	Amount int ` + "`json:\"amount\"`" + `
+}
+
+type Service struct{}
+
+func (s *Service) Run() {}
+
+func (s Service) Name() string { return "" }
+
+func (s *Service) stop() {}
+
+type Store interface {
+	Get(id int64) (*UserModel, error)
+}
+
+func Map[T any, R any](items []T, fn func(T) R) []R { return nil }
+
+const Version = "v1"
+`
+
+// TestQueryFile tests selectors over structs, fields, methods and functions
+// Verifies child and descendant relations, attribute operators and negation
+//
+// TestQueryFile 测试针对结构体、字段、方法和函数的选择器
+// 验证子元素和后代元素关系、属性运算符以及取反
+func TestQueryFile(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(queryExampleCode)))
+	astFile, fset := astBundle.GetBundle()
+
+	getNames := func(text string) []string {
+		matches, err := QueryFile(fset, astFile, text)
+		require.NoError(t, err)
+		var names []string
+		for _, match := range matches {
+			names = append(names, match.Name)
+		}
+		return names
+	}
+
+	require.Equal(t, []string{"ID", "Name"}, getNames(`struct[name~="^User"] > field[tag.gorm.column]`))
+	require.Equal(t, []string{"ID", "Name", "Avatar"}, getNames(`struct[name~="^User"] field[tag.gorm.column]`))
+	require.Equal(t, []string{"ID"}, getNames(`field[tag.gorm.primaryKey]`))
+	require.Equal(t, []string{"Name"}, getNames(`field[tag.json.omitempty]`))
+	require.Equal(t, []string{"Name"}, getNames(`field[tag.gorm.column="name"]`))
+	require.Equal(t, []string{"Model"}, getNames(`field[embedded]`))
+	require.Equal(t, []string{"secret"}, getNames(`struct > field[!exported]`))
+	require.Equal(t, []string{"Run"}, getNames(`func[recv="*Service"][exported]`))
+	require.Equal(t, []string{"Run", "Name", "stop"}, getNames(`func[recv$="Service"]`))
+	require.Equal(t, []string{"Map"}, getNames(`func[!recv]`))
+	require.Equal(t, []string{"Map"}, getNames(`func[generic]`))
+	require.Equal(t, []string{"Get"}, getNames(`interface[name=Store] > method[type*="*UserModel"]`))
+	require.Equal(t, []string{"UserList"}, getNames(`array[type^="[]*User"]`))
+	require.Equal(t, []string{"UserModel"}, getNames(`type[doc*="users table"]`))
+	require.Equal(t, []string{"Version"}, getNames(`const[name='Version']`))
+	require.Empty(t, getNames(`struct[name=Order] > field[tag.gorm]`))
+}
+
+// TestQueryFile_Matches tests the positions, parents and typed nodes of the matches
+// Verifies QueryNodes filters the nodes by type
+//
+// TestQueryFile_Matches 测试匹配结果的位置、外层元素和类型化节点
+// 验证 QueryNodes 按类型过滤节点
+func TestQueryFile_Matches(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(queryExampleCode)))
+	astFile, fset := astBundle.GetBundle()
+
+	matches, err := QueryFile(fset, astFile, `struct field[name=Avatar]`)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "field", matches[0].Kind)
+	require.Equal(t, 9, matches[0].Position.Line)
+	require.Equal(t, "Profile", matches[0].Parent.Name)
+	require.Equal(t, "UserModel", matches[0].Parent.Parent.Name)
+	require.Len(t, QueryNodes[*ast.Field](matches), 1)
+
+	matches, err = QueryFile(fset, astFile, `func[recv]`)
+	require.NoError(t, err)
+	require.Len(t, QueryNodes[*ast.FuncDecl](matches), 3)
+	require.Empty(t, QueryNodes[*ast.TypeSpec](matches))
+}
+
+// TestCompileQuery_Errors tests compiling invalid selectors
+// Verifies unknown kinds, unknown keys, bad regexps and unclosed brackets are rejected
+//
+// TestCompileQuery_Errors 测试编译无效的选择器
+// 验证未知的类型、未知的键、错误的正则表达式和未闭合的方括号会被拒绝
+func TestCompileQuery_Errors(t *testing.T) {
+	for _, text := range []string{"", "klass", "struct[size]", `struct[name~="("]`, "struct[name", "struct > > field", `field[name="x]`} {
+		_, err := CompileQuery(text)
+		require.Error(t, err, text)
+	}
+	query, err := CompileQuery(` struct > field[ tag.json ] `)
+	require.NoError(t, err)
+	require.Equal(t, ` struct > field[ tag.json ] `, query.String())
+}
+
+// TestQueryPackage tests running a selector across the files of a package
+// Verifies methods defined in any file are found
+//
+// TestQueryPackage 测试在包内所有文件上运行选择器
+// 验证能找到定义在任意文件中的方法
+func TestQueryPackage(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/query", map[string]string{
+		"query.go": "package query\n\ntype Query struct{}\n\nfunc (q *Query) String() string { return \"\" }\n\nfunc (q *Query) reset() {}\n",
+		"find.go":  "package query\n\nfunc (q *Query) Find() {}\n\nfunc (q Query) Count() int { return 0 }\n",
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	matches, err := QueryPackage(pkgBundle, `func[recv="*Query"][exported]`)
+	require.NoError(t, err)
+	var names []string
+	for _, match := range matches {
+		names = append(names, match.Name)
+	}
+	t.Log(names)
+	require.Equal(t, []string{"Find", "String"}, names)
+}
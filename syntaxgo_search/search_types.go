@@ -0,0 +1,88 @@
+package syntaxgo_search
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// TypedNode pairs an AST node with its go/types object and resolved type.
+// Object and Type are nil when the types info does not record the node.
+//
+// TypedNode 将 AST 节点与其 go/types 对象和解析后的类型配对。
+// 当类型信息中没有记录该节点时，Object 和 Type 为 nil。
+type TypedNode[NODE ast.Node] struct {
+	Node   NODE         // AST node / AST 节点
+	Object types.Object // Declared object, such as *types.TypeName or *types.Func / 声明的对象，比如 *types.TypeName 或 *types.Func
+	Type   types.Type   // Resolved type / 解析后的类型
+}
+
+// NewTypedNode creates a TypedNode with the object defined by the name and the type of the object.
+// NewTypedNode 根据名称定义的对象及其类型创建 TypedNode。
+func NewTypedNode[NODE ast.Node](info *types.Info, node NODE, name *ast.Ident) *TypedNode[NODE] {
+	typedNode := &TypedNode[NODE]{Node: node}
+	if object := info.Defs[name]; object != nil {
+		typedNode.Object = object
+		typedNode.Type = object.Type()
+	}
+	return typedNode
+}
+
+// FindTypesWithTypes finds all type declarations in the file, together with their resolved types.
+// FindTypesWithTypes 查找文件中的所有类型声明，并返回其解析后的类型。
+func FindTypesWithTypes(astFile *ast.File, info *types.Info) (results []*TypedNode[*ast.TypeSpec]) {
+	for _, typeSpec := range FindTypes(astFile) {
+		results = append(results, NewTypedNode(info, typeSpec, typeSpec.Name))
+	}
+	return results
+}
+
+// FindFunctionsWithTypes finds all function declarations in the file, together with their resolved signatures.
+// FindFunctionsWithTypes 查找文件中的所有函数声明，并返回其解析后的签名。
+func FindFunctionsWithTypes(astFile *ast.File, info *types.Info) (results []*TypedNode[*ast.FuncDecl]) {
+	for _, funcDecl := range FindFunctions(astFile) {
+		results = append(results, NewTypedNode(info, funcDecl, funcDecl.Name))
+	}
+	return results
+}
+
+// FindFunctionByNameWithTypes finds a function by its name in the file, together with its resolved signature.
+// FindFunctionByNameWithTypes 根据名称查找文件中的函数，并返回其解析后的签名。
+func FindFunctionByNameWithTypes(astFile *ast.File, info *types.Info, functionName string) (*TypedNode[*ast.FuncDecl], bool) {
+	funcDecl := FindFunctionByName(astFile, functionName)
+	if funcDecl == nil {
+		return nil, false
+	}
+	return NewTypedNode(info, funcDecl, funcDecl.Name), true
+}
+
+// FindFunctionsByReceiverNameWithTypes finds the methods of the receiver in the file, together with their resolved signatures.
+// FindFunctionsByReceiverNameWithTypes 查找文件中指定接收者的方法，并返回其解析后的签名。
+func FindFunctionsByReceiverNameWithTypes(astFile *ast.File, info *types.Info, receiverName string, onlyExport bool) (results []*TypedNode[*ast.FuncDecl]) {
+	for _, funcDecl := range FindFunctionsByReceiverName(astFile, receiverName, onlyExport) {
+		results = append(results, NewTypedNode(info, funcDecl, funcDecl.Name))
+	}
+	return results
+}
+
+// FindStructTypeByNameWithTypes finds a struct type by its name in the file, together with its resolved named type.
+// FindStructTypeByNameWithTypes 根据名称查找文件中的结构体类型，并返回其解析后的命名类型。
+func FindStructTypeByNameWithTypes(astFile *ast.File, info *types.Info, structName string) (*TypedNode[*ast.StructType], bool) {
+	for _, typeSpec := range FindTypes(astFile) {
+		if structType, ok := typeSpec.Type.(*ast.StructType); ok && typeSpec.Name.Name == structName {
+			return NewTypedNode(info, structType, typeSpec.Name), true
+		}
+	}
+	return nil, false
+}
+
+// FindInterfaceTypesWithTypes maps interface names to interface contents in the file, together with their resolved named types.
+// FindInterfaceTypesWithTypes 返回文件中接口名称到接口内容的映射，并附带其解析后的命名类型。
+func FindInterfaceTypesWithTypes(astFile *ast.File, info *types.Info) map[string]*TypedNode[*ast.InterfaceType] {
+	results := map[string]*TypedNode[*ast.InterfaceType]{}
+	for _, typeSpec := range FindTypes(astFile) {
+		if interfaceType, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+			results[typeSpec.Name.Name] = NewTypedNode(info, interfaceType, typeSpec.Name)
+		}
+	}
+	return results
+}
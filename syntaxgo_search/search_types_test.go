@@ -0,0 +1,77 @@
+package syntaxgo_search
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const typedCode = `package example
+
+import "time"
+
+type Event struct {
+	At time.Time
+}
+
+type Handler interface {
+	Handle(event *Event) error
+}
+
+func (e *Event) Since() time.Duration {
+	return time.Since(e.At)
+}
+
+func NewEvent() *Event {
+	return &Event{At: time.Now()}
+}
+`
+
+// TestFindStructTypeByNameWithTypes tests finding a struct together with its resolved named type
+// Verifies the field types are resolved through go/types
+//
+// TestFindStructTypeByNameWithTypes 测试查找结构体并返回其解析后的命名类型
+// 验证字段类型通过 go/types 解析
+func TestFindStructTypeByNameWithTypes(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(typedCode)))
+	typesBundle := rese.P1(astBundle.TypeCheck())
+	astFile, _ := astBundle.GetBundle()
+
+	typedNode, found := FindStructTypeByNameWithTypes(astFile, typesBundle.GetInfo(), "Event")
+	require.True(t, found)
+	require.NotNil(t, typedNode.Node)
+	named, ok := typedNode.Type.(*types.Named)
+	require.True(t, ok)
+	require.Equal(t, "time.Time", named.Underlying().(*types.Struct).Field(0).Type().String())
+
+	interfaces := FindInterfaceTypesWithTypes(astFile, typesBundle.GetInfo())
+	require.Contains(t, interfaces, "Handler")
+	require.True(t, types.IsInterface(interfaces["Handler"].Type))
+}
+
+// TestFindFunctionsWithTypes tests finding functions and methods together with their resolved signatures
+// Verifies signatures are recorded for functions and methods
+//
+// TestFindFunctionsWithTypes 测试查找函数和方法并返回其解析后的签名
+// 验证函数和方法的签名被记录
+func TestFindFunctionsWithTypes(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(typedCode)))
+	typesBundle := rese.P1(astBundle.TypeCheck())
+	astFile, _ := astBundle.GetBundle()
+
+	for _, typedNode := range FindFunctionsWithTypes(astFile, typesBundle.GetInfo()) {
+		t.Log(typedNode.Node.Name.Name, typedNode.Type)
+		require.NotNil(t, typedNode.Object)
+	}
+
+	typedNode, found := FindFunctionByNameWithTypes(astFile, typesBundle.GetInfo(), "NewEvent")
+	require.True(t, found)
+	require.Equal(t, "func() *example.Event", typedNode.Type.String())
+
+	methods := FindFunctionsByReceiverNameWithTypes(astFile, typesBundle.GetInfo(), "Event", true)
+	require.Len(t, methods, 1)
+	require.Equal(t, "func() time.Duration", methods[0].Type.String())
+}
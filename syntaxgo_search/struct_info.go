@@ -0,0 +1,178 @@
+package syntaxgo_search
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// StructInfo describes a struct type declaration with its type parameters and fields.
+// StructInfo 描述一个结构体类型声明，包括其类型参数和字段。
+type StructInfo struct {
+	Name       string           // Type name / 类型名
+	TypeParams []*TypeParamInfo // Type parameters, one per name / 类型参数，每个名称一项
+	Fields     []*FieldInfo     // Fields, one per name / 字段，每个名称一项
+	TypeSpec   *ast.TypeSpec    // Type declaration / 类型声明
+	StructType *ast.StructType  // Struct type / 结构体类型
+	Position   token.Position   // Position of the type name / 类型名的位置
+}
+
+// TypeParamInfo is a type parameter of a generic struct, such as "K comparable".
+// TypeParamInfo 是泛型结构体的类型参数，比如 "K comparable"。
+type TypeParamInfo struct {
+	Name       string // Parameter name / 参数名
+	Constraint string // Constraint source text / 约束的源码文本
+}
+
+// FieldInfo describes a field of a struct, fields declaring several names like `A, B int` give one FieldInfo per name.
+// FieldInfo 描述结构体的一个字段，像 `A, B int` 这样声明多个名称的字段会为每个名称给出一个 FieldInfo。
+type FieldInfo struct {
+	Name         string            // Field name, or the type name for embedded fields / 字段名，嵌入字段时为类型名
+	TypeText     string            // Type source text, printed in gofmt style / 类型的源码文本，按 gofmt 风格打印
+	Tag          reflect.StructTag // Parsed tag, empty when the field has no tag / 解析后的标签，字段没有标签时为空
+	Embedded     bool              // Whether the field is embedded / 是否为嵌入字段
+	Exported     bool              // Whether the field name is exported / 字段名是否导出
+	Doc          string            // Doc comment text / 文档注释文本
+	Comment      string            // Trailing comment text / 行尾注释文本
+	Field        *ast.Field        // Field declaration, shared by the names of a multi-name field / 字段声明，多名称字段的各名称共享
+	Position     token.Position    // Position of the name, or of the type for embedded fields / 名称的位置，嵌入字段时为类型的位置
+	TypePosition token.Position    // Position of the type / 类型的位置
+	TagPosition  token.Position    // Position of the tag literal, zero when the field has no tag / 标签字面量的位置，没有标签时为零值
+}
+
+// GetField returns the field with the name.
+// GetField 返回具有该名称的字段。
+func (structInfo *StructInfo) GetField(name string) (*FieldInfo, bool) {
+	for _, fieldInfo := range structInfo.Fields {
+		if fieldInfo.Name == name {
+			return fieldInfo, true
+		}
+	}
+	return nil, false
+}
+
+// GetFieldNames returns the names of the fields in declaration order.
+// GetFieldNames 按声明顺序返回字段名。
+func (structInfo *StructInfo) GetFieldNames() []string {
+	var names []string
+	for _, fieldInfo := range structInfo.Fields {
+		names = append(names, fieldInfo.Name)
+	}
+	return names
+}
+
+// NewStructInfo describes the struct type declaration, false when the type is not a struct.
+// NewStructInfo 描述结构体类型声明，类型不是结构体时返回 false。
+func NewStructInfo(fset *token.FileSet, typeSpec *ast.TypeSpec) (*StructInfo, bool) {
+	structType, ok := typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return nil, false
+	}
+	structInfo := &StructInfo{
+		Name:       typeSpec.Name.Name,
+		TypeSpec:   typeSpec,
+		StructType: structType,
+		Position:   fset.Position(typeSpec.Name.Pos()),
+	}
+	if typeSpec.TypeParams != nil {
+		for _, field := range typeSpec.TypeParams.List {
+			constraint := printExprText(fset, field.Type)
+			for _, name := range field.Names {
+				structInfo.TypeParams = append(structInfo.TypeParams, &TypeParamInfo{Name: name.Name, Constraint: constraint})
+			}
+		}
+	}
+	for _, field := range structType.Fields.List {
+		base := FieldInfo{
+			TypeText:     printExprText(fset, field.Type),
+			Doc:          strings.TrimSpace(field.Doc.Text()),
+			Comment:      strings.TrimSpace(field.Comment.Text()),
+			Field:        field,
+			TypePosition: fset.Position(field.Type.Pos()),
+		}
+		if field.Tag != nil {
+			if tag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				base.Tag = reflect.StructTag(tag)
+			}
+			base.TagPosition = fset.Position(field.Tag.Pos())
+		}
+		if len(field.Names) == 0 {
+			fieldInfo := base
+			fieldInfo.Embedded = true
+			fieldInfo.Position = base.TypePosition
+			if embeddedName := getEmbeddedName(field.Type); embeddedName != nil {
+				fieldInfo.Name = embeddedName.Name
+			}
+			fieldInfo.Exported = ast.IsExported(fieldInfo.Name)
+			structInfo.Fields = append(structInfo.Fields, &fieldInfo)
+			continue
+		}
+		for _, name := range field.Names {
+			fieldInfo := base
+			fieldInfo.Name = name.Name
+			fieldInfo.Exported = name.IsExported()
+			fieldInfo.Position = fset.Position(name.Pos())
+			structInfo.Fields = append(structInfo.Fields, &fieldInfo)
+		}
+	}
+	return structInfo, true
+}
+
+// FindStructInfos describes the struct types declared in the file.
+// FindStructInfos 描述文件中声明的结构体类型。
+func FindStructInfos(fset *token.FileSet, astFile *ast.File) (results []*StructInfo) {
+	for _, typeSpec := range FindTypes(astFile) {
+		if structInfo, ok := NewStructInfo(fset, typeSpec); ok {
+			results = append(results, structInfo)
+		}
+	}
+	return results
+}
+
+// FindStructInfoByName describes the struct type with the name in the file.
+// FindStructInfoByName 描述文件中具有该名称的结构体类型。
+func FindStructInfoByName(fset *token.FileSet, astFile *ast.File, structName string) (*StructInfo, bool) {
+	for _, typeSpec := range FindTypes(astFile) {
+		if typeSpec.Name.Name == structName {
+			return NewStructInfo(fset, typeSpec)
+		}
+	}
+	return nil, false
+}
+
+// FindStructInfosInPackage describes the struct types declared across the files of the package.
+// FindStructInfosInPackage 描述包内所有文件中声明的结构体类型。
+func FindStructInfosInPackage(pkgBundle *syntaxgo_ast.PackageBundle) (results []*StructInfo) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		results = append(results, FindStructInfos(pkgBundle.GetFileSet(), astFile)...)
+	}
+	return results
+}
+
+// FindStructInfoByNameInPackage describes the struct type with the name across the files of the package.
+// FindStructInfoByNameInPackage 在包内所有文件中描述具有该名称的结构体类型。
+func FindStructInfoByNameInPackage(pkgBundle *syntaxgo_ast.PackageBundle, structName string) (*StructInfo, bool) {
+	for _, astFile := range pkgBundle.GetAstFiles() {
+		if structInfo, ok := FindStructInfoByName(pkgBundle.GetFileSet(), astFile, structName); ok {
+			return structInfo, true
+		}
+	}
+	return nil, false
+}
+
+// printExprText prints the expression in gofmt style.
+// printExprText 按 gofmt 风格打印表达式。
+func printExprText(fset *token.FileSet, expr ast.Expr) string {
+	var buffer bytes.Buffer
+	if err := printer.Fprint(&buffer, fset, expr); err != nil {
+		return types.ExprString(expr)
+	}
+	return buffer.String()
+}
@@ -0,0 +1,102 @@
+package syntaxgo_search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/internal/tests"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+const structInfoExampleCode = `package example
+
+import "gorm.io/gorm"
+
+type Page[K comparable, V any] struct {
+	*gorm.Model
+	// Items holds the values.
+	Items map[K]V ` + "`json:\"items\" gorm:\"-\"`" + `
+	A, b  int // two names
+	Next  *Page[K, V]
+}
+
+type Name string
+`
+
+// TestNewStructInfo tests describing a generic struct with embedded, tagged and multi-name fields
+// Verifies the names, types, tags, flags, comments and positions of each field
+//
+// TestNewStructInfo 测试描述带嵌入字段、标签字段和多名称字段的泛型结构体
+// 验证每个字段的名称、类型、标签、标记、注释和位置
+func TestNewStructInfo(t *testing.T) {
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1([]byte(structInfoExampleCode)))
+	astFile, fset := astBundle.GetBundle()
+
+	structInfos := FindStructInfos(fset, astFile)
+	require.Len(t, structInfos, 1)
+	structInfo := structInfos[0]
+	require.Equal(t, "Page", structInfo.Name)
+	require.Equal(t, 5, structInfo.Position.Line)
+	require.Len(t, structInfo.TypeParams, 2)
+	require.Equal(t, "K", structInfo.TypeParams[0].Name)
+	require.Equal(t, "comparable", structInfo.TypeParams[0].Constraint)
+	require.Equal(t, "any", structInfo.TypeParams[1].Constraint)
+	require.Equal(t, []string{"Model", "Items", "A", "b", "Next"}, structInfo.GetFieldNames())
+
+	model := structInfo.Fields[0]
+	require.True(t, model.Embedded)
+	require.True(t, model.Exported)
+	require.Equal(t, "*gorm.Model", model.TypeText)
+	require.Equal(t, model.TypePosition, model.Position)
+
+	items, ok := structInfo.GetField("Items")
+	require.True(t, ok)
+	require.Equal(t, "map[K]V", items.TypeText)
+	require.Equal(t, "items", items.Tag.Get("json"))
+	require.Equal(t, "-", items.Tag.Get("gorm"))
+	require.Equal(t, "Items holds the values.", items.Doc)
+	require.Equal(t, 8, items.Position.Line)
+	require.Equal(t, 2, items.Position.Column)
+	require.Equal(t, 8, items.TypePosition.Column)
+	require.Equal(t, 16, items.TagPosition.Column)
+
+	fieldA, fieldB := structInfo.Fields[2], structInfo.Fields[3]
+	require.Same(t, fieldA.Field, fieldB.Field)
+	require.Equal(t, "int", fieldB.TypeText)
+	require.Equal(t, "two names", fieldB.Comment)
+	require.True(t, fieldA.Exported)
+	require.False(t, fieldB.Exported)
+	require.Equal(t, 5, fieldB.Position.Column)
+	require.False(t, fieldB.TagPosition.IsValid())
+	require.Empty(t, fieldB.Tag)
+
+	next, ok := structInfo.GetField("Next")
+	require.True(t, ok)
+	require.Equal(t, "*Page[K, V]", next.TypeText)
+
+	_, ok = FindStructInfoByName(fset, astFile, "Name")
+	require.False(t, ok)
+}
+
+// TestFindStructInfoByNameInPackage tests describing structs declared across the files of a package
+// Verifies the struct in the second file is found with its fields
+//
+// TestFindStructInfoByNameInPackage 测试描述声明在包内多个文件中的结构体
+// 验证能找到第二个文件中的结构体及其字段
+func TestFindStructInfoByNameInPackage(t *testing.T) {
+	root := tests.NewTempModule(t, "example.com/shop", map[string]string{
+		"order.go": "package shop\n\ntype Order struct {\n\tID int64\n}\n",
+		"user.go":  "package shop\n\ntype User struct {\n\tName string // user name\n\tAge  int\n}\n",
+	})
+	pkgBundle := rese.P1(syntaxgo_ast.NewPackageBundleV1(root))
+
+	structInfo, ok := FindStructInfoByNameInPackage(pkgBundle, "User")
+	require.True(t, ok)
+	require.Equal(t, []string{"Name", "Age"}, structInfo.GetFieldNames())
+	require.Equal(t, "user name", structInfo.Fields[0].Comment)
+
+	_, ok = FindStructInfoByNameInPackage(pkgBundle, "Unknown")
+	require.False(t, ok)
+	require.Len(t, FindStructInfosInPackage(pkgBundle), 2)
+}
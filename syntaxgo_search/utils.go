@@ -2,43 +2,96 @@ package syntaxgo_search
 
 import (
 	"go/ast"
+	"go/token"
+
+	"github.com/yyle88/syntaxgo/syntaxgo_astnode"
 )
 
 // ExtractFunctionDefinitionCode extracts the code definition of the specified function from the source byte slice.
 // ExtractFunctionDefinitionCode 从源字节切片中提取指定函数的代码定义。
 func ExtractFunctionDefinitionCode(source []byte, funcDecl *ast.FuncDecl) string {
+	return ExtractFunctionDefinitionCodeV2(nil, source, funcDecl)
+}
+
+// ExtractFunctionDefinitionCodeV2 extracts the code definition of the function, resolving offsets through the FileSet.
+// ExtractFunctionDefinitionCodeV2 提取函数的代码定义，通过 FileSet 计算偏移量。
+func ExtractFunctionDefinitionCodeV2(fset *token.FileSet, source []byte, funcDecl *ast.FuncDecl) string {
 	// Return the function's code definition as a string.
 	// 返回函数的代码定义作为字符串。
-	return string(source[funcDecl.Pos()-1 : funcDecl.Body.Lbrace-1])
+	sdx := syntaxgo_astnode.GetOffset(fset, funcDecl.Pos())
+	edx := syntaxgo_astnode.GetOffset(fset, funcDecl.Body.Lbrace)
+	return string(source[sdx:edx])
+}
+
+// ReceiverInfo describes the receiver of a method, generic receivers such as `*Box[K, V]` included.
+// ReceiverInfo 描述方法的接收者，包括 `*Box[K, V]` 这样的泛型接收者。
+type ReceiverInfo struct {
+	Name       string   // Receiver variable name, empty when unnamed / 接收者变量名，未命名时为空
+	TypeName   string   // Base type name without pointer and type parameters / 不含指针和类型参数的基础类型名
+	TypeParams []string // Type parameter names, such as ["K", "V"] / 类型参数名称，比如 ["K", "V"]
+	IsPointer  bool     // Whether the receiver is a pointer / 接收者是否为指针
+}
+
+// GetFunctionReceiverInfo returns the receiver of the method, false when the function has no receiver or the receiver type is malformed.
+// GetFunctionReceiverInfo 返回方法的接收者，函数没有接收者或接收者类型不合法时返回 false。
+func GetFunctionReceiverInfo(funcDecl *ast.FuncDecl) (*ReceiverInfo, bool) {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return nil, false
+	}
+	field := funcDecl.Recv.List[0]
+	typeName, typeParams, isPointer, ok := ParseReceiverType(field.Type)
+	if !ok {
+		return nil, false
+	}
+	receiverInfo := &ReceiverInfo{
+		TypeName:   typeName,
+		TypeParams: typeParams,
+		IsPointer:  isPointer,
+	}
+	if len(field.Names) > 0 {
+		receiverInfo.Name = field.Names[0].Name
+	}
+	return receiverInfo, true
+}
+
+// ParseReceiverType splits a receiver type expression into the base type name, the type parameter names and the pointer flag.
+// It accepts T, *T, T[P], *T[P], T[K, V], *T[K, V] and parenthesized forms, and returns false on anything else.
+//
+// ParseReceiverType 将接收者类型表达式拆分为基础类型名、类型参数名称和指针标记。
+// 支持 T、*T、T[P]、*T[P]、T[K, V]、*T[K, V] 以及带括号的形式，其他情况返回 false。
+func ParseReceiverType(recvType ast.Expr) (typeName string, typeParams []string, isPointer bool, ok bool) {
+	expr := ast.Unparen(recvType)
+	if starExpr, match := expr.(*ast.StarExpr); match {
+		isPointer = true
+		expr = ast.Unparen(starExpr.X)
+	}
+	var indices []ast.Expr
+	switch node := expr.(type) {
+	case *ast.IndexExpr:
+		expr, indices = node.X, []ast.Expr{node.Index}
+	case *ast.IndexListExpr:
+		expr, indices = node.X, node.Indices
+	}
+	ident, match := expr.(*ast.Ident)
+	if !match {
+		return "", nil, false, false
+	}
+	for _, index := range indices {
+		param, match := index.(*ast.Ident)
+		if !match {
+			return "", nil, false, false
+		}
+		typeParams = append(typeParams, param.Name)
+	}
+	return ident.Name, typeParams, isPointer, true
 }
 
 // IsFunctionReceiverName checks if the specified receiver name matches the receiver of the function.
+// Generic receivers match by the base type name, so "Box" matches `func (b *Box[T]) Get() T`.
+//
 // IsFunctionReceiverName 检查指定的接收者名称是否与函数的接收者匹配。
+// 泛型接收者按基础类型名匹配，因此 "Box" 能匹配 `func (b *Box[T]) Get() T`。
 func IsFunctionReceiverName(funcDecl *ast.FuncDecl, receiverName string) bool {
-	// If the function has a receiver, check its name.
-	// 如果函数有接收者，检查接收者名称。
-	if funcDecl.Recv != nil {
-		// Iterate over the list of receiver declarations.
-		// 遍历接收者声明列表。
-		for _, item := range funcDecl.Recv.List {
-			// Check if the receiver is a pointer type.
-			// 检查接收者是否为指针类型。
-			if starExpr, ok := item.Type.(*ast.StarExpr); ok {
-				// If the receiver is a pointer, check if its type matches the receiver name.
-				// 如果接收者是指针类型，检查其类型是否匹配接收者名称。
-				if ident, ok := starExpr.X.(*ast.Ident); ok && ident.Name == receiverName {
-					return true
-				}
-			} else {
-				// If the receiver is not a pointer, check if its type matches the receiver name.
-				// 如果接收者不是指针类型，检查其类型是否匹配接收者名称。
-				if ident, ok := item.Type.(*ast.Ident); ok && ident.Name == receiverName {
-					return true
-				}
-			}
-		}
-	}
-	// Return false if no match is found.
-	// 如果未找到匹配项，返回false。
-	return false
+	receiverInfo, ok := GetFunctionReceiverInfo(funcDecl)
+	return ok && receiverInfo.TypeName == receiverName
 }
@@ -0,0 +1,107 @@
+package syntaxgo_search
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
+	"github.com/yyle88/syntaxgo/syntaxgo_ast"
+)
+
+// TestExtractFunctionDefinitionCodeV2 tests extracting a function signature from the second file of a shared FileSet
+// Verifies the signature is cut from the right offsets
+//
+// TestExtractFunctionDefinitionCodeV2 测试从共享 FileSet 中第二个文件提取函数签名
+// 验证签名从正确的偏移量截取
+func TestExtractFunctionDefinitionCodeV2(t *testing.T) {
+	fset := token.NewFileSet()
+	rese.P1(syntaxgo_ast.NewAstBundleV2(fset, []byte("package a\n\nfunc A() {}\n")))
+
+	source := []byte("package b\n\ntype S struct{}\n\nfunc (s *S) Get(x int) string { return \"\" }\n")
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV2(fset, source))
+	astFile, _ := astBundle.GetBundle()
+
+	astFunc, found := FindFunctionByReceiverAndName(astFile, "S", "Get")
+	require.True(t, found)
+	require.Equal(t, "func (s *S) Get(x int) string ", ExtractFunctionDefinitionCodeV2(fset, source, astFunc))
+
+	receiverName, receiverType := GetFunctionReceiverNameAndTypeV2(fset, astFunc, source)
+	require.Equal(t, "s", receiverName)
+	require.Equal(t, "S", receiverType)
+}
+
+// TestGetFunctionReceiverInfo tests receivers of generic types with one and two type parameters
+// Verifies the base type name and the type parameter names are returned without panics
+//
+// TestGetFunctionReceiverInfo 测试带一个和两个类型参数的泛型类型接收者
+// 验证返回基础类型名和类型参数名称且不会 panic
+func TestGetFunctionReceiverInfo(t *testing.T) {
+	source := []byte(`package b
+
+type Box[T any] struct{ v T }
+
+func (b *Box[T]) Get() T { return b.v }
+
+func (Box[T]) Name() string { return "box" }
+
+type Pair[K comparable, V any] struct{}
+
+func (p Pair[K, V]) Keys() []K { return nil }
+
+func Plain() {}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+
+	astFunc, found := FindFunctionByReceiverAndName(astFile, "Box", "Get")
+	require.True(t, found)
+	receiverInfo, ok := GetFunctionReceiverInfo(astFunc)
+	require.True(t, ok)
+	require.Equal(t, &ReceiverInfo{Name: "b", TypeName: "Box", TypeParams: []string{"T"}, IsPointer: true}, receiverInfo)
+
+	receiverName, receiverType := GetFunctionReceiverNameAndTypeV2(fset, astFunc, source)
+	require.Equal(t, "b", receiverName)
+	require.Equal(t, "Box", receiverType)
+
+	require.Len(t, FindFunctionsByReceiverName(astFile, "Box", true), 2)
+
+	astFunc, found = FindFunctionByReceiverAndName(astFile, "Pair", "Keys")
+	require.True(t, found)
+	receiverInfo, ok = GetFunctionReceiverInfo(astFunc)
+	require.True(t, ok)
+	require.Equal(t, &ReceiverInfo{Name: "p", TypeName: "Pair", TypeParams: []string{"K", "V"}, IsPointer: false}, receiverInfo)
+
+	astFunc = FindFunctionByName(astFile, "Plain")
+	require.NotNil(t, astFunc)
+	_, ok = GetFunctionReceiverInfo(astFunc)
+	require.False(t, ok)
+}
+
+// TestGetFunctionReceiverNameAndTypeV2_Parenthesized tests receivers whose types are wrapped in parentheses
+// Verifies the parentheses are removed around and inside the pointer
+//
+// TestGetFunctionReceiverNameAndTypeV2_Parenthesized 测试类型被括号包裹的接收者
+// 验证指针外部和内部的括号都被去掉
+func TestGetFunctionReceiverNameAndTypeV2_Parenthesized(t *testing.T) {
+	source := []byte(`package b
+
+type T struct{}
+
+func (t (*T)) M() {}
+
+func (t *(T)) N() {}
+
+func (t (T)) O() {}
+`)
+	astBundle := rese.P1(syntaxgo_ast.NewAstBundleV1(source))
+	astFile, fset := astBundle.GetBundle()
+
+	for _, name := range []string{"M", "N", "O"} {
+		astFunc := FindFunctionByName(astFile, name)
+		require.NotNil(t, astFunc)
+		receiverName, receiverType := GetFunctionReceiverNameAndTypeV2(fset, astFunc, source)
+		require.Equal(t, "t", receiverName)
+		require.Equal(t, "T", receiverType)
+	}
+}